@@ -0,0 +1,396 @@
+package action
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ====================
+// Confirmed actions
+// ====================
+
+// Confirm é a confirmação esperada de uma ação. A ação é pressionada de
+// novo (com backoff) até a confirmação chegar ou o timeout estourar:
+//
+//	{"skill": 12345}                    cast do skill ID visto pelo SkillMonitor
+//	{"buff": 8000, "timeout_ms": 2000}  buff aparece no player
+//	{"target": true}                    ID do target muda
+//	{"combat": true}                    flag de combate do player ligada
+//	{"gone": 4242}                      entidade sai da entity list (corpo lootado)
+//	{"resource": "player_hp"}           recurso sobe (com "drop": true, desce)
+//
+// Vazio = sem confirmação: um press só.
+type Confirm struct {
+	Skill     uint32 `json:"skill,omitempty"`
+	Buff      uint32 `json:"buff,omitempty"`
+	Target    bool   `json:"target,omitempty"`
+	Combat    bool   `json:"combat,omitempty"`
+	Gone      uint32 `json:"gone,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Drop      bool   `json:"drop,omitempty"`       // resource: confirma quando desce
+	TimeoutMs int    `json:"timeout_ms,omitempty"` // 0 = Policy.TimeoutMs
+}
+
+// Empty indica que a ação não declara confirmação.
+func (c Confirm) Empty() bool {
+	return c.Skill == 0 && c.Buff == 0 && !c.Target && !c.Combat && c.Gone == 0 && c.Resource == ""
+}
+
+func (c Confirm) String() string {
+	var parts []string
+	if c.Skill != 0 {
+		parts = append(parts, fmt.Sprintf("skill %d", c.Skill))
+	}
+	if c.Buff != 0 {
+		parts = append(parts, fmt.Sprintf("buff %d", c.Buff))
+	}
+	if c.Target {
+		parts = append(parts, "target")
+	}
+	if c.Combat {
+		parts = append(parts, "combat")
+	}
+	if c.Gone != 0 {
+		parts = append(parts, fmt.Sprintf("gone %d", c.Gone))
+	}
+	if c.Resource != "" {
+		dir := "up"
+		if c.Drop {
+			dir = "down"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", c.Resource, dir))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "|")
+}
+
+// Policy controla as tentativas: o 2º press sai RetryMs depois do 1º, e
+// cada espera seguinte é multiplicada por Backoff (até MaxRetryMs).
+type Policy struct {
+	TimeoutMs  int     `json:"timeout_ms"`
+	RetryMs    int     `json:"retry_ms"`
+	Backoff    float64 `json:"backoff"`
+	MaxRetryMs int     `json:"max_retry_ms"`
+	PollMs     int     `json:"poll_ms"` // intervalo entre checagens
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		TimeoutMs:  1500,
+		RetryMs:    150,
+		Backoff:    2,
+		MaxRetryMs: 600,
+		PollMs:     30,
+	}
+}
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+// Result é o resultado de uma execução.
+type Result struct {
+	Name      string
+	Confirmed bool
+	By        string // skill, buff, target, combat, gone ou resource ("" = timeout)
+	Presses   int
+	Latency   time.Duration // do 1º press até a confirmação (ou desistência)
+}
+
+// Stats são as taxas de uma ação (por nome).
+type Stats struct {
+	Name      string `json:"name"`
+	Runs      int    `json:"runs"`
+	Confirmed int    `json:"confirmed"`
+	Presses   int    `json:"presses"`
+	LatencyMs int64  `json:"latency_ms"` // soma das confirmadas
+}
+
+// Rate é a porcentagem de execuções confirmadas.
+func (s Stats) Rate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Confirmed) / float64(s.Runs) * 100
+}
+
+// AvgLatency é a latência média das confirmadas.
+func (s Stats) AvgLatency() time.Duration {
+	if s.Confirmed == 0 {
+		return 0
+	}
+	return ms(int(s.LatencyMs / int64(s.Confirmed)))
+}
+
+func (s Stats) String() string {
+	presses := 0.0
+	if s.Runs > 0 {
+		presses = float64(s.Presses) / float64(s.Runs)
+	}
+	return fmt.Sprintf("%s: %d/%d (%.0f%%) %.1f presses, %s",
+		s.Name, s.Confirmed, s.Runs, s.Rate(), presses, s.AvgLatency())
+}
+
+// Merge soma as stats de b em a, por nome (bot_report).
+func Merge(a, b []Stats) []Stats {
+	index := make(map[string]int)
+	var out []Stats
+	for _, list := range [][]Stats{a, b} {
+		for _, s := range list {
+			i, ok := index[s.Name]
+			if !ok {
+				index[s.Name] = len(out)
+				out = append(out, s)
+				continue
+			}
+			out[i].Runs += s.Runs
+			out[i].Confirmed += s.Confirmed
+			out[i].Presses += s.Presses
+			out[i].LatencyMs += s.LatencyMs
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ====================
+// Executor
+// ====================
+
+// Executor executa ações confirmadas e acumula as taxas. Do bloqueia quem
+// chama até confirmar ou desistir; Start/Poll fazem o mesmo sem bloquear
+// (o bot checa a cada tick). O cast do skill chega por OnSkillCast (outra
+// goroutine), os outros sinais são consultados a cada checagem.
+type Executor struct {
+	mu     sync.Mutex
+	policy Policy
+	now    func() time.Time
+	sleep  func(time.Duration)
+	casts  map[uint32]time.Time
+	stats  map[string]*Stats
+
+	// Sinais (nil = essa confirmação nunca chega)
+	HasBuff  func(id uint32) bool
+	TargetID func() uint32
+	InCombat func() bool
+	Exists   func(id uint32) bool              // entidade na entity list
+	Resource func(name string) (float32, bool) // player_hp, player_mp, ... (%)
+}
+
+// NewExecutor cria o executor. now/sleep nil = relógio do sistema.
+func NewExecutor(policy Policy, now func() time.Time, sleep func(time.Duration)) *Executor {
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	e := &Executor{
+		now:   now,
+		sleep: sleep,
+		casts: make(map[uint32]time.Time),
+		stats: make(map[string]*Stats),
+	}
+	e.SetPolicy(policy)
+	return e
+}
+
+// SetPolicy troca a política em runtime (zeros = padrão).
+func (e *Executor) SetPolicy(p Policy) {
+	def := DefaultPolicy()
+	if p.TimeoutMs <= 0 {
+		p.TimeoutMs = def.TimeoutMs
+	}
+	if p.RetryMs <= 0 {
+		p.RetryMs = def.RetryMs
+	}
+	if p.Backoff < 1 {
+		p.Backoff = 1
+	}
+	if p.PollMs <= 0 {
+		p.PollMs = def.PollMs
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policy = p
+}
+
+// GetPolicy retorna a política atual.
+func (e *Executor) GetPolicy() Policy {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.policy
+}
+
+// OnSkillCast registra um cast confirmado (SkillMonitor.OnSkillCast).
+func (e *Executor) OnSkillCast(skillID uint32) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.casts[skillID] = e.now()
+}
+
+// Pending é uma ação em andamento (Start). Cada Poll pressiona de novo se o
+// backoff venceu e checa a confirmação.
+type Pending struct {
+	name        string
+	press       func()
+	confirm     Confirm
+	policy      Policy
+	timeout     time.Duration
+	wait        time.Duration
+	start       time.Time
+	startTarget uint32
+	startValue  float32 // recurso no Start (Confirm.Resource)
+	hasValue    bool
+	nextPress   time.Time
+	res         Result
+	done        bool
+}
+
+// Name é o nome da ação (stats).
+func (p *Pending) Name() string {
+	return p.name
+}
+
+// Start prepara a ação sem pressionar; o 1º press sai no primeiro Poll.
+func (e *Executor) Start(name string, press func(), c Confirm) *Pending {
+	p := e.GetPolicy()
+	timeout := ms(p.TimeoutMs)
+	if c.TimeoutMs > 0 {
+		timeout = ms(c.TimeoutMs)
+	}
+	pa := &Pending{
+		name:    name,
+		press:   press,
+		confirm: c,
+		policy:  p,
+		timeout: timeout,
+		wait:    ms(p.RetryMs),
+		start:   e.now(),
+		res:     Result{Name: name},
+	}
+	if c.Target && e.TargetID != nil {
+		pa.startTarget = e.TargetID()
+	}
+	if c.Resource != "" && e.Resource != nil {
+		pa.startValue, pa.hasValue = e.Resource(c.Resource)
+	}
+	return pa
+}
+
+// Poll pressiona se o backoff venceu e checa a confirmação. done = true
+// quando confirmou ou estourou o timeout (o Result já foi contado).
+func (e *Executor) Poll(pa *Pending) (Result, bool) {
+	if pa.done {
+		return pa.res, true
+	}
+	if pa.res.Presses == 0 || !e.now().Before(pa.nextPress) {
+		pa.press()
+		pa.res.Presses++
+		pa.nextPress = e.now().Add(pa.wait)
+		pa.wait = time.Duration(float64(pa.wait) * pa.policy.Backoff)
+		if pa.policy.MaxRetryMs > 0 && pa.wait > ms(pa.policy.MaxRetryMs) {
+			pa.wait = ms(pa.policy.MaxRetryMs)
+		}
+	}
+	if by := e.confirmed(pa); by != "" {
+		pa.res.Confirmed, pa.res.By = true, by
+	} else if e.now().Sub(pa.start) < pa.timeout {
+		return pa.res, false
+	}
+	pa.done = true
+	pa.res.Latency = e.now().Sub(pa.start)
+	e.record(pa.res)
+	if !pa.res.Confirmed {
+		fmt.Printf("[ACTION] %s: sem confirmação (%s) após %d presses em %s\n",
+			pa.name, pa.confirm, pa.res.Presses, pa.res.Latency.Round(10*time.Millisecond))
+	}
+	return pa.res, true
+}
+
+// Do pressiona (press) até a confirmação c chegar ou estourar o timeout,
+// bloqueando quem chama (regras e guardian, que rodam na própria goroutine).
+func (e *Executor) Do(name string, press func(), c Confirm) Result {
+	pa := e.Start(name, press, c)
+	for {
+		if res, done := e.Poll(pa); done {
+			return res
+		}
+		e.sleep(ms(pa.policy.PollMs))
+	}
+}
+
+// confirmed retorna o sinal que confirmou a ação ("" = nenhum ainda).
+// Buff que já estava ativo conta como confirmado.
+func (e *Executor) confirmed(pa *Pending) string {
+	c := pa.confirm
+	if c.Skill != 0 {
+		e.mu.Lock()
+		at, ok := e.casts[c.Skill]
+		e.mu.Unlock()
+		if ok && !at.Before(pa.start) {
+			return "skill"
+		}
+	}
+	if c.Buff != 0 && e.HasBuff != nil && e.HasBuff(c.Buff) {
+		return "buff"
+	}
+	if c.Target && e.TargetID != nil {
+		if id := e.TargetID(); id != pa.startTarget {
+			return "target"
+		}
+	}
+	if c.Combat && e.InCombat != nil && e.InCombat() {
+		return "combat"
+	}
+	if c.Gone != 0 && e.Exists != nil && !e.Exists(c.Gone) {
+		return "gone"
+	}
+	if pa.hasValue {
+		v, ok := e.Resource(c.Resource)
+		if ok && (c.Drop && v < pa.startValue || !c.Drop && v > pa.startValue) {
+			return "resource"
+		}
+	}
+	return ""
+}
+
+func (e *Executor) record(res Result) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, ok := e.stats[res.Name]
+	if !ok {
+		s = &Stats{Name: res.Name}
+		e.stats[res.Name] = s
+	}
+	s.Runs++
+	s.Presses += res.Presses
+	if res.Confirmed {
+		s.Confirmed++
+		s.LatencyMs += res.Latency.Milliseconds()
+	}
+}
+
+// Stats retorna as taxas por ação, em ordem de nome.
+func (e *Executor) Stats() []Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]Stats, 0, len(e.stats))
+	for _, s := range e.stats {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ResetStats zera as taxas (nova sessão).
+func (e *Executor) ResetStats() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats = make(map[string]*Stats)
+}
@@ -0,0 +1,100 @@
+package action_test
+
+import (
+	"archefriend/action"
+	"archefriend/sim"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newExecutor() (*action.Executor, *sim.VirtualClock) {
+	clock := sim.NewVirtualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	return action.NewExecutor(action.DefaultPolicy(), clock.Now, clock.Sleep), clock
+}
+
+func TestTargetConfirm(t *testing.T) {
+	e, _ := newExecutor()
+	target := uint32(3)
+	e.TargetID = func() uint32 { return target }
+	presses := 0
+	res := e.Do("tab", func() {
+		presses++
+		if presses == 2 {
+			target = 7
+		}
+	}, action.Confirm{Target: true})
+	if !res.Confirmed || res.By != "target" || res.Presses != 2 || res.Latency != 150*time.Millisecond {
+		t.Fatalf("unexpected target confirm %+v", res)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	// Start não pressiona; cada Poll pressiona só quando o backoff venceu
+	e, clock := newExecutor()
+	inCombat := false
+	e.InCombat = func() bool { return inCombat }
+	start := clock.Now()
+	var at []time.Duration
+	pa := e.Start("attack", func() { at = append(at, clock.Now().Sub(start)) }, action.Confirm{Combat: true})
+	if len(at) != 0 {
+		t.Fatalf("Start pressed")
+	}
+	for i := 0; i < 20; i++ {
+		if _, done := e.Poll(pa); done {
+			t.Fatalf("done before confirmation at %s", clock.Now().Sub(start))
+		}
+		clock.Advance(25 * time.Millisecond)
+	}
+	want := []time.Duration{0, 150 * time.Millisecond, 450 * time.Millisecond}
+	if fmt.Sprint(at) != fmt.Sprint(want) {
+		t.Fatalf("unexpected press times %v, want %v", at, want)
+	}
+
+	inCombat = true
+	res, done := e.Poll(pa)
+	if !done || !res.Confirmed || res.By != "combat" || res.Presses != 3 {
+		t.Fatalf("unexpected combat confirm %+v (done %v)", res, done)
+	}
+	if st := e.Stats(); len(st) != 1 || st[0].Runs != 1 || st[0].Confirmed != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	// Sem confirmação: desiste no timeout e conta como falha
+	inCombat = false
+	pa = e.Start("attack", func() {}, action.Confirm{Combat: true})
+	for i := 0; i < 100; i++ {
+		if res, done = e.Poll(pa); done {
+			break
+		}
+		clock.Advance(25 * time.Millisecond)
+	}
+	if !done || res.Confirmed || res.Latency != 1500*time.Millisecond {
+		t.Fatalf("unexpected timeout result %+v (done %v)", res, done)
+	}
+	if st := e.Stats(); st[0].Runs != 2 || st[0].Rate() != 50 {
+		t.Fatalf("unexpected stats after timeout %+v", st)
+	}
+}
+
+func TestGoneAndResourceConfirm(t *testing.T) {
+	e, _ := newExecutor()
+	exists := true
+	e.Exists = func(id uint32) bool { return id != 42 || exists }
+	res := e.Do("loot", func() { exists = false }, action.Confirm{Gone: 42})
+	if !res.Confirmed || res.By != "gone" || res.Presses != 1 {
+		t.Fatalf("unexpected gone confirm %+v", res)
+	}
+
+	// HP subindo confirma a potion; com drop só a queda confirma
+	hp := float32(40)
+	e.Resource = func(name string) (float32, bool) { return hp, name == "player_hp" }
+	res = e.Do("potion", func() { hp += 10 }, action.Confirm{Resource: "player_hp"})
+	if !res.Confirmed || res.By != "resource" || res.Presses != 1 {
+		t.Fatalf("unexpected resource confirm %+v", res)
+	}
+	res = e.Do("execute", func() { hp += 10 }, action.Confirm{Resource: "player_hp", Drop: true})
+	if res.Confirmed || res.Presses < 2 {
+		t.Fatalf("rising resource confirmed a drop %+v", res)
+	}
+}
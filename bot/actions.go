@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"archefriend/action"
+)
+
+// ====================
+// Pending actions
+// ====================
+
+// Slots das ações confirmadas: no máximo uma ação em andamento por slot,
+// então uma potion não espera o cast da rotação (e vice-versa).
+const (
+	slotCombat    = "combat"    // attack key e rotação
+	slotThreshold = "threshold" // threshold rules (potions)
+	slotPress     = "press"     // press da behavior tree e keys dos goals
+	slotLoot      = "loot"      // loot key depois do kill
+)
+
+// KeySequenceInterval é o intervalo entre as teclas de uma sequência
+// (threshold com várias keys, keys de um goal).
+const KeySequenceInterval = 30 * time.Millisecond
+
+// botAction é uma ação confirmada em andamento. O tick chama Poll (que
+// repete o press no backoff) em vez de esperar a confirmação: morte, potions
+// e target perdido continuam sendo checados enquanto ela não confirma.
+type botAction struct {
+	pending *action.Pending
+	done    func(action.Result) // nil = só as stats do executor
+}
+
+// startAction dispara a ação no slot (1º press já neste tick) e chama done
+// quando confirmar ou desistir. Sem confirmação é um press só, com done na
+// hora. Retorna false se o slot já tem uma ação em andamento.
+func (b *Bot) startAction(slot, name string, press func(), c action.Confirm, done func(action.Result)) bool {
+	if c.Empty() {
+		if b.actionBusy(slot) {
+			return false
+		}
+		press()
+		if done != nil {
+			done(action.Result{Name: name, Confirmed: true, Presses: 1})
+		}
+		return true
+	}
+
+	if b.actionBusy(slot) {
+		return false
+	}
+	// Start fora do lock: lê os sinais (recursos) que travam b.mu
+	a := &botAction{pending: b.actions.Start(name, press, c), done: done}
+	b.mu.Lock()
+	b.inflight[slot] = a
+	b.mu.Unlock()
+
+	b.pollAction(slot, a)
+	return true
+}
+
+// actionBusy indica se o slot tem uma ação esperando confirmação.
+func (b *Bot) actionBusy(slot string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, busy := b.inflight[slot]
+	return busy
+}
+
+// tickActions avança as ações em andamento (chamado a cada tick, antes da
+// behavior tree). A ação de combate cai junto com o target.
+func (b *Bot) tickActions() {
+	b.mu.Lock()
+	if b.currentTarget == nil {
+		delete(b.inflight, slotCombat)
+	}
+	running := make(map[string]*botAction, len(b.inflight))
+	for slot, a := range b.inflight {
+		running[slot] = a
+	}
+	b.mu.Unlock()
+
+	// Ordem fixa: potions primeiro (replay/simulação deterministas)
+	for _, slot := range []string{slotThreshold, slotCombat, slotPress, slotLoot} {
+		if a, ok := running[slot]; ok {
+			b.pollAction(slot, a)
+		}
+	}
+}
+
+func (b *Bot) pollAction(slot string, a *botAction) {
+	res, done := b.actions.Poll(a.pending)
+	if !done {
+		return
+	}
+	b.mu.Lock()
+	if b.inflight[slot] == a {
+		delete(b.inflight, slot)
+	}
+	b.mu.Unlock()
+	if a.done != nil {
+		a.done(res)
+	}
+}
+
+// cancelActionsLocked descarta as ações em andamento sem contar nas stats
+// (morte, stop). Caller must hold b.mu.
+func (b *Bot) cancelActionsLocked(reason string) {
+	for slot, a := range b.inflight {
+		fmt.Printf("[BOT] Action %s cancelada: %s\n", a.pending.Name(), reason)
+		delete(b.inflight, slot)
+	}
+}
+
+// combatConfirm é a confirmação do attack key e das habilidades sem
+// skill_id: a flag de combate do player. Sem provider = um press só.
+func (b *Bot) combatConfirm() action.Confirm {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.config.IsPlayerInCombat == nil {
+		return action.Confirm{}
+	}
+	return action.Confirm{Combat: true}
+}
+
+// entityExists indica se a entidade ainda está na entity list (sinal "gone"
+// do executor: corpo lootado some).
+func (b *Bot) entityExists(id uint32) bool {
+	for _, e := range b.GetEntityProvider().GetEntities() {
+		if e.EntityID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package bot_test
+
+import (
+	"archefriend/action"
+	"archefriend/bot"
+	"archefriend/sim"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestActions(t *testing.T) {
+	// Potion de MP com confirm: o cast só registra no 3º press
+	w := sim.NewWorld()
+	cfg := w.Config()
+	cfg.Thresholds = []bot.ThresholdRule{{Name: "mp", Resource: bot.ResourcePlayerMP, Value: 30,
+		Keys: []string{"F2"}, CooldownMs: 20000, Confirm: action.Confirm{Skill: 500}}}
+	b := w.NewBot(cfg)
+	w.OnKey = func(key string) {
+		if key == "F2" && w.KeyCount("F2") == 3 {
+			b.OnSkillCast(500)
+		}
+	}
+	w.SetPlayerMP(200)
+	// Não bloqueia o tick: um press por tick, o retry sai nos próximos
+	w.Run(b, 1, step)
+	if n := w.KeyCount("F2"); n != 1 || w.Clock.Now().Sub(w.KeyLog()[0].At) > 0 {
+		t.Fatalf("tick blocked waiting for confirmation (%d presses)", n)
+	}
+	w.Run(b, int(time.Second/step), step)
+	if n := w.KeyCount("F2"); n != 3 {
+		t.Fatalf("expected 3 presses until confirmed, got %d", n)
+	}
+	if s := b.GetStats(); s.MPPotions != 1 {
+		t.Fatalf("confirmed potion not counted (%d)", s.MPPotions)
+	}
+	stats := b.ActionStats()
+	if len(stats) != 1 || stats[0].Runs != 1 || stats[0].Confirmed != 1 || stats[0].Presses != 3 {
+		t.Fatalf("unexpected action stats %+v", stats)
+	}
+
+	// Sem confirmação: backoff 150/300/600ms (checado a cada tick) até o
+	// timeout de 1.5s, não conta e não gasta o cooldown
+	w = sim.NewWorld()
+	cfg = w.Config()
+	cfg.Thresholds = []bot.ThresholdRule{{Name: "mp", Resource: bot.ResourcePlayerMP, Value: 30,
+		Keys: []string{"F2"}, CooldownMs: 20000, Confirm: action.Confirm{Skill: 500}}}
+	b = w.NewBot(cfg)
+	w.SetPlayerMP(200)
+	start := w.Clock.Now()
+	w.Run(b, int(1500*time.Millisecond/step), step)
+	var at []time.Duration
+	for _, kp := range w.KeyLog() {
+		at = append(at, kp.At.Sub(start))
+	}
+	want := []time.Duration{20 * time.Millisecond, 180 * time.Millisecond, 480 * time.Millisecond, 1080 * time.Millisecond}
+	if fmt.Sprint(at) != fmt.Sprint(want) {
+		t.Fatalf("unexpected retry times %v, want %v", at, want)
+	}
+	w.Run(b, 2, step)
+	if s := b.GetStats(); s.MPPotions != 0 {
+		t.Fatalf("unconfirmed potion counted")
+	}
+	if st := b.ActionStats(); len(st) != 1 || st[0].Runs != 1 || st[0].Confirmed != 0 || st[0].Rate() != 0 {
+		t.Fatalf("unexpected action stats %+v", st)
+	}
+	if n := w.KeyCount("F2"); n != 5 {
+		t.Fatalf("unconfirmed potion used up its cooldown (%d presses)", n)
+	}
+
+	// Player morre com a potion pendente: a ação é descartada
+	w = sim.NewWorld()
+	cfg = w.Config()
+	cfg.Thresholds = []bot.ThresholdRule{{Name: "mp", Resource: bot.ResourcePlayerMP, Value: 30,
+		Keys: []string{"F2"}, CooldownMs: 20000, Confirm: action.Confirm{Skill: 500}}}
+	b = w.NewBot(cfg)
+	w.SetPlayerMP(200)
+	w.Run(b, 1, step)
+	w.SetPlayerDead(true)
+	w.Run(b, int(time.Second/step), step)
+	if n := w.KeyCount("F2"); n != 1 || b.GetState() != bot.StateDead {
+		t.Fatalf("pending potion kept pressing after death (%d presses, %s)", n, b.GetState())
+	}
+
+	// Rotação com skill_id: um press por uso (sem keyspam) e taxas na sessão
+	w = sim.NewWorld()
+	w.Damage["1"] = 10
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+	cfg = w.Config("Wolf")
+	cfg.Rotation = &bot.Rotation{Abilities: []bot.Ability{{Name: "Strike", Key: "1", SkillID: 100}}}
+	b = w.NewBot(cfg)
+	w.OnKey = func(key string) {
+		if key == "1" {
+			b.OnSkillCast(100)
+		}
+	}
+	if !w.RunUntil(b, 2000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed with confirmed rotation (HP %d)", w.Mob(1).HP)
+	}
+	session := b.GetSession()
+	if len(session.Actions) != 1 || session.Actions[0].Name != "Strike" ||
+		session.Actions[0].Runs != 10 || session.Actions[0].Presses != 10 || session.Actions[0].Rate() != 100 {
+		t.Fatalf("unexpected rotation action stats %+v", session.Actions)
+	}
+
+}
@@ -0,0 +1,584 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"archefriend/action"
+)
+
+// ====================
+// Behavior tree
+// ====================
+//
+// O tick do bot executa uma árvore de comportamento. A árvore padrão
+// (DefaultBehaviorTree) reproduz a máquina de estados original; árvores
+// customizadas são carregadas de JSON ("behavior_tree" no bot_config.json):
+//
+//	{"type": "sequence", "children": [
+//	  {"type": "succeed", "children": [{"type": "action", "name": "potions"}]},
+//	  {"type": "selector", "children": [
+//	    {"type": "sequence", "children": [
+//	      {"type": "condition", "name": "state", "key": "IDLE"},
+//	      {"type": "action", "name": "acquire_target"}]},
+//	    ...
+//	  ]}
+//	]}
+//
+// Para ver a árvore padrão em JSON: go run ./cmd/debug/bot_tree
+
+// Status é o resultado de um nó.
+type Status int
+
+const (
+	Success Status = iota
+	Failure
+	Running
+)
+
+func (s Status) String() string {
+	switch s {
+	case Success:
+		return "SUCCESS"
+	case Failure:
+		return "FAILURE"
+	case Running:
+		return "RUNNING"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Tipos de nó aceitos no JSON
+const (
+	NodeSequence  = "sequence"  // roda filhos em ordem até um não ter sucesso
+	NodeSelector  = "selector"  // roda filhos em ordem até um não falhar
+	NodeInvert    = "invert"    // decorator: troca sucesso/falha
+	NodeSucceed   = "succeed"   // decorator: sempre sucesso
+	NodeCooldown  = "cooldown"  // decorator: filho no máximo 1x por duration_ms
+	NodeCondition = "condition" // condição registrada (name)
+	NodeAction    = "action"    // ação registrada (name)
+)
+
+// NodeSpec é a definição serializável de um nó.
+type NodeSpec struct {
+	Type       string         `json:"type"`
+	Name       string         `json:"name,omitempty"`        // condition/action
+	Key        string         `json:"key,omitempty"`         // argumento texto (ex: state, tecla)
+	Value      float64        `json:"value,omitempty"`       // argumento numérico (ex: % HP)
+	DurationMs int            `json:"duration_ms,omitempty"` // cooldown
+	Confirm    action.Confirm `json:"confirm,omitempty"`     // press: repete até confirmar (vazio = um press)
+	Comment    string         `json:"comment,omitempty"`
+	Children   []NodeSpec     `json:"children,omitempty"`
+}
+
+// Node é um nó executável.
+type Node interface {
+	Tick(b *Bot) Status
+}
+
+// ActionFunc executa uma ação do bot. args vem do NodeSpec (key/value).
+type ActionFunc func(b *Bot, args NodeSpec) Status
+
+// ConditionFunc avalia uma condição do bot.
+type ConditionFunc func(b *Bot, args NodeSpec) bool
+
+var (
+	registryMu sync.RWMutex
+	actions    = map[string]ActionFunc{}
+	conditions = map[string]ConditionFunc{}
+)
+
+// RegisterAction registra uma ação usável nas árvores ("action" name).
+func RegisterAction(name string, fn ActionFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	actions[name] = fn
+}
+
+// RegisterCondition registra uma condição usável nas árvores ("condition" name).
+func RegisterCondition(name string, fn ConditionFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	conditions[name] = fn
+}
+
+// ActionNames / ConditionNames listam o que está registrado (para erros/docs).
+func ActionNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(actions))
+	for n := range actions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ConditionNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(conditions))
+	for n := range conditions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ====================
+// Nodes
+// ====================
+
+type sequenceNode struct{ children []Node }
+
+func (n *sequenceNode) Tick(b *Bot) Status {
+	for _, c := range n.children {
+		if s := c.Tick(b); s != Success {
+			return s
+		}
+	}
+	return Success
+}
+
+type selectorNode struct{ children []Node }
+
+func (n *selectorNode) Tick(b *Bot) Status {
+	for _, c := range n.children {
+		if s := c.Tick(b); s != Failure {
+			return s
+		}
+	}
+	return Failure
+}
+
+type invertNode struct{ child Node }
+
+func (n *invertNode) Tick(b *Bot) Status {
+	switch s := n.child.Tick(b); s {
+	case Success:
+		return Failure
+	case Failure:
+		return Success
+	default:
+		return s
+	}
+}
+
+type succeedNode struct{ child Node }
+
+func (n *succeedNode) Tick(b *Bot) Status {
+	n.child.Tick(b)
+	return Success
+}
+
+type cooldownNode struct {
+	child    Node
+	duration time.Duration
+	last     time.Time
+}
+
+func (n *cooldownNode) Tick(b *Bot) Status {
+	now := b.now()
+	if !n.last.IsZero() && now.Sub(n.last) < n.duration {
+		return Failure
+	}
+	s := n.child.Tick(b)
+	if s != Failure {
+		n.last = now
+	}
+	return s
+}
+
+type conditionNode struct {
+	fn   ConditionFunc
+	spec NodeSpec
+}
+
+func (n *conditionNode) Tick(b *Bot) Status {
+	if n.fn(b, n.spec) {
+		return Success
+	}
+	return Failure
+}
+
+type actionNode struct {
+	fn   ActionFunc
+	spec NodeSpec
+}
+
+func (n *actionNode) Tick(b *Bot) Status {
+	return n.fn(b, n.spec)
+}
+
+// BuildBehaviorTree valida o spec e monta a árvore executável.
+func BuildBehaviorTree(spec NodeSpec) (Node, error) {
+	return buildNode(spec, "root")
+}
+
+func buildNode(spec NodeSpec, path string) (Node, error) {
+	children := func() ([]Node, error) {
+		nodes := make([]Node, 0, len(spec.Children))
+		for i, c := range spec.Children {
+			n, err := buildNode(c, fmt.Sprintf("%s/%d:%s", path, i, c.Type))
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		}
+		return nodes, nil
+	}
+	single := func() (Node, error) {
+		if len(spec.Children) != 1 {
+			return nil, fmt.Errorf("%s: %s needs exactly 1 child, got %d", path, spec.Type, len(spec.Children))
+		}
+		nodes, err := children()
+		if err != nil {
+			return nil, err
+		}
+		return nodes[0], nil
+	}
+
+	switch spec.Type {
+	case NodeSequence, NodeSelector:
+		if len(spec.Children) == 0 {
+			return nil, fmt.Errorf("%s: %s without children", path, spec.Type)
+		}
+		nodes, err := children()
+		if err != nil {
+			return nil, err
+		}
+		if spec.Type == NodeSequence {
+			return &sequenceNode{children: nodes}, nil
+		}
+		return &selectorNode{children: nodes}, nil
+
+	case NodeInvert:
+		child, err := single()
+		if err != nil {
+			return nil, err
+		}
+		return &invertNode{child: child}, nil
+
+	case NodeSucceed:
+		child, err := single()
+		if err != nil {
+			return nil, err
+		}
+		return &succeedNode{child: child}, nil
+
+	case NodeCooldown:
+		if spec.DurationMs <= 0 {
+			return nil, fmt.Errorf("%s: cooldown needs duration_ms > 0", path)
+		}
+		child, err := single()
+		if err != nil {
+			return nil, err
+		}
+		return &cooldownNode{child: child, duration: time.Duration(spec.DurationMs) * time.Millisecond}, nil
+
+	case NodeCondition:
+		registryMu.RLock()
+		fn, ok := conditions[spec.Name]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown condition %q (available: %s)", path, spec.Name, strings.Join(ConditionNames(), ", "))
+		}
+		return &conditionNode{fn: fn, spec: spec}, nil
+
+	case NodeAction:
+		registryMu.RLock()
+		fn, ok := actions[spec.Name]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown action %q (available: %s)", path, spec.Name, strings.Join(ActionNames(), ", "))
+		}
+		return &actionNode{fn: fn, spec: spec}, nil
+
+	default:
+		return nil, fmt.Errorf("%s: unknown node type %q", path, spec.Type)
+	}
+}
+
+// LoadBehaviorTree lê e valida uma árvore de um arquivo JSON.
+func LoadBehaviorTree(filename string) (*NodeSpec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var spec NodeSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filename, err)
+	}
+	if _, err := BuildBehaviorTree(spec); err != nil {
+		return nil, fmt.Errorf("invalid tree in %s: %v", filename, err)
+	}
+	return &spec, nil
+}
+
+// ====================
+// Default tree
+// ====================
+
+func stateBranch(state BotState, action string) NodeSpec {
+	return NodeSpec{Type: NodeSequence, Children: []NodeSpec{
+		{Type: NodeCondition, Name: "state", Key: state.String()},
+		{Type: NodeAction, Name: action},
+	}}
+}
+
+// DefaultBehaviorTree reproduz o tick original: potions e loot agendado
+// sempre, depois a ação do estado atual. Sem mobs em IDLE, patrulha a rota.
+func DefaultBehaviorTree() NodeSpec {
+	return NodeSpec{Type: NodeSequence, Comment: "default bot behavior", Children: []NodeSpec{
+		{Type: NodeSucceed, Children: []NodeSpec{{Type: NodeAction, Name: "potions"}}},
+		{Type: NodeSucceed, Children: []NodeSpec{{Type: NodeAction, Name: "loot"}}},
+		{Type: NodeSelector, Children: []NodeSpec{
+			{Type: NodeSequence, Children: []NodeSpec{
+				{Type: NodeCondition, Name: "state", Key: StateIdle.String()},
+				{Type: NodeSelector, Children: []NodeSpec{
+					{Type: NodeAction, Name: "start_return"},
+					{Type: NodeAction, Name: "start_rest"},
+					{Type: NodeAction, Name: "acquire_target"},
+					{Type: NodeAction, Name: "start_patrol"},
+				}},
+			}},
+			stateBranch(StatePatrolling, "patrol"),
+			stateBranch(StateTargeting, "set_target"),
+			stateBranch(StateCombat, "combat"),
+			stateBranch(StateApproaching, "approach"),
+			stateBranch(StateReturning, "return"),
+			stateBranch(StateResting, "rest"),
+			stateBranch(StateLooting, "finish_loot"),
+		}},
+	}}
+}
+
+// ====================
+// Built-in actions / conditions
+// ====================
+
+func init() {
+	RegisterAction("potions", func(b *Bot, _ NodeSpec) Status {
+		b.tickThresholds()
+		return Success
+	})
+	RegisterAction("loot", func(b *Bot, _ NodeSpec) Status {
+		b.tickPendingLoot()
+		return Success
+	})
+	// Scan + escolha de target. Sucesso se entrou em TARGETING.
+	RegisterAction("acquire_target", func(b *Bot, _ NodeSpec) Status {
+		b.tickIdle()
+		if b.GetState() == StateTargeting {
+			return Success
+		}
+		return Failure
+	})
+	// SetTarget + confirmação. Sucesso se entrou em COMBAT.
+	RegisterAction("set_target", func(b *Bot, _ NodeSpec) Status {
+		b.tickTargeting()
+		if b.GetState() == StateCombat {
+			return Success
+		}
+		return Failure
+	})
+	// Um tick de combate. Running enquanto o target estiver vivo.
+	RegisterAction("combat", func(b *Bot, _ NodeSpec) Status {
+		b.tickCombat()
+		if b.GetState() == StateCombat {
+			return Running
+		}
+		return Success
+	})
+	// Entra em PATROLLING se há rota configurada.
+	RegisterAction("start_patrol", func(b *Bot, _ NodeSpec) Status {
+		if !b.canPatrol() {
+			return Failure
+		}
+		b.setState(StatePatrolling)
+		return Success
+	})
+	// Anda pela rota. Sucesso quando acha target (TARGETING).
+	RegisterAction("patrol", func(b *Bot, _ NodeSpec) Status {
+		b.tickPatrol()
+		if b.GetState() == StateTargeting {
+			return Success
+		}
+		return Running
+	})
+	RegisterAction("stop_moving", func(b *Bot, _ NodeSpec) Status {
+		b.stopMoving()
+		return Success
+	})
+	// Anda até o target. Sucesso ao entrar na engage distance (COMBAT).
+	RegisterAction("approach", func(b *Bot, _ NodeSpec) Status {
+		b.tickApproach()
+		switch b.GetState() {
+		case StateApproaching:
+			return Running
+		case StateCombat:
+			return Success
+		default:
+			return Failure
+		}
+	})
+	// Entra em RETURNING se o player estiver fora do leash.
+	RegisterAction("start_return", func(b *Bot, _ NodeSpec) Status {
+		if b.startReturn() {
+			return Success
+		}
+		return Failure
+	})
+	// Anda até o anchor. Sucesso ao chegar (IDLE).
+	RegisterAction("return", func(b *Bot, _ NodeSpec) Status {
+		b.tickReturn()
+		if b.GetState() == StateReturning {
+			return Running
+		}
+		return Success
+	})
+	// Entra em RESTING se HP/MP estiverem abaixo dos limiares de descanso.
+	RegisterAction("start_rest", func(b *Bot, _ NodeSpec) Status {
+		if b.startRest() {
+			return Success
+		}
+		return Failure
+	})
+	// Espera regenerar. Sucesso ao terminar (IDLE).
+	RegisterAction("rest", func(b *Bot, _ NodeSpec) Status {
+		b.tickRest()
+		if b.GetState() == StateResting {
+			return Running
+		}
+		return Success
+	})
+	RegisterAction("finish_loot", func(b *Bot, _ NodeSpec) Status {
+		b.setState(StateIdle)
+		return Success
+	})
+	RegisterAction("clear_target", func(b *Bot, _ NodeSpec) Status {
+		b.clearTarget("behavior tree")
+		return Success
+	})
+	// Pressiona uma tecla. key = tecla/combo. Com confirm repete até
+	// confirmar nos próximos ticks (Running enquanto o press anterior não
+	// terminou); sem confirm é um press só
+	RegisterAction("press", func(b *Bot, args NodeSpec) Status {
+		b.mu.RLock()
+		sendKey := b.config.SendKey
+		b.mu.RUnlock()
+		if sendKey == nil || args.Key == "" {
+			return Failure
+		}
+		if !b.startAction(slotPress, "press "+args.Key, func() { sendKey(args.Key) }, args.Confirm, nil) {
+			return Running
+		}
+		return Success
+	})
+	RegisterAction("set_state", func(b *Bot, args NodeSpec) Status {
+		s, ok := ParseBotState(args.Key)
+		if !ok {
+			return Failure
+		}
+		b.setState(s)
+		return Success
+	})
+
+	// key = nome do estado (IDLE, COMBAT, ...)
+	RegisterCondition("state", func(b *Bot, args NodeSpec) bool {
+		return strings.EqualFold(b.GetState().String(), args.Key)
+	})
+	RegisterCondition("has_target", func(b *Bot, _ NodeSpec) bool {
+		return b.GetCurrentTarget() != nil
+	})
+	RegisterCondition("has_route", func(b *Bot, _ NodeSpec) bool {
+		return b.canPatrol()
+	})
+	RegisterCondition("outside_leash", func(b *Bot, _ NodeSpec) bool {
+		outside, _ := b.playerOutsideLeash()
+		return outside
+	})
+	RegisterCondition("needs_rest", func(b *Bot, _ NodeSpec) bool {
+		need, _ := b.needsRest()
+		return need
+	})
+	RegisterCondition("player_dead", func(b *Bot, _ NodeSpec) bool {
+		b.mu.RLock()
+		fn := b.config.IsPlayerDead
+		b.mu.RUnlock()
+		return fn != nil && fn()
+	})
+	RegisterCondition("queue_empty", func(b *Bot, _ NodeSpec) bool {
+		return b.GetKillQueueCount() == 0
+	})
+	// value = % (0..100)
+	RegisterCondition("player_hp_below", func(b *Bot, args NodeSpec) bool {
+		p, ok := b.playerHPPercent()
+		return ok && p < float32(args.Value)
+	})
+	RegisterCondition("player_mp_below", func(b *Bot, args NodeSpec) bool {
+		p, ok := b.playerMPPercent()
+		return ok && p < float32(args.Value)
+	})
+	RegisterCondition("target_hp_below", func(b *Bot, args NodeSpec) bool {
+		t := b.GetCurrentTarget()
+		return t != nil && hpPercent(*t) < args.Value
+	})
+	// value = buff ID
+	RegisterCondition("has_buff", func(b *Bot, args NodeSpec) bool {
+		b.mu.RLock()
+		fn := b.config.HasPlayerBuff
+		b.mu.RUnlock()
+		return fn != nil && fn(uint32(args.Value))
+	})
+}
+
+// ParseBotState converte o nome ("IDLE", "combat") para BotState.
+func ParseBotState(name string) (BotState, bool) {
+	for s := StateIdle; s.String() != "UNKNOWN"; s++ {
+		if strings.EqualFold(s.String(), name) {
+			return s, true
+		}
+	}
+	return StateIdle, false
+}
+
+func (b *Bot) playerHPPercent() (float32, bool) {
+	b.mu.RLock()
+	fn := b.config.GetPlayerHP
+	b.mu.RUnlock()
+	if fn == nil {
+		return 0, false
+	}
+	cur, max := fn()
+	if max == 0 {
+		return 0, false
+	}
+	return float32(cur) / float32(max) * 100, true
+}
+
+func (b *Bot) playerMPPercent() (float32, bool) {
+	b.mu.RLock()
+	fn := b.config.GetPlayerMP
+	b.mu.RUnlock()
+	if fn == nil {
+		return 0, false
+	}
+	cur, max := fn()
+	if max == 0 {
+		return 0, false
+	}
+	return float32(cur) / float32(max) * 100, true
+}
+
+func (b *Bot) canPatrol() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.navigator != nil && b.navigator.HasRoute() && b.config.GetPlayerPos != nil
+}
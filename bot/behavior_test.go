@@ -0,0 +1,50 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+func TestCustomTree(t *testing.T) {
+	// Árvore customizada: com HP < 30% pressiona "R" (no máximo a cada 5s)
+	// e não procura target; senão segue o comportamento padrão.
+	def := bot.DefaultBehaviorTree()
+	tree := bot.NodeSpec{Type: bot.NodeSelector, Children: []bot.NodeSpec{
+		{Type: bot.NodeSequence, Children: []bot.NodeSpec{
+			{Type: bot.NodeCondition, Name: "player_hp_below", Value: 30},
+			{Type: bot.NodeSucceed, Children: []bot.NodeSpec{
+				{Type: bot.NodeCooldown, DurationMs: 5000, Children: []bot.NodeSpec{
+					{Type: bot.NodeAction, Name: "press", Key: "R"},
+				}},
+			}},
+		}},
+		def,
+	}}
+
+	w := sim.NewWorld()
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 200})
+	cfg := w.Config("Wolf")
+	cfg.BehaviorTree = &tree
+	b := w.NewBot(cfg)
+
+	w.SetPlayerHP(200)
+	w.Run(b, int(6*time.Second/step), step)
+	if n := w.KeyCount("R"); n != 2 {
+		t.Fatalf("expected 2 rests in 6s, got %d presses", n)
+	}
+	if tgt := b.GetCurrentTarget(); tgt != nil {
+		t.Fatalf("targeted %s while resting", tgt.Name)
+	}
+
+	w.SetPlayerHP(1000)
+	if !w.RunUntil(b, 100, step, func() bool { return b.GetState() == bot.StateCombat }) {
+		t.Fatalf("default branch not reached after HP recovered")
+	}
+
+	bad := bot.NodeSpec{Type: bot.NodeAction, Name: "fly"}
+	if err := b.SetBehaviorTree(&bad); err == nil {
+		t.Fatalf("unknown action accepted")
+	}
+}
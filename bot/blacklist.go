@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ====================
+// Abandon / blacklist
+// ====================
+
+// BlacklistEntry é um mob abandonado que fica fora da kill queue até Until.
+type BlacklistEntry struct {
+	EntityID uint32
+	Name     string
+	Reason   string
+	At       time.Time
+	Until    time.Time
+}
+
+// Motivos de abandono
+const (
+	AbandonUnreachable = "unreachable" // approach timeout
+	AbandonNoProgress  = "no progress" // HP não cai (evade/imune)
+	AbandonMismatch    = "mismatch"    // SetTarget não pega
+)
+
+// SetStuckDetection configura quando abandonar um target: sem queda de HP
+// por noProgress (0 = desativado), maxMismatches falhas seguidas de
+// SetTarget (0 = desativado) e por quanto tempo ignorar o mob.
+func (b *Bot) SetStuckDetection(noProgress time.Duration, maxMismatches int, blacklistFor time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.NoProgressTimeout = noProgress
+	b.config.MaxTargetMismatches = maxMismatches
+	if blacklistFor > 0 {
+		b.config.BlacklistTime = blacklistFor
+	}
+}
+
+// GetBlacklist retorna os mobs ainda na blacklist, do que expira primeiro
+// ao último.
+func (b *Bot) GetBlacklist() []BlacklistEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	now := b.now()
+	result := make([]BlacklistEntry, 0, len(b.blacklist))
+	for _, e := range b.blacklist {
+		if now.Before(e.Until) {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Until.Before(result[j].Until) })
+	return result
+}
+
+// isBlacklisted indica se o mob está na blacklist (remove entradas
+// expiradas). Chamar com b.mu travado.
+func (b *Bot) isBlacklisted(id uint32, now time.Time) bool {
+	e, ok := b.blacklist[id]
+	if !ok {
+		return false
+	}
+	if now.Before(e.Until) {
+		return true
+	}
+	delete(b.blacklist, id)
+	return false
+}
+
+// abandonTarget desiste do target: blacklist temporária, remove da fila e
+// volta para IDLE. detail complementa o motivo no log.
+func (b *Bot) abandonTarget(target EntityInfo, reason, detail string) {
+	b.mu.Lock()
+	now := b.now()
+	d := b.config.BlacklistTime
+	b.blacklist[target.EntityID] = BlacklistEntry{
+		EntityID: target.EntityID,
+		Name:     target.Name,
+		Reason:   reason,
+		At:       now,
+		Until:    now.Add(d),
+	}
+	b.stats.Abandoned++
+	b.session.Abandoned++
+	b.session.mob(target.Name).Abandoned++
+	b.mu.Unlock()
+
+	fmt.Printf("[BOT] Abandon: %s (ID:%d) - %s (%s) - blacklisted for %s\n",
+		target.Name, target.EntityID, reason, detail, d)
+	b.removeFromQueueWithReason(target.EntityID, reason)
+	b.clearTarget("abandoned: " + reason)
+}
+
+// resetProgress reinicia o acompanhamento de dano do target atual.
+func (b *Bot) resetProgress(hp uint32) {
+	b.mu.Lock()
+	b.progressHP = hp
+	b.progressAt = b.now()
+	b.mu.Unlock()
+}
+
+// checkProgress registra quedas de HP do target e indica se ele ficou
+// mais que NoProgressTimeout sem tomar dano.
+func (b *Bot) checkProgress(hp uint32) (stuck bool, since time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if hp < b.progressHP {
+		b.progressHP = hp
+		b.progressAt = now
+		return false, 0
+	}
+	since = now.Sub(b.progressAt)
+	timeout := b.config.NoProgressTimeout
+	return timeout > 0 && since >= timeout, since
+}
+
+// onTargetMismatch conta falhas seguidas de SetTarget no mesmo mob.
+// Retorna true se o mob foi abandonado.
+func (b *Bot) onTargetMismatch(target EntityInfo, detail string) bool {
+	b.mu.Lock()
+	if b.mismatchID != target.EntityID {
+		b.mismatchID = target.EntityID
+		b.mismatchCount = 0
+	}
+	b.mismatchCount++
+	count := b.mismatchCount
+	max := b.config.MaxTargetMismatches
+	b.mu.Unlock()
+
+	if max > 0 && count >= max {
+		b.mu.Lock()
+		b.mismatchID, b.mismatchCount = 0, 0
+		b.mu.Unlock()
+		b.abandonTarget(target, AbandonMismatch, fmt.Sprintf("%d in a row, last: %s", count, detail))
+		return true
+	}
+	return false
+}
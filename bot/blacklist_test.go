@@ -0,0 +1,61 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+func TestStuck(t *testing.T) {
+	newWorld := func() (*sim.World, *bot.Bot) {
+		w := sim.NewWorld()
+		w.Damage["1"] = 50
+		w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 10, MaxHP: 100})
+		cfg := w.Config("Wolf")
+		cfg.NoProgressTimeout = 3 * time.Second
+		cfg.BlacklistTime = 10 * time.Second
+		return w, w.NewBot(cfg)
+	}
+	blacklisted := func(b *bot.Bot, id uint32, reason string) bool {
+		for _, e := range b.GetBlacklist() {
+			if e.EntityID == id && e.Reason == reason {
+				return true
+			}
+		}
+		return false
+	}
+
+	// sim.Mob em evade: sem queda de HP abandona, mata o outro e só volta
+	// ao primeiro quando a blacklist expira
+	w, b := newWorld()
+	w.Mob(1).Immune = true
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().Abandoned == 1 }) {
+		t.Fatalf("evading mob never abandoned (state %s)", b.GetState())
+	}
+	if !blacklisted(b, 1, bot.AbandonNoProgress) {
+		t.Fatalf("expected mob 1 blacklisted for no progress")
+	}
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("second mob not killed after abandon")
+	}
+	w.Run(b, int(5*time.Second/step), step)
+	if tgt := b.GetCurrentTarget(); tgt != nil && tgt.EntityID == 1 {
+		t.Fatalf("blacklisted mob targeted again")
+	}
+	w.Mob(1).Immune = false
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 2 }) {
+		t.Fatalf("mob not retried after blacklist expired")
+	}
+
+	// SetTarget nunca pega: abandona após 3 mismatches seguidos
+	w, b = newWorld()
+	w.RejectTarget = func(id uint32) bool { return id == 1 }
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mismatch: second mob not killed (state %s)", b.GetState())
+	}
+	if !blacklisted(b, 1, bot.AbandonMismatch) {
+		t.Fatalf("mismatch: expected mob 1 blacklisted for mismatch")
+	}
+}
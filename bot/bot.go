@@ -1,37 +1,11 @@
 package bot
 
 import (
+	"archefriend/action"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
-	"unsafe"
-
-	"golang.org/x/sys/windows"
-)
-
-// ====================
-// Constants (SetTarget)
-// ====================
-
-const (
-	OFFSET_SET_TARGET     uintptr = 0x1BE090
-	PTR_ENEMY_TARGET_BASE uintptr = 0x19EBF4
-	OFF_TARGET_ID         uintptr = 0x08
-
-	MEM_COMMIT             = 0x1000
-	MEM_RESERVE            = 0x2000
-	MEM_RELEASE            = 0x8000
-	PAGE_EXECUTE_READWRITE = 0x40
-)
-
-var (
-	kernel32               = windows.NewLazySystemDLL("kernel32.dll")
-	procVirtualAllocEx     = kernel32.NewProc("VirtualAllocEx")
-	procVirtualFreeEx      = kernel32.NewProc("VirtualFreeEx")
-	procWriteProcessMem    = kernel32.NewProc("WriteProcessMemory")
-	procReadProcessMem     = kernel32.NewProc("ReadProcessMemory")
-	procCreateRemoteThread = kernel32.NewProc("CreateRemoteThread")
 )
 
 // ====================
@@ -45,6 +19,11 @@ const (
 	StateTargeting
 	StateCombat
 	StateLooting
+	StatePatrolling
+	StateApproaching
+	StateReturning
+	StateResting
+	StateDead
 )
 
 func (s BotState) String() string {
@@ -57,6 +36,16 @@ func (s BotState) String() string {
 		return "COMBAT"
 	case StateLooting:
 		return "LOOTING"
+	case StatePatrolling:
+		return "PATROLLING"
+	case StateApproaching:
+		return "APPROACHING"
+	case StateReturning:
+		return "RETURNING"
+	case StateResting:
+		return "RESTING"
+	case StateDead:
+		return "DEAD"
 	default:
 		return "UNKNOWN"
 	}
@@ -81,6 +70,7 @@ type EntityInfo struct {
 	IsPlayer bool
 	IsNPC    bool
 	IsMate   bool
+	Level    uint32 // 0 = desconhecido (regra max_level não se aplica)
 }
 
 // EntityProvider fornece entidades pro bot.
@@ -89,6 +79,14 @@ type EntityProvider interface {
 	GetEntities() []EntityInfo
 }
 
+// Targeter seleciona o target no client e lê o target atual.
+// Em produção é o shellcode de SetTarget (process_windows.go); replay e
+// simulação injetam implementações fake.
+type Targeter interface {
+	SetTarget(unitID uint32) error
+	GetCurrentTargetID() uint32
+}
+
 // RangeProvider fornece range dinâmica (sincroniza com ESP overlay).
 type RangeProvider interface {
 	GetMaxRange() float32
@@ -126,6 +124,11 @@ type Config struct {
 	TargetDelay  time.Duration // delay após setar target
 	PartialMatch bool          // contains vs exact match
 
+	// Target prioritization (ver selector.go)
+	Strategy      string         // fifo, nearest, lowest_hp, priority, cluster, damaged_first
+	Priorities    map[string]int // pesos por nome (strategy "priority")
+	ClusterRadius float32        // raio da strategy "cluster" (0 = 8m)
+
 	// Auto-combat settings
 	AttackKey    string        // tecla de ataque (ex: "1", "F")
 	LootKey      string        // tecla de loot (ex: "F", "E")
@@ -134,6 +137,66 @@ type Config struct {
 	AutoAttack   bool          // atacar automaticamente
 	AutoLoot     bool          // lootar automaticamente
 
+	// Rotação de skills (opcional). Quando definida substitui o spam do
+	// AttackKey em combate (ver rotation.go).
+	Rotation *Rotation
+
+	// Behavior tree (opcional) - nil usa DefaultBehaviorTree (ver behavior.go)
+	BehaviorTree *NodeSpec
+
+	// Patrulha (opcional) - sem Route o bot fica parado esperando mobs
+	Route    *Route
+	Movement MovementConfig
+
+	// Approach: anda até o target quando ele está além da distância de
+	// engajamento (0 = desativado, ataca de onde estiver)
+	EngageDistance  float32
+	ApproachTimeout time.Duration
+
+	// Regras por nome e blacklist permanente (ver rules.go). MobNames
+	// continua sendo a whitelist: só mobs da lista são considerados.
+	MobRules  []MobRule
+	Blacklist MobBlacklist
+
+	// Stuck/evade: abandona o target sem queda de HP por NoProgressTimeout
+	// ou após MaxTargetMismatches falhas seguidas de SetTarget (0 = desativa).
+	// Mobs abandonados (inclusive approach timeout) ficam fora da fila por
+	// BlacklistTime (ver blacklist.go).
+	NoProgressTimeout   time.Duration
+	MaxTargetMismatches int
+	BlacklistTime       time.Duration
+
+	// Leash: só mobs a até LeashRadius do anchor entram na fila, e o bot
+	// volta ao anchor quando termina uma luta fora dele (0 = desativado).
+	// Anchor nil = posição do player ao iniciar o bot.
+	LeashRadius float32
+	Anchor      *Waypoint
+
+	// Descanso entre pulls (ver rest.go)
+	Rest RestConfig
+
+	// O que fazer quando o player morre (ver death.go)
+	Death DeathConfig
+
+	// Uso da flag de combate do player (ver combat.go)
+	Combat CombatConfig
+
+	// Pasta onde cada sessão é salva no Stop (ver session.go, "" = não salva)
+	SessionDir string
+
+	// Regras de threshold (ver thresholds.go). Vazio = potions de HP/MP
+	// abaixo (LegacyPotionRules)
+	Thresholds []ThresholdRule
+
+	// Retry/backoff das ações com confirm (thresholds, rotação com skill_id)
+	Actions action.Policy
+
+	// Goals em sequência e onde salvar o progresso (ver goals.go).
+	// ConfigFile é o bot_config.json usado pelos goals que trocam de preset.
+	Goals         []Goal
+	GoalStateFile string
+	ConfigFile    string
+
 	// Potion settings
 	HPPotionKey       string        // tecla HP potion
 	HPPotionThreshold float32       // % HP para usar
@@ -143,17 +206,38 @@ type Config struct {
 	MPPotionEnabled   bool          // usar MP potion automaticamente
 	PotionCooldown    time.Duration // cooldown entre potions (21s)
 
-	// Callbacks (opcionais)
-	OnTargetAcquired func(target EntityInfo)
-	OnTargetDead     func(target EntityInfo)
-	OnCombatTick     func(target EntityInfo)
-
 	// Key sender (injetado pelo main)
 	SendKey func(key string)
+	// Key sender das threshold rules/potions (lane potion do scheduler de
+	// input). nil = SendKey.
+	SendPotionKey func(key string)
 
 	// Player stats provider (injetado pelo main)
 	GetPlayerHP func() (current, max uint32) // retorna HP atual e máximo
 	GetPlayerMP func() (current, max uint32) // retorna MP atual e máximo
+
+	// Flag de morte do player (config.OFF_IS_DEAD). nil = não detecta morte.
+	IsPlayerDead func() bool
+
+	// Flag de combate do player (config.OFF_COMBAT_RAW). nil = ignora.
+	IsPlayerInCombat func() bool
+
+	// Posição do player (necessária para patrulha)
+	GetPlayerPos func() (x, y, z float32, ok bool)
+
+	// Segurar/soltar tecla (movimento). Sem eles o movimento usa SendKey (tap).
+	KeyDown func(key string)
+	KeyUp   func(key string)
+
+	// Buff/debuff/cooldown providers para as condições da rotação (opcionais)
+	HasPlayerBuff   func(id uint32) bool
+	HasPlayerDebuff func(id uint32) bool
+	HasTargetDebuff func(id uint32) bool
+	IsSkillReady    func(skillID uint32) bool
+
+	// Clock (opcional) - nil usa o relógio do sistema. A simulação injeta
+	// um relógio virtual para rodar cenários de forma determinística.
+	Clock Clock
 }
 
 func DefaultConfig() Config {
@@ -163,12 +247,25 @@ func DefaultConfig() Config {
 		ScanInterval: 20 * time.Millisecond,  // Fast: ~50 scans/sec
 		TargetDelay:  50 * time.Millisecond,  // Fast: quick target confirm
 		PartialMatch: false,
+		Strategy:     StrategyFIFO,
 		AttackKey:    "1",
 		LootKey:      "F",
 		AttackDelay:  500 * time.Millisecond,
 		LootDelay:    300 * time.Millisecond,
 		AutoAttack:   true,
 		AutoLoot:     true,
+		// Approach defaults (desativado até configurar engage distance)
+		EngageDistance:  0,
+		ApproachTimeout: 10 * time.Second,
+		// Stuck detection
+		NoProgressTimeout:   10 * time.Second,
+		MaxTargetMismatches: 3,
+		BlacklistTime:       60 * time.Second,
+		// Descanso, morte, combate e ações confirmadas
+		Rest:    DefaultRestConfig(),
+		Death:   DefaultDeathConfig(),
+		Combat:  DefaultCombatConfig(),
+		Actions: action.DefaultPolicy(),
 		// Potion defaults
 		HPPotionKey:       "5",
 		HPPotionThreshold: 50.0,
@@ -189,6 +286,12 @@ type Stats struct {
 	TargetsSet   int
 	StartTime    time.Time
 	LastTargetAt time.Time
+	RestTime     time.Duration // tempo total em RESTING
+	Rests        int
+	Deaths       int
+	Abandoned    int // targets abandonados (stuck/evade/unreachable)
+	HPPotions    int
+	MPPotions    int
 }
 
 // ====================
@@ -196,8 +299,7 @@ type Stats struct {
 // ====================
 
 type Bot struct {
-	handle   windows.Handle
-	x2game   uintptr
+	targeter Targeter
 	config   Config
 	state    BotState
 	mu       sync.RWMutex
@@ -212,9 +314,91 @@ type Bot struct {
 	lastAttackTime  time.Time
 	lastLootTime    time.Time
 
-	// Potion cooldown tracking
-	lastHPPotionTime time.Time
-	lastMPPotionTime time.Time
+	// Regras de threshold (potions etc, ver thresholds.go)
+	thresholds *ThresholdEngine
+
+	// Loot agendado (substitui a goroutine com sleep: processado no tick)
+	pendingLoot *pendingLoot
+
+	// Skill rotation (nil = spam do AttackKey)
+	rotation *RotationEngine
+
+	// Behavior tree executada a cada tick
+	tree Node
+
+	// Patrulha entre pulls
+	navigator *Navigator
+
+	// Approach até o target
+	approachNav   *Navigator
+	approachStart time.Time
+	approachFromX float32 // posição do target ao iniciar (detecta mob andando)
+	approachFromY float32
+
+	// Motivo de cada mob da lista fora da fila no último scan (rules.go)
+	skipped map[uint32]SkipInfo
+
+	// Mobs abandonados e progresso do target atual (ver blacklist.go)
+	blacklist     map[uint32]BlacklistEntry
+	progressHP    uint32
+	progressAt    time.Time
+	mismatchID    uint32
+	mismatchCount int
+
+	// Leash: anchor efetivo (config ou posição ao iniciar) e volta até ele
+	anchor    *Waypoint
+	returnNav *Navigator
+
+	// Descanso
+	restStart        time.Time
+	restLastHP       float32   // HP% do último tick (detecta dano)
+	restBlockedUntil time.Time // após interrupção
+
+	// Morte: trace de HP, mortes da sessão e a sequência de res em andamento
+	hpTrace      []HPSample
+	lastHPSample time.Time
+	deaths       []DeathRecord
+	deathAt      time.Time
+	deathPolicy  string // política efetiva da morte atual
+	resStep      int
+	resAttempts  int
+	resNextAt    time.Time
+
+	// Flag de combate do player e confirmação do engage (ver combat.go)
+	inCombat        bool
+	combatChangedAt time.Time
+	engageStart     time.Time
+	engageConfirmed bool
+
+	// Eventos enfileirados até o fim do tick (ver events.go)
+	events        *EventBus
+	pendingEvents []Event
+
+	// Ações confirmadas (retry até o cast/buff/target) e suas taxas; as em
+	// andamento ficam por slot e são checadas a cada tick (ver actions.go)
+	actions  *action.Executor
+	inflight map[string]*botAction
+
+	// Sessão atual (kills por mob, TTK, potions...) e preset ativo
+	session  *SessionRecord
+	preset   string
+	ttkID    uint32 // target do TTK em andamento
+	ttkStart time.Time
+
+	// Progresso dos goals (ver goals.go)
+	goalState    GoalState
+	goalLastTick time.Time
+	goalSavedAt  time.Time
+
+	// Target prioritization
+	selector TargetSelector
+	engaged  map[uint32]bool // mobs já atacados pelo bot (damaged_first)
+}
+
+// pendingLoot é um loot que deve ser disparado quando o relógio passar de At.
+type pendingLoot struct {
+	Target EntityInfo
+	At     time.Time
 }
 
 // getEffectiveRange returns the bot's configured range.
@@ -225,17 +409,88 @@ func (b *Bot) getEffectiveRange() float32 {
 	return b.config.MaxRange
 }
 
-func New(handle windows.Handle, x2game uintptr, provider EntityProvider, cfg Config) *Bot {
-	return &Bot{
-		handle:         handle,
-		x2game:         x2game,
+// NewWithTargeter cria o bot com um Targeter customizado (replay/simulação).
+// Em produção use New, que injeta o SetTarget via shellcode.
+func NewWithTargeter(targeter Targeter, provider EntityProvider, cfg Config) *Bot {
+	selector, err := NewTargetSelector(cfg.Strategy, SelectorOptions{
+		Priorities:    cfg.Priorities,
+		ClusterRadius: cfg.ClusterRadius,
+	})
+	if err != nil {
+		fmt.Printf("[BOT] %v - usando fifo\n", err)
+		selector, _ = NewTargetSelector(StrategyFIFO, SelectorOptions{})
+		cfg.Strategy = StrategyFIFO
+	}
+
+	var rotation *RotationEngine
+	if cfg.Rotation != nil {
+		if rotation, err = NewRotationEngine(*cfg.Rotation); err != nil {
+			fmt.Printf("[BOT] Rotation: %v - usando attack key\n", err)
+			rotation = nil
+		}
+	}
+
+	treeSpec := DefaultBehaviorTree()
+	if cfg.BehaviorTree != nil {
+		treeSpec = *cfg.BehaviorTree
+	}
+	tree, err := BuildBehaviorTree(treeSpec)
+	if err != nil {
+		fmt.Printf("[BOT] Behavior tree: %v - usando padrão\n", err)
+		tree, _ = BuildBehaviorTree(DefaultBehaviorTree())
+		cfg.BehaviorTree = nil
+	}
+
+	var route Route
+	if cfg.Route != nil {
+		route = *cfg.Route
+	}
+
+	b := &Bot{
+		blacklist:      make(map[uint32]BlacklistEntry),
+		approachNav:    NewNavigator(Route{}, cfg.Movement),
+		returnNav:      NewNavigator(Route{}, cfg.Movement),
+		anchor:         copyWaypoint(cfg.Anchor),
+		navigator:      NewNavigator(route, cfg.Movement),
+		tree:           tree,
+		rotation:       rotation,
+		selector:       selector,
+		engaged:        make(map[uint32]bool),
+		targeter:       targeter,
 		config:         cfg,
 		state:          StateIdle,
 		provider:       provider,
 		killQueue:      make(map[uint32]EntityInfo),
 		killQueueOrder: make([]uint32, 0),
 		stopChan:       make(chan struct{}),
+		events:         NewEventBus(),
 	}
+	b.session = newSession(b.now(), "", cfg.MobNames)
+	b.actions = action.NewExecutor(cfg.Actions, b.now, b.sleep)
+	b.actions.HasBuff = cfg.HasPlayerBuff
+	b.actions.TargetID = b.getCurrentTargetId
+	b.actions.InCombat = func() bool {
+		b.mu.RLock()
+		fn := b.config.IsPlayerInCombat
+		b.mu.RUnlock()
+		return fn != nil && fn()
+	}
+	b.actions.Exists = b.entityExists
+	b.actions.Resource = func(name string) (float32, bool) {
+		return b.botResources().Read(name)
+	}
+	b.inflight = make(map[string]*botAction)
+	if err := b.rebuildThresholdsLocked(); err != nil {
+		fmt.Printf("[BOT] Thresholds: %v - usando potions da config\n", err)
+		b.config.Thresholds = nil
+		b.rebuildThresholdsLocked()
+	}
+	if err := validateGoals(cfg.Goals); err != nil {
+		fmt.Printf("[BOT] Goals: %v - ignorando goals\n", err)
+		b.config.Goals = nil
+	}
+	b.loadGoalStateLocked()
+	return b
 }
 
 // ====================
@@ -243,43 +498,91 @@ func New(handle windows.Handle, x2game uintptr, provider EntityProvider, cfg Con
 // ====================
 
 func (b *Bot) Start() {
+	b.mu.RLock()
+	getPos := b.config.GetPlayerPos
+	b.mu.RUnlock()
+
+	// Anchor padrão: onde o player está agora
+	var startPos *Waypoint
+	if getPos != nil {
+		if x, y, z, ok := getPos(); ok {
+			startPos = &Waypoint{X: x, Y: y, Z: z}
+		}
+	}
+
 	b.mu.Lock()
 	if b.running {
 		b.mu.Unlock()
 		return
 	}
 	b.running = true
-	b.stats.StartTime = time.Now()
+	b.anchor = copyWaypoint(b.config.Anchor)
+	if b.anchor == nil {
+		b.anchor = startPos
+	}
+	b.stats.StartTime = b.now()
 	// Recria o canal para cada nova execução
 	b.stopChan = make(chan struct{})
 	// Limpa a kill queue ao reiniciar
 	b.killQueue = make(map[uint32]EntityInfo)
 	b.killQueueOrder = make([]uint32, 0)
 	b.currentTarget = nil
+	b.pendingLoot = nil
+	b.setStateLocked(StateIdle)
+	b.hpTrace = nil
+	b.engaged = make(map[uint32]bool)
+	b.blacklist = make(map[uint32]BlacklistEntry)
+	b.mismatchID, b.mismatchCount = 0, 0
+	b.restBlockedUntil = time.Time{}
+	b.inCombat, b.combatChangedAt = false, time.Time{}
+	if b.rotation != nil {
+		b.rotation.Reset()
+	}
 	b.mu.Unlock()
+	b.beginSession()
+	b.flushEvents()
 
 	go b.loop()
 	fmt.Println("[BOT] Started")
 	fmt.Printf("[BOT] Mobs: %v | Range: %.0fm | Match: %s\n",
 		b.config.MobNames, b.config.MaxRange, matchMode(b.config.PartialMatch))
+	if anchor, radius, ok := b.GetLeash(); ok {
+		fmt.Printf("[BOT] Leash: %.0fm around (%.0f, %.0f)\n", radius, anchor.X, anchor.Y)
+	}
 }
 
 func (b *Bot) Stop() {
 	b.mu.Lock()
 	if !b.running {
 		b.mu.Unlock()
+		// Mesmo parado (ex: Step manual) não deixa tecla de movimento presa
+		b.stopMoving()
+		b.stopApproach()
+		b.stopReturn()
 		return
 	}
 	b.running = false
 	stopChan := b.stopChan
+	b.cancelActionsLocked("bot parado")
 	b.mu.Unlock()
 
+	// Solta teclas de movimento que possam ter ficado pressionadas
+	b.stopMoving()
+	b.stopApproach()
+	b.stopReturn()
+
 	// Fecha o canal fora do lock para evitar deadlock
 	if stopChan != nil {
 		close(stopChan)
 	}
 	fmt.Println("[BOT] Stopped")
 	b.PrintStats()
+	b.mu.Lock()
+	b.goalLastTick = time.Time{}
+	b.saveGoalStateLocked()
+	b.mu.Unlock()
+	b.EndSession()
+	b.flushEvents()
 }
 
 func (b *Bot) IsRunning() bool {
@@ -307,14 +610,27 @@ func (b *Bot) GetCurrentTarget() *EntityInfo {
 func (b *Bot) GetStats() Stats {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	return b.stats
+	s := b.stats
+	// Descanso em andamento também conta
+	if b.state == StateResting {
+		s.RestTime += b.now().Sub(b.restStart)
+	}
+	return s
 }
 
 func (b *Bot) PrintStats() {
 	s := b.GetStats()
-	elapsed := time.Since(s.StartTime)
-	fmt.Printf("[BOT] Stats: %d killed | %d targets | uptime %s\n",
-		s.MobsKilled, s.TargetsSet, elapsed.Round(time.Second))
+	elapsed := b.now().Sub(s.StartTime)
+	restPct := 0.0
+	if elapsed > 0 {
+		restPct = float64(s.RestTime) / float64(elapsed) * 100
+	}
+	fmt.Printf("[BOT] Stats: %d killed | %d targets | %d abandoned | uptime %s | rest %s (%.0f%%)\n",
+		s.MobsKilled, s.TargetsSet, s.Abandoned, elapsed.Round(time.Second), s.RestTime.Round(time.Second), restPct)
+	fmt.Printf("[BOT] Potions: %d HP / %d MP | %d deaths\n", s.HPPotions, s.MPPotions, s.Deaths)
+	for _, a := range b.actions.Stats() {
+		fmt.Printf("[BOT] Action %s\n", a)
+	}
 }
 
 // ====================
@@ -360,6 +676,182 @@ func (b *Bot) SetPartialMatch(partial bool) {
 	b.config.PartialMatch = partial
 }
 
+// SetStrategy troca a estratégia de seleção de target em runtime.
+func (b *Bot) SetStrategy(strategy string, priorities map[string]int, clusterRadius float32) error {
+	selector, err := NewTargetSelector(strategy, SelectorOptions{
+		Priorities:    priorities,
+		ClusterRadius: clusterRadius,
+	})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.selector = selector
+	b.config.Strategy = selector.Name()
+	b.config.Priorities = priorities
+	b.config.ClusterRadius = clusterRadius
+	if selector.Name() == StrategyPriority {
+		fmt.Printf("[BOT] Strategy: %s %s\n", selector.Name(), FormatPriorities(priorities))
+	} else {
+		fmt.Printf("[BOT] Strategy: %s\n", selector.Name())
+	}
+	return nil
+}
+
+// SetRotation troca a rotação de skills (nil = volta para o AttackKey).
+func (b *Bot) SetRotation(r *Rotation) error {
+	var engine *RotationEngine
+	if r != nil {
+		var err error
+		if engine, err = NewRotationEngine(*r); err != nil {
+			return err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rotation = engine
+	b.config.Rotation = r
+	if engine != nil {
+		fmt.Printf("[BOT] Rotation: %d abilities\n", len(r.Abilities))
+	}
+	return nil
+}
+
+// SetRoute troca a rota de patrulha (nil = sem patrulha).
+func (b *Bot) SetRoute(r *Route, movement MovementConfig) {
+	b.stopMoving()
+
+	var route Route
+	if r != nil {
+		route = *r
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Route = r
+	b.config.Movement = movement
+	b.navigator = NewNavigator(route, movement)
+	b.approachNav = NewNavigator(Route{}, movement)
+	b.returnNav = NewNavigator(Route{}, movement)
+	if b.state == StatePatrolling && !b.navigator.HasRoute() {
+		b.setStateLocked(StateIdle)
+	}
+	if len(route.Waypoints) > 0 {
+		fmt.Printf("[BOT] Route: %d waypoints (loop: %v)\n", len(route.Waypoints), route.Loop)
+	}
+}
+
+// SetApproach configura a distância de engajamento (0 = desativa) e o timeout.
+func (b *Bot) SetApproach(engageDistance float32, timeout time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.EngageDistance = engageDistance
+	if timeout > 0 {
+		b.config.ApproachTimeout = timeout
+	}
+}
+
+// SetLeash configura o raio do leash (0 = desativa). anchor nil mantém o
+// anchor atual (ou a posição do player no próximo Start).
+func (b *Bot) SetLeash(anchor *Waypoint, radius float32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.LeashRadius = radius
+	b.config.Anchor = copyWaypoint(anchor)
+	if anchor != nil {
+		b.anchor = copyWaypoint(anchor)
+	}
+	if radius > 0 && b.anchor != nil {
+		fmt.Printf("[BOT] Leash: %.0fm around (%.0f, %.0f)\n", radius, b.anchor.X, b.anchor.Y)
+	}
+}
+
+// SetAnchorHere move o anchor para a posição atual do player.
+func (b *Bot) SetAnchorHere() bool {
+	b.mu.RLock()
+	getPos := b.config.GetPlayerPos
+	b.mu.RUnlock()
+	if getPos == nil {
+		return false
+	}
+	x, y, z, ok := getPos()
+	if !ok {
+		return false
+	}
+	b.mu.Lock()
+	b.anchor = &Waypoint{X: x, Y: y, Z: z}
+	b.mu.Unlock()
+	fmt.Printf("[BOT] Anchor: (%.0f, %.0f, %.0f)\n", x, y, z)
+	return true
+}
+
+// GetAnchor retorna o anchor atual (false se ainda não conhecido).
+func (b *Bot) GetAnchor() (Waypoint, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.anchor == nil {
+		return Waypoint{}, false
+	}
+	return *b.anchor, true
+}
+
+// GetLeash retorna o anchor e o raio do leash (ok = false se desativado
+// ou se o anchor ainda não é conhecido). Usado pelo overlay do ESP.
+func (b *Bot) GetLeash() (anchor Waypoint, radius float32, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.config.LeashRadius <= 0 || b.anchor == nil {
+		return Waypoint{}, 0, false
+	}
+	return *b.anchor, b.config.LeashRadius, true
+}
+
+// OnSkillCast deve ser ligado ao SkillMonitor.OnSkillCast: confirma casts
+// para cooldown/GCD da rotação e para as ações com confirm.
+func (b *Bot) OnSkillCast(skillID uint32) {
+	b.mu.RLock()
+	rotation := b.rotation
+	b.mu.RUnlock()
+	if rotation != nil {
+		rotation.OnSkillCast(skillID, b.now())
+	}
+	b.actions.OnSkillCast(skillID)
+}
+
+// SetActionPolicy troca o retry/backoff das ações com confirm.
+func (b *Bot) SetActionPolicy(p action.Policy) {
+	b.mu.Lock()
+	b.config.Actions = p
+	b.mu.Unlock()
+	b.actions.SetPolicy(p)
+}
+
+// ActionStats retorna as taxas de confirmação da sessão atual.
+func (b *Bot) ActionStats() []action.Stats {
+	return b.actions.Stats()
+}
+
+// GetLastAbility retorna a última habilidade usada pela rotação ("" se nenhuma).
+func (b *Bot) GetLastAbility() string {
+	b.mu.RLock()
+	rotation := b.rotation
+	b.mu.RUnlock()
+	if rotation == nil {
+		return ""
+	}
+	name, _ := rotation.LastUsed()
+	return name
+}
+
+// GetStrategy retorna o nome da estratégia ativa.
+func (b *Bot) GetStrategy() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.selector.Name()
+}
+
 func (b *Bot) SetAttackKey(key string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -410,6 +902,7 @@ func (b *Bot) SetHPPotion(key string, threshold float32, enabled bool) {
 	b.config.HPPotionKey = key
 	b.config.HPPotionThreshold = threshold
 	b.config.HPPotionEnabled = enabled
+	b.rebuildThresholdsLocked()
 	if enabled {
 		fmt.Printf("[BOT] HP Potion: %s (< %.0f%%)\n", key, threshold)
 	}
@@ -421,6 +914,7 @@ func (b *Bot) SetMPPotion(key string, threshold float32, enabled bool) {
 	b.config.MPPotionKey = key
 	b.config.MPPotionThreshold = threshold
 	b.config.MPPotionEnabled = enabled
+	b.rebuildThresholdsLocked()
 	if enabled {
 		fmt.Printf("[BOT] MP Potion: %s (< %.0f%%)\n", key, threshold)
 	}
@@ -430,6 +924,7 @@ func (b *Bot) SetPotionCooldown(ms int) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.config.PotionCooldown = time.Duration(ms) * time.Millisecond
+	b.rebuildThresholdsLocked()
 	fmt.Printf("[BOT] Potion cooldown: %dms\n", ms)
 }
 
@@ -445,6 +940,20 @@ func (b *Bot) SetPlayerMPProvider(fn func() (uint32, uint32)) {
 	b.config.GetPlayerMP = fn
 }
 
+// SetEntityProvider troca a fonte de entidades em runtime (ex: Recorder).
+func (b *Bot) SetEntityProvider(p EntityProvider) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.provider = p
+}
+
+// GetEntityProvider retorna a fonte de entidades atual.
+func (b *Bot) GetEntityProvider() EntityProvider {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.provider
+}
+
 func (b *Bot) GetConfig() Config {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -462,36 +971,78 @@ func (b *Bot) UpdateKillQueue(entities []EntityInfo, maxRange float32, mobNames
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	// Cria set de IDs atuais válidos
+	// Cria set de IDs atuais válidos. Mobs da lista que ficam de fora
+	// têm o motivo registrado (GetSkipped)
+	now := b.now()
 	currentValid := make(map[uint32]EntityInfo)
+	skipped := make(map[uint32]SkipInfo)
 	for _, e := range entities {
-		if e.Distance > maxRange || e.HP == 0 {
+		if !matchName(e.Name, mobNames, partial) {
 			continue
 		}
-		if !matchName(e.Name, mobNames, partial) {
+		if e.HP == 0 {
+			b.recordSkip(skipped, e, SkipDead)
+			continue
+		}
+		if e.Distance > maxRange {
+			b.recordSkip(skipped, e, SkipOutOfRange)
+			continue
+		}
+		// Fora do leash: nunca entra na fila, mesmo dentro da range do player
+		if b.config.LeashRadius > 0 && b.anchor != nil &&
+			dist2D(e.PosX, e.PosY, b.anchor.X, b.anchor.Y) > b.config.LeashRadius {
+			b.recordSkip(skipped, e, "out of leash")
+			continue
+		}
+		if b.isBlacklisted(e.EntityID, now) {
+			b.recordSkip(skipped, e, "abandoned: "+b.blacklist[e.EntityID].Reason)
+			continue
+		}
+		if reason := b.checkMobRules(e, entities); reason != "" {
+			b.recordSkip(skipped, e, reason)
 			continue
 		}
 		currentValid[e.EntityID] = e
 	}
+	b.skipped = skipped
 
 	// Remove mobs que não são mais válidos (mortos, fora de range, etc)
 	// Também remove da ordem FIFO
 	newOrder := make([]uint32, 0, len(b.killQueueOrder))
+	var removed []Event
 	for _, id := range b.killQueueOrder {
 		if _, ok := currentValid[id]; ok {
 			newOrder = append(newOrder, id)
 		} else {
-			// Mob saiu da range ou morreu
+			// Mob saiu da range ou morreu: o motivo é o do skip, ou sumiu
+			// da entity list
+			reason := "gone"
+			if s, ok := skipped[id]; ok {
+				reason = s.Reason
+			}
+			removed = append(removed, Event{Type: EventQueueRemove, Target: b.killQueue[id], Reason: reason})
 			delete(b.killQueue, id)
+			delete(b.engaged, id)
 		}
 	}
 	b.killQueueOrder = newOrder
+	for _, e := range removed {
+		e.Queue = len(newOrder)
+		b.emitLocked(e)
+	}
 
-	// Adiciona novos mobs ao FINAL da queue (FIFO)
-	for id, e := range currentValid {
+	// Adiciona novos mobs ao FINAL da queue (FIFO), na ordem da entity list
+	// para que mobs vistos no mesmo scan entrem numa ordem estável
+	for _, cur := range entities {
+		e, valid := currentValid[cur.EntityID]
+		if !valid {
+			continue
+		}
+		id := e.EntityID
 		if _, exists := b.killQueue[id]; !exists {
 			b.killQueue[id] = e
 			b.killQueueOrder = append(b.killQueueOrder, id) // Vai pro final
+			b.emitLocked(Event{Type: EventQueueAdd, Target: e, Queue: len(b.killQueueOrder)})
 			fmt.Printf("[BOT] +Queue[%d]: %s (ID:%d HP:%d Dist:%.0fm)\n",
 				len(b.killQueueOrder), e.Name, e.EntityID, e.HP, e.Distance)
 		} else {
@@ -518,6 +1069,7 @@ func (b *Bot) removeFromQueueWithReason(entityID uint32, reason string) {
 	if e, ok := b.killQueue[entityID]; ok {
 		fmt.Printf("[BOT] -Queue: %s (ID:%d) - %s\n", e.Name, entityID, reason)
 		delete(b.killQueue, entityID)
+		delete(b.engaged, entityID)
 
 		// Remove da ordem FIFO
 		for i, id := range b.killQueueOrder {
@@ -526,6 +1078,7 @@ func (b *Bot) removeFromQueueWithReason(entityID uint32, reason string) {
 				break
 			}
 		}
+		b.emitLocked(Event{Type: EventQueueRemove, Target: e, Reason: reason, Queue: len(b.killQueueOrder)})
 	}
 }
 
@@ -590,90 +1143,61 @@ func (b *Bot) loop() {
 	}
 }
 
-func (b *Bot) tick() {
-	// Always check potions regardless of state
-	b.tickPotions()
-
-	b.mu.RLock()
-	state := b.state
-	b.mu.RUnlock()
-
-	switch state {
-	case StateIdle:
-		b.tickIdle()
-	case StateTargeting:
-		b.tickTargeting()
-	case StateCombat:
-		b.tickCombat()
-	case StateLooting:
-		b.setState(StateIdle)
-	}
+// Step executa um único tick do bot, sem a goroutine do loop.
+// Usado pelo replay e pela simulação para avançar frame a frame.
+func (b *Bot) Step() {
+	b.tick()
 }
 
-// tickPotions verifica HP/MP e usa potions se necessário
-func (b *Bot) tickPotions() {
+func (b *Bot) tick() {
 	b.mu.RLock()
-	hpEnabled := b.config.HPPotionEnabled
-	mpEnabled := b.config.MPPotionEnabled
-	hpKey := b.config.HPPotionKey
-	mpKey := b.config.MPPotionKey
-	hpThreshold := b.config.HPPotionThreshold
-	mpThreshold := b.config.MPPotionThreshold
-	cooldown := b.config.PotionCooldown
-	sendKey := b.config.SendKey
-	getHP := b.config.GetPlayerHP
-	getMP := b.config.GetPlayerMP
-	lastHP := b.lastHPPotionTime
-	lastMP := b.lastMPPotionTime
+	tree := b.tree
 	b.mu.RUnlock()
+	defer b.flushEvents()
 
-	if sendKey == nil {
+	// Morto: nenhum input, só a política de morte (ver death.go)
+	b.sampleHP()
+	b.sampleCombat()
+	if b.tickDeath() {
 		return
 	}
+	b.tickActions()
 
-	now := time.Now()
-
-	// Check HP Potion
-	if hpEnabled && getHP != nil && hpKey != "" {
-		if now.Sub(lastHP) >= cooldown {
-			current, max := getHP()
-			if max > 0 {
-				percent := float32(current) / float32(max) * 100
-				if percent < hpThreshold && percent > 0 {
-					sendKeySpam(sendKey, hpKey)
-					b.mu.Lock()
-					b.lastHPPotionTime = now
-					b.mu.Unlock()
-					fmt.Printf("[BOT] HP Potion used (%.0f%% < %.0f%%) [x%d]\n", percent, hpThreshold, KeySpamCount)
-				}
-			}
-		}
-	}
+	// A árvore padrão checa potions e loot agendado sempre, depois
+	// executa a ação do estado atual (ver DefaultBehaviorTree)
+	tree.Tick(b)
+	b.tickGoals()
+}
 
-	// Check MP Potion
-	if mpEnabled && getMP != nil && mpKey != "" {
-		if now.Sub(lastMP) >= cooldown {
-			current, max := getMP()
-			if max > 0 {
-				percent := float32(current) / float32(max) * 100
-				if percent < mpThreshold && percent > 0 {
-					sendKeySpam(sendKey, mpKey)
-					b.mu.Lock()
-					b.lastMPPotionTime = now
-					b.mu.Unlock()
-					fmt.Printf("[BOT] MP Potion used (%.0f%% < %.0f%%) [x%d]\n", percent, mpThreshold, KeySpamCount)
-				}
-			}
-		}
+// SetBehaviorTree troca a árvore em runtime (nil = árvore padrão).
+func (b *Bot) SetBehaviorTree(spec *NodeSpec) error {
+	treeSpec := DefaultBehaviorTree()
+	if spec != nil {
+		treeSpec = *spec
+	}
+	tree, err := BuildBehaviorTree(treeSpec)
+	if err != nil {
+		return err
 	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tree = tree
+	b.config.BehaviorTree = spec
+	return nil
 }
 
 func (b *Bot) tickIdle() {
-	entities := b.provider.GetEntities()
+	entities := b.GetEntityProvider().GetEntities()
 	if len(entities) == 0 {
 		return
 	}
 
+	// Add batendo no player tem prioridade sobre a fila (ver combat.go)
+	if b.takeAggressor(entities) {
+		return
+	}
+
 	// Use dynamic range from ESP if available
 	maxRange := b.getEffectiveRange()
 
@@ -686,6 +1210,9 @@ func (b *Bot) tickIdle() {
 		return
 	}
 
+	// Bot rodando via Step (replay/simulação) não passa pelo Start
+	b.ensureAnchor()
+
 	// Atualiza kill queue com mobs válidos na range
 	b.UpdateKillQueue(entities, maxRange, mobNames, partial)
 
@@ -695,11 +1222,10 @@ func (b *Bot) tickIdle() {
 		currentEntities[e.EntityID] = e
 	}
 
-	// Pega o próximo target da queue (FIFO - primeiro a entrar)
-	// Só seleciona mobs que existem na lista atual E tem HP > 0
+	// Candidatos: mobs da queue (em ordem FIFO) que existem na lista atual
+	// E tem HP > 0. A estratégia escolhe entre eles.
 	b.mu.Lock()
-	var first *EntityInfo
-
+	candidates := make([]EntityInfo, 0, len(b.killQueueOrder))
 	for _, id := range b.killQueueOrder {
 		if _, ok := b.killQueue[id]; ok {
 			// Verifica se o mob ainda existe na lista de entidades atual
@@ -712,23 +1238,27 @@ func (b *Bot) tickIdle() {
 				// Mob morto - pular, UpdateKillQueue vai remover
 				continue
 			}
-			// Mob válido: existe e tem HP > 0
-			cpy := currentEntity
-			first = &cpy
-			break
+			candidates = append(candidates, currentEntity)
 		}
 	}
+	engaged := make(map[uint32]bool, len(b.engaged))
+	for id := range b.engaged {
+		engaged[id] = true
+	}
+	selector := b.selector
 	b.mu.Unlock()
 
+	first := selector.Select(SelectContext{Candidates: candidates, Engaged: engaged})
+
 	if first != nil {
 		b.mu.Lock()
 		b.currentTarget = first
-		b.state = StateTargeting
+		b.setStateLocked(StateTargeting)
 		b.mu.Unlock()
 
 		queueCount := b.GetKillQueueCount()
-		fmt.Printf("[BOT] Target: %s (ID:%d HP:%d Dist:%.0fm) [Queue: %d]\n",
-			first.Name, first.EntityID, first.HP, first.Distance, queueCount)
+		fmt.Printf("[BOT] Target: %s (ID:%d HP:%d Dist:%.0fm) [Queue: %d | %s]\n",
+			first.Name, first.EntityID, first.HP, first.Distance, queueCount, selector.Name())
 	}
 }
 
@@ -745,31 +1275,40 @@ func (b *Bot) tickTargeting() {
 	if err := b.setTarget(target.EntityID); err != nil {
 		fmt.Printf("[BOT] SetTarget failed: %v\n", err)
 		// Não remove da queue aqui - deixa UpdateKillQueue validar o estado
-		b.clearTarget()
+		if !b.onTargetMismatch(*target, err.Error()) {
+			b.clearTarget("set target failed: " + err.Error())
+		}
 		return
 	}
 
-	time.Sleep(b.config.TargetDelay)
+	b.sleep(b.config.TargetDelay)
 
 	// Confirma que pegou
-	if b.getCurrentTargetId() != target.EntityID {
-		fmt.Printf("[BOT] Target mismatch - tentando novamente\n")
-		// Não remove da queue - pode ser lag do client, tenta de novo
-		b.clearTarget()
+	if got := b.getCurrentTargetId(); got != target.EntityID {
+		// Pode ser lag do client: tenta de novo, até MaxTargetMismatches seguidas
+		if !b.onTargetMismatch(*target, fmt.Sprintf("client target %d", got)) {
+			fmt.Printf("[BOT] Target mismatch - tentando novamente\n")
+			b.clearTarget(fmt.Sprintf("target mismatch (client %d)", got))
+		}
 		return
 	}
 
+	b.resetProgress(target.HP)
 	b.mu.Lock()
+	b.mismatchID, b.mismatchCount = 0, 0
 	b.stats.TargetsSet++
-	b.stats.LastTargetAt = time.Now()
-	b.state = StateCombat
+	b.stats.LastTargetAt = b.now()
+	// TTK conta do primeiro target confirmado no mob (retarget não zera)
+	if b.ttkID != target.EntityID {
+		b.ttkID = target.EntityID
+		b.ttkStart = b.now()
+	}
+	b.setStateLocked(StateCombat)
+	b.startEngageLocked()
 	b.mu.Unlock()
 
 	fmt.Printf("[BOT] Targeting: %s (ID:%d)\n", target.Name, target.EntityID)
-
-	if b.config.OnTargetAcquired != nil {
-		b.config.OnTargetAcquired(*target)
-	}
+	b.emit(Event{Type: EventTargetAcquired, Target: *target})
 }
 
 func (b *Bot) tickCombat() {
@@ -795,7 +1334,7 @@ func (b *Bot) tickCombat() {
 	}
 
 	// Ainda vivo na entity list?
-	entities := b.provider.GetEntities()
+	entities := b.GetEntityProvider().GetEntities()
 	alive := false
 	maxRange := b.getEffectiveRange()
 
@@ -815,7 +1354,7 @@ func (b *Bot) tickCombat() {
 				fmt.Printf("[BOT] Target out of range: %s (%.0fm > %.0fm)\n", target.Name, e.Distance, maxRange)
 				// Remove da kill queue também para não ser selecionado de novo
 				b.RemoveFromKillQueueOutOfRange(target.EntityID)
-				b.clearTarget()
+				b.clearTarget("out of range")
 				return
 			}
 			break
@@ -828,165 +1367,510 @@ func (b *Bot) tickCombat() {
 		return
 	}
 
-	// Auto-attack: pressiona tecla de ataque periodicamente (keyspam)
-	if autoAttack && sendKey != nil && attackKey != "" {
-		if time.Since(b.lastAttackTime) >= attackDelay {
-			sendKeySpam(sendKey, attackKey)
-			b.lastAttackTime = time.Now()
+	// Longe demais para atacar: anda até o target
+	if b.needsApproach() {
+		b.startApproach()
+		return
+	}
+
+	// Target confirmado mas o jogo não entrou em combate: mob inalcançável
+	if b.checkEngaged(*target) {
+		return
+	}
+
+	// Sem dano há muito tempo: evade, imune ou preso em algum lugar
+	b.mu.RLock()
+	hp := b.currentTarget.HP
+	b.mu.RUnlock()
+	if stuck, since := b.checkProgress(hp); stuck {
+		b.abandonTarget(*target, AbandonNoProgress,
+			fmt.Sprintf("HP %d unchanged for %s", hp, since.Round(100*time.Millisecond)))
+		return
+	}
+
+	b.mu.RLock()
+	rotation := b.rotation
+	b.mu.RUnlock()
+
+	// Ação anterior ainda esperando confirmação: nada novo até ela confirmar
+	// ou desistir (o retry é do tickActions)
+	if b.actionBusy(slotCombat) {
+		autoAttack = false
+	}
+
+	// Rotação: escolhe a habilidade pela prioridade/condições
+	if autoAttack && sendKey != nil && rotation != nil {
+		b.tickRotation(rotation, sendKey, target.EntityID)
+	} else if autoAttack && sendKey != nil && attackKey != "" {
+		// Auto-attack: pressiona a tecla de ataque periodicamente, repetindo
+		// até o player entrar em combate
+		if b.now().Sub(b.lastAttackTime) >= attackDelay &&
+			b.startAction(slotCombat, "attack", func() { sendKey(attackKey) }, b.combatConfirm(), nil) {
+			b.lastAttackTime = b.now()
+			b.mu.Lock()
+			b.engaged[target.EntityID] = true
+			b.mu.Unlock()
 		}
 	}
 
-	if b.config.OnCombatTick != nil {
-		b.mu.RLock()
-		t := *b.currentTarget
-		b.mu.RUnlock()
-		b.config.OnCombatTick(t)
+	b.mu.Lock()
+	if b.currentTarget != nil {
+		b.emitLocked(Event{Type: EventCombatTick, Target: *b.currentTarget})
 	}
+	b.mu.Unlock()
+}
+
+// tickRotation avalia a rotação contra o target atual e pressiona a habilidade escolhida.
+func (b *Bot) tickRotation(rotation *RotationEngine, sendKey func(string), targetID uint32) {
+	b.mu.RLock()
+	t := *b.currentTarget
+	ctx := RotationContext{
+		Target:          t,
+		PlayerHP:        -1,
+		PlayerMP:        -1,
+		HasPlayerBuff:   b.config.HasPlayerBuff,
+		HasPlayerDebuff: b.config.HasPlayerDebuff,
+		HasTargetDebuff: b.config.HasTargetDebuff,
+		IsSkillReady:    b.config.IsSkillReady,
+	}
+	getHP := b.config.GetPlayerHP
+	getMP := b.config.GetPlayerMP
+	b.mu.RUnlock()
+
+	if getHP != nil {
+		if cur, max := getHP(); max > 0 {
+			ctx.PlayerHP = float32(cur) / float32(max) * 100
+		}
+	}
+	if getMP != nil {
+		if cur, max := getMP(); max > 0 {
+			ctx.PlayerMP = float32(cur) / float32(max) * 100
+		}
+	}
+
+	ability, why := rotation.Next(ctx, b.now())
+	if ability == nil {
+		return
+	}
+
+	fmt.Printf("[BOT] Rotation: %s (%s) -> %s [%s]\n", ability.Name, ability.Key, t.Name, why)
+	// Repete até o SkillMonitor ver o cast (sem cast a rotação tenta de novo);
+	// sem skill_id a confirmação é a flag de combate
+	confirm := b.combatConfirm()
+	if ability.SkillID != 0 {
+		confirm = action.Confirm{Skill: ability.SkillID, TimeoutMs: int(castConfirmWindow / time.Millisecond)}
+	}
+	b.startAction(slotCombat, ability.Name, func() { sendKey(ability.Key) }, confirm, nil)
+	b.mu.Lock()
+	b.lastAttackTime = b.now()
+	b.engaged[targetID] = true
+	b.mu.Unlock()
 }
 
 // ====================
-// Internal helpers
+// Patrol
 // ====================
 
-func (b *Bot) setState(s BotState) {
+func (b *Bot) keyIO() keyIO {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return keyIO{down: b.config.KeyDown, up: b.config.KeyUp, tap: b.config.SendKey}
+}
+
+func (b *Bot) stopMoving() {
+	io := b.keyIO()
 	b.mu.Lock()
-	b.state = s
+	nav := b.navigator
 	b.mu.Unlock()
+	if nav != nil {
+		nav.Stop(io)
+	}
+}
+
+// tickPatrol procura mobs e, sem nenhum, anda até o próximo waypoint.
+// Ao achar target solta o movimento e passa para TARGETING.
+func (b *Bot) tickPatrol() {
+	b.mu.RLock()
+	nav := b.navigator
+	getPos := b.config.GetPlayerPos
+	b.mu.RUnlock()
+
+	if nav == nil || !nav.HasRoute() || getPos == nil {
+		b.stopMoving()
+		b.setState(StateIdle)
+		return
+	}
+
+	b.tickIdle()
+	if b.GetState() == StateTargeting {
+		b.stopMoving()
+		fmt.Printf("[BOT] Patrol paused at waypoint %d\n", nav.Index())
+		return
+	}
+	// tickIdle não muda o estado quando não acha nada
+	b.setState(StatePatrolling)
+
+	x, y, _, ok := getPos()
+	if !ok {
+		return
+	}
+	if msg := nav.Step(b.keyIO(), x, y, b.now()); msg != "" {
+		fmt.Printf("[BOT] Patrol: %s\n", msg)
+	}
 }
 
-func (b *Bot) clearTarget() {
+// ====================
+// Approach
+// ====================
+
+// needsApproach indica se o target atual está além da engage distance
+// (e se dá para andar: posição do player disponível).
+func (b *Bot) needsApproach() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	engage := b.config.EngageDistance
+	return engage > 0 && b.currentTarget != nil && b.config.GetPlayerPos != nil &&
+		b.currentTarget.Distance > engage
+}
+
+func (b *Bot) startApproach() {
 	b.mu.Lock()
-	b.currentTarget = nil
-	b.state = StateIdle
+	t := *b.currentTarget
+	b.setStateLocked(StateApproaching)
+	b.approachStart = b.now()
+	b.approachFromX, b.approachFromY = t.PosX, t.PosY
+	engage := b.config.EngageDistance
 	b.mu.Unlock()
+
+	fmt.Printf("[BOT] Approaching: %s (%.0fm > %.0fm)\n", t.Name, t.Distance, engage)
 }
 
-func (b *Bot) onMobDead(target EntityInfo) {
+// tickApproach anda até o target (recalculando o rumo a cada tick, já que
+// o mob pode estar andando) até entrar na engage distance ou dar timeout.
+func (b *Bot) tickApproach() {
 	b.mu.RLock()
-	autoLoot := b.config.AutoLoot
-	lootKey := b.config.LootKey
-	lootDelay := b.config.LootDelay
-	sendKey := b.config.SendKey
+	target := b.currentTarget
+	engage := b.config.EngageDistance
+	timeout := b.config.ApproachTimeout
+	getPos := b.config.GetPlayerPos
+	nav := b.approachNav
+	start := b.approachStart
 	b.mu.RUnlock()
 
-	// Remove da kill queue
-	b.RemoveFromKillQueue(target.EntityID)
+	if target == nil {
+		b.stopApproach()
+		b.setState(StateIdle)
+		return
+	}
+
+	// Atualiza o target pela entity list
+	var current *EntityInfo
+	for _, e := range b.GetEntityProvider().GetEntities() {
+		if e.EntityID == target.EntityID {
+			cpy := e
+			current = &cpy
+			break
+		}
+	}
+	if current == nil || current.HP == 0 || b.getCurrentTargetId() != target.EntityID {
+		b.stopApproach()
+		fmt.Printf("[BOT] Approach: target lost (%s)\n", target.Name)
+		if current != nil && current.HP == 0 {
+			b.onMobDead(*target)
+		} else {
+			b.clearTarget("lost during approach")
+		}
+		return
+	}
 
 	b.mu.Lock()
-	b.stats.MobsKilled++
-	b.currentTarget = nil
-	b.state = StateLooting
+	if b.currentTarget != nil {
+		b.currentTarget.HP = current.HP
+		b.currentTarget.Distance = current.Distance
+		b.currentTarget.PosX, b.currentTarget.PosY, b.currentTarget.PosZ = current.PosX, current.PosY, current.PosZ
+	}
 	b.mu.Unlock()
 
-	queueCount := b.GetKillQueueCount()
-	fmt.Printf("[BOT] Killed: %s [Queue remaining: %d]\n", target.Name, queueCount)
+	if maxRange := b.getEffectiveRange(); current.Distance > maxRange {
+		b.stopApproach()
+		fmt.Printf("[BOT] Approach: %s left range (%.0fm > %.0fm)\n", current.Name, current.Distance, maxRange)
+		b.RemoveFromKillQueueOutOfRange(current.EntityID)
+		b.clearTarget("out of range")
+		return
+	}
 
-	// Auto-loot: pressiona tecla de loot após delay (keyspam)
-	if autoLoot && sendKey != nil && lootKey != "" {
-		go func() {
-			time.Sleep(lootDelay)
-			sendKeySpam(sendKey, lootKey)
-			b.lastLootTime = time.Now()
-			fmt.Printf("[BOT] Looting: %s [x%d]\n", target.Name, KeySpamCount)
-
-			// Volta pro idle após loot
-			time.Sleep(200 * time.Millisecond)
-			b.setState(StateIdle)
-		}()
-	} else {
-		b.setState(StateIdle)
+	if b.outsideLeash(current.PosX, current.PosY) {
+		b.stopApproach()
+		fmt.Printf("[BOT] Approach: %s left leash\n", current.Name)
+		b.removeFromQueueWithReason(current.EntityID, "OUT OF LEASH")
+		b.clearTarget("out of leash")
+		return
 	}
 
-	if b.config.OnTargetDead != nil {
-		b.config.OnTargetDead(target)
+	if current.Distance <= engage {
+		b.stopApproach()
+		b.mu.Lock()
+		b.setStateLocked(StateCombat)
+		b.startEngageLocked()
+		b.mu.Unlock()
+		// Tempo andando não conta como "sem progresso"
+		b.resetProgress(current.HP)
+		fmt.Printf("[BOT] Approach: in range of %s (%.0fm) after %s\n",
+			current.Name, current.Distance, b.now().Sub(start).Round(100*time.Millisecond))
+		return
+	}
+
+	if timeout > 0 && b.now().Sub(start) >= timeout {
+		b.stopApproach()
+		moved := dist2D(current.PosX, current.PosY, b.approachFromX, b.approachFromY)
+		b.abandonTarget(*current, AbandonUnreachable,
+			fmt.Sprintf("approach timeout %s at %.0fm, mob moved %.0fm", timeout, current.Distance, moved))
+		return
 	}
+
+	if getPos == nil {
+		return
+	}
+	x, y, _, ok := getPos()
+	if !ok {
+		return
+	}
+	nav.StepToward(b.keyIO(), x, y, Waypoint{X: current.PosX, Y: current.PosY, Z: current.PosZ}, b.now())
+}
+
+func (b *Bot) stopApproach() {
+	io := b.keyIO()
+	b.mu.RLock()
+	nav := b.approachNav
+	b.mu.RUnlock()
+	nav.Stop(io)
 }
 
 // ====================
-// SetTarget (shellcode)
+// Leash
 // ====================
 
-func (b *Bot) setTarget(unitId uint32) error {
-	addr := b.x2game + OFFSET_SET_TARGET
+func copyWaypoint(wp *Waypoint) *Waypoint {
+	if wp == nil {
+		return nil
+	}
+	cpy := *wp
+	return &cpy
+}
 
-	shellcode := []byte{
-		0x6A, 0x00,                   // push 0 (flag)
-		0x68, 0x00, 0x00, 0x00, 0x00, // push unitId
-		0xB8, 0x00, 0x00, 0x00, 0x00, // mov eax, addr
-		0xFF, 0xD0,                   // call eax
-		0x83, 0xC4, 0x08,             // add esp, 8
-		0xC3,                         // ret
+// ensureAnchor define o anchor na posição atual se o leash estiver ligado
+// e ainda não houver anchor.
+func (b *Bot) ensureAnchor() {
+	b.mu.RLock()
+	need := b.config.LeashRadius > 0 && b.anchor == nil
+	getPos := b.config.GetPlayerPos
+	b.mu.RUnlock()
+	if !need || getPos == nil {
+		return
 	}
+	if x, y, z, ok := getPos(); ok {
+		b.mu.Lock()
+		if b.anchor == nil {
+			b.anchor = &Waypoint{X: x, Y: y, Z: z}
+		}
+		b.mu.Unlock()
+	}
+}
 
-	*(*uint32)(unsafe.Pointer(&shellcode[3])) = unitId
-	*(*uint32)(unsafe.Pointer(&shellcode[8])) = uint32(addr)
+// outsideLeash indica se o ponto (x, y) está fora do leash.
+func (b *Bot) outsideLeash(x, y float32) bool {
+	anchor, radius, ok := b.GetLeash()
+	return ok && dist2D(x, y, anchor.X, anchor.Y) > radius
+}
 
-	alloc, err := virtualAllocEx(b.handle, 256)
-	if err != nil {
-		return err
+// playerOutsideLeash indica se o player está fora do leash (e a distância
+// até o anchor).
+func (b *Bot) playerOutsideLeash() (bool, float32) {
+	b.ensureAnchor()
+	anchor, radius, ok := b.GetLeash()
+	b.mu.RLock()
+	getPos := b.config.GetPlayerPos
+	b.mu.RUnlock()
+	if !ok || getPos == nil {
+		return false, 0
 	}
-	defer virtualFreeEx(b.handle, alloc)
-
-	if err := writeProcessMemory(b.handle, alloc, shellcode); err != nil {
-		return err
+	x, y, _, posOK := getPos()
+	if !posOK {
+		return false, 0
 	}
+	d := dist2D(x, y, anchor.X, anchor.Y)
+	return d > radius, d
+}
 
-	th, err := createRemoteThread(b.handle, alloc)
-	if err != nil {
-		return err
+// startReturn entra em RETURNING se o player estiver fora do leash.
+func (b *Bot) startReturn() bool {
+	outside, d := b.playerOutsideLeash()
+	if !outside {
+		return false
 	}
-	defer windows.CloseHandle(th)
+	b.stopMoving()
+	b.setState(StateReturning)
+	_, radius, _ := b.GetLeash()
+	fmt.Printf("[BOT] Outside leash (%.0fm > %.0fm) - returning to anchor\n", d, radius)
+	return true
+}
 
-	windows.WaitForSingleObject(th, 5000)
-	return nil
+// startReturnToAnchor entra em RETURNING se o player estiver longe do
+// anchor (mais que ArriveRadius), com ou sem leash. Usado após reviver.
+func (b *Bot) startReturnToAnchor() bool {
+	anchor, ok := b.GetAnchor()
+	b.mu.RLock()
+	getPos := b.config.GetPlayerPos
+	arrive := b.config.Movement.withDefaults().ArriveRadius
+	b.mu.RUnlock()
+	if !ok || getPos == nil {
+		return false
+	}
+	x, y, _, posOK := getPos()
+	if !posOK {
+		return false
+	}
+	d := dist2D(x, y, anchor.X, anchor.Y)
+	if d <= arrive {
+		return false
+	}
+	b.stopMoving()
+	b.setState(StateReturning)
+	fmt.Printf("[BOT] Returning to anchor (%.0fm)\n", d)
+	return true
 }
 
-func (b *Bot) getCurrentTargetId() uint32 {
-	ptr := readU32(b.handle, b.x2game+PTR_ENEMY_TARGET_BASE)
-	if ptr == 0 {
-		return 0
+// tickReturn anda até o anchor; volta para IDLE ao chegar (ArriveRadius).
+// Não escolhe targets no caminho.
+func (b *Bot) tickReturn() {
+	anchor, ok := b.GetAnchor()
+	b.mu.RLock()
+	getPos := b.config.GetPlayerPos
+	arrive := b.config.Movement.withDefaults().ArriveRadius
+	nav := b.returnNav
+	b.mu.RUnlock()
+
+	if !ok || getPos == nil {
+		b.stopReturn()
+		b.setState(StateIdle)
+		return
+	}
+	x, y, _, posOK := getPos()
+	if !posOK {
+		return
 	}
-	return readU32(b.handle, uintptr(ptr)+OFF_TARGET_ID)
+	if dist2D(x, y, anchor.X, anchor.Y) <= arrive {
+		b.stopReturn()
+		b.setState(StateIdle)
+		fmt.Printf("[BOT] Back at anchor (%.0f, %.0f)\n", anchor.X, anchor.Y)
+		return
+	}
+	nav.StepToward(b.keyIO(), x, y, anchor, b.now())
+}
+
+func (b *Bot) stopReturn() {
+	io := b.keyIO()
+	b.mu.RLock()
+	nav := b.returnNav
+	b.mu.RUnlock()
+	nav.Stop(io)
 }
 
 // ====================
-// Memory helpers (minimal, só o que o bot precisa)
+// Internal helpers
 // ====================
 
-func readU32(handle windows.Handle, addr uintptr) uint32 {
-	var val uint32
-	var n uintptr
-	procReadProcessMem.Call(uintptr(handle), addr, uintptr(unsafe.Pointer(&val)), 4, uintptr(unsafe.Pointer(&n)))
-	return val
+func (b *Bot) setState(s BotState) {
+	b.mu.Lock()
+	b.setStateLocked(s)
+	b.mu.Unlock()
 }
 
-func virtualAllocEx(handle windows.Handle, size uint32) (uintptr, error) {
-	r, _, err := procVirtualAllocEx.Call(uintptr(handle), 0, uintptr(size), MEM_COMMIT|MEM_RESERVE, PAGE_EXECUTE_READWRITE)
-	if r == 0 {
-		return 0, err
+// clearTarget solta o target sem matar e volta para IDLE. reason vai no
+// evento target_lost.
+func (b *Bot) clearTarget(reason string) {
+	b.mu.Lock()
+	if b.currentTarget != nil {
+		b.emitLocked(Event{Type: EventTargetLost, Target: *b.currentTarget, Reason: reason})
 	}
-	return r, nil
+	b.currentTarget = nil
+	b.setStateLocked(StateIdle)
+	b.mu.Unlock()
 }
 
-func virtualFreeEx(handle windows.Handle, addr uintptr) {
-	procVirtualFreeEx.Call(uintptr(handle), addr, 0, MEM_RELEASE)
-}
+func (b *Bot) onMobDead(target EntityInfo) {
+	b.mu.RLock()
+	autoLoot := b.config.AutoLoot
+	lootKey := b.config.LootKey
+	lootDelay := b.config.LootDelay
+	sendKey := b.config.SendKey
+	b.mu.RUnlock()
 
-func writeProcessMemory(handle windows.Handle, addr uintptr, data []byte) error {
-	var n uintptr
-	r, _, err := procWriteProcessMem.Call(uintptr(handle), addr, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), uintptr(unsafe.Pointer(&n)))
-	if r == 0 {
-		return err
+	// Remove da kill queue
+	b.RemoveFromKillQueue(target.EntityID)
+
+	b.mu.Lock()
+	b.stats.MobsKilled++
+	ttk := time.Duration(0)
+	if b.ttkID == target.EntityID {
+		ttk = b.now().Sub(b.ttkStart)
 	}
-	return nil
+	b.ttkID = 0
+	b.session.recordKill(target.Name, ttk)
+	b.goalKillLocked(target.Name)
+	b.currentTarget = nil
+	b.emitLocked(Event{Type: EventTargetKilled, Target: target})
+	b.setStateLocked(StateLooting)
+	// Auto-loot: agenda a tecla de loot para depois do delay (enviada no tick)
+	if autoLoot && sendKey != nil && lootKey != "" {
+		b.pendingLoot = &pendingLoot{Target: target, At: b.now().Add(lootDelay)}
+	}
+	b.mu.Unlock()
+
+	queueCount := b.GetKillQueueCount()
+	fmt.Printf("[BOT] Killed: %s [Queue remaining: %d]\n", target.Name, queueCount)
 }
 
-func createRemoteThread(handle windows.Handle, addr uintptr) (windows.Handle, error) {
-	r, _, err := procCreateRemoteThread.Call(uintptr(handle), 0, 0, addr, 0, 0, 0)
-	if r == 0 {
-		return 0, err
+// tickPendingLoot dispara o loot agendado quando o delay expira.
+func (b *Bot) tickPendingLoot() {
+	b.mu.Lock()
+	pl := b.pendingLoot
+	// Loot só depois do combate acabar (adds ainda batendo)
+	_, busy := b.inflight[slotLoot]
+	if pl == nil || busy || b.now().Before(pl.At) || b.combatBlocksLocked(b.now()) {
+		b.mu.Unlock()
+		return
 	}
-	return windows.Handle(r), nil
+	b.pendingLoot = nil
+	lootKey := b.config.LootKey
+	sendKey := b.config.SendKey
+	b.mu.Unlock()
+
+	// Confirma com o corpo sumindo da entity list (ou o target mudando)
+	c := action.Confirm{Target: true, Gone: pl.Target.EntityID}
+	name := pl.Target.Name
+	b.startAction(slotLoot, "loot", func() { sendKey(lootKey) }, c, func(res action.Result) {
+		if !res.Confirmed {
+			fmt.Printf("[BOT] Looting: %s - não confirmado\n", name)
+		}
+	})
+	b.mu.Lock()
+	b.lastLootTime = b.now()
+	b.emitLocked(Event{Type: EventLoot, Target: pl.Target, Key: lootKey})
+	b.mu.Unlock()
+	fmt.Printf("[BOT] Looting: %s\n", name)
+}
+
+// ====================
+// Targeting helpers
+// ====================
+
+func (b *Bot) setTarget(unitId uint32) error {
+	return b.targeter.SetTarget(unitId)
+}
+
+func (b *Bot) getCurrentTargetId() uint32 {
+	return b.targeter.GetCurrentTargetID()
 }
 
 // ====================
@@ -1016,25 +1900,3 @@ func matchMode(partial bool) string {
 	}
 	return "exact"
 }
-
-// ====================
-// Key spam helper
-// ====================
-
-const (
-	KeySpamCount    = 5             // Número de vezes que cada tecla é pressionada
-	KeySpamInterval = 30 * time.Millisecond // Intervalo entre key presses
-)
-
-// sendKeySpam envia uma tecla múltiplas vezes para garantir registro
-func sendKeySpam(sendKey func(string), key string) {
-	if sendKey == nil || key == "" {
-		return
-	}
-	for i := 0; i < KeySpamCount; i++ {
-		sendKey(key)
-		if i < KeySpamCount-1 {
-			time.Sleep(KeySpamInterval)
-		}
-	}
-}
\ No newline at end of file
@@ -0,0 +1,163 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+// Step padrão dos testes (mesmo ScanInterval do bot real)
+const step = 20 * time.Millisecond
+
+func TestMobDies(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 10 // um press por ataque (sem flag de combate)
+	w.LootKey = bot.DefaultConfig().LootKey
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 200})
+
+	b := w.NewBot(w.Config("Wolf"))
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed (HP %d, state %s)", w.Mob(1).HP, b.GetState())
+	}
+	if got := b.GetStats().TargetsSet; got != 1 {
+		t.Fatalf("expected 1 target set, got %d", got)
+	}
+
+	// Loot só depois do LootDelay
+	if n := w.KeyCount("F"); n != 0 {
+		t.Fatalf("looted before loot delay (%d presses)", n)
+	}
+	w.Run(b, int(bot.DefaultConfig().LootDelay/step)+1, step)
+	if n := w.KeyCount("F"); n != 1 {
+		t.Fatalf("expected 1 loot press, got %d", n)
+	}
+	if n := len(w.GetEntities()); n != 0 {
+		t.Fatalf("corpse not looted (%d entities)", n)
+	}
+	if s := b.GetState(); s != bot.StateIdle {
+		t.Fatalf("expected IDLE after loot, got %s", s)
+	}
+}
+
+func TestLootRetries(t *testing.T) {
+	// Corpo continua na entity list: o loot é repetido no backoff até o
+	// timeout em vez de um keyspam fixo
+	w := sim.NewWorld()
+	w.Damage["1"] = 10
+	w.CorpseTime = 10 * time.Second
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 200})
+
+	b := w.NewBot(w.Config("Wolf"))
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed (HP %d, state %s)", w.Mob(1).HP, b.GetState())
+	}
+	w.Run(b, int((bot.DefaultConfig().LootDelay+3*time.Second)/step), step)
+	if n := w.KeyCount("F"); n < 2 || n > 5 {
+		t.Fatalf("expected loot retries until timeout, got %d presses", n)
+	}
+	if st := b.ActionStats(); len(st) != 1 || st[0].Name != "loot" || st[0].Runs != 1 || st[0].Confirmed != 0 {
+		t.Fatalf("unexpected loot stats %+v", st)
+	}
+}
+
+func TestMobDespawns(t *testing.T) {
+	w := sim.NewWorld()
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 200})
+
+	b := w.NewBot(w.Config("Wolf"))
+	if !w.RunUntil(b, 100, step, func() bool { return b.GetState() == bot.StateCombat }) {
+		t.Fatalf("never entered combat (state %s)", b.GetState())
+	}
+
+	w.Despawn(1)
+	w.Run(b, 5, step)
+
+	if tgt := b.GetCurrentTarget(); tgt != nil {
+		t.Fatalf("target still set after despawn: %s", tgt.Name)
+	}
+	w.Run(b, 5, step)
+	if n := b.GetKillQueueCount(); n != 0 {
+		t.Fatalf("despawned mob still in kill queue (%d)", n)
+	}
+	if s := b.GetState(); s != bot.StateIdle {
+		t.Fatalf("expected IDLE, got %s", s)
+	}
+}
+
+func TestMobLeavesRange(t *testing.T) {
+	w := sim.NewWorld()
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 200})
+
+	cfg := w.Config("Wolf")
+	cfg.AutoAttack = false
+	b := w.NewBot(cfg)
+	if !w.RunUntil(b, 100, step, func() bool { return b.GetState() == bot.StateCombat }) {
+		t.Fatalf("never entered combat (state %s)", b.GetState())
+	}
+
+	w.MoveMob(1, cfg.MaxRange+20, 0, 0)
+	w.Run(b, 3, step)
+
+	if tgt := b.GetCurrentTarget(); tgt != nil {
+		t.Fatalf("target kept out of range: %s (%.0fm)", tgt.Name, tgt.Distance)
+	}
+	w.Run(b, 10, step)
+	if tgt := b.GetCurrentTarget(); tgt != nil {
+		t.Fatalf("re-targeted mob out of range: %s", tgt.Name)
+	}
+	if k := b.GetStats().MobsKilled; k != 0 {
+		t.Fatalf("out of range counted as kill (%d)", k)
+	}
+}
+
+func TestTargetMismatch(t *testing.T) {
+	w := sim.NewWorld()
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 200})
+
+	reject := true
+	w.RejectTarget = func(id uint32) bool { return reject }
+
+	// Sem abandono: aqui só interessa o retry (abandono em scenarioStuck)
+	cfg := w.Config("Wolf")
+	cfg.MaxTargetMismatches = 0
+	b := w.NewBot(cfg)
+	w.Run(b, 50, step)
+	if s := b.GetState(); s == bot.StateCombat {
+		t.Fatalf("entered combat without client target")
+	}
+	if n := b.GetStats().TargetsSet; n != 0 {
+		t.Fatalf("counted %d targets while client rejected", n)
+	}
+
+	// Client volta a aceitar: bot deve tentar de novo e pegar o mesmo mob
+	reject = false
+	if !w.RunUntil(b, 100, step, func() bool { return b.GetState() == bot.StateCombat }) {
+		t.Fatalf("never retried after mismatch (state %s)", b.GetState())
+	}
+	if tgt := b.GetCurrentTarget(); tgt == nil || tgt.EntityID != 1 {
+		t.Fatalf("wrong target after retry")
+	}
+}
+
+func TestRespawnFIFO(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 40
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 100, RespawnAfter: 10 * time.Second})
+	w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 20, MaxHP: 100, RespawnAfter: 10 * time.Second})
+	w.AddMob(sim.Mob{ID: 3, Name: "Bear", X: 5, MaxHP: 100})
+
+	var order []uint32
+	b := w.NewBot(w.Config("Wolf"))
+	b.Events().Subscribe(func(e bot.Event) { order = append(order, e.Target.EntityID) }, bot.EventTargetAcquired)
+
+	if !w.RunUntil(b, 5000, step, func() bool { return b.GetStats().MobsKilled >= 4 }) {
+		t.Fatalf("expected 4 kills with respawn, got %d", b.GetStats().MobsKilled)
+	}
+	if w.Mob(3).HP != w.Mob(3).MaxHP {
+		t.Fatalf("attacked mob not in list (Bear)")
+	}
+	if len(order) < 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("expected FIFO order [1 2 ...], got %v", order)
+	}
+}
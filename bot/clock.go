@@ -0,0 +1,40 @@
+package bot
+
+import "time"
+
+// ====================
+// Clock
+// ====================
+
+// Clock abstrai o tempo do bot. Em produção é o relógio do sistema;
+// a simulação (package sim) injeta um relógio virtual determinístico.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// SystemClock é o relógio real (padrão quando Config.Clock é nil).
+var SystemClock Clock = systemClock{}
+
+func (b *Bot) clock() Clock {
+	if b.config.Clock != nil {
+		return b.config.Clock
+	}
+	return SystemClock
+}
+
+func (b *Bot) now() time.Time {
+	return b.clock().Now()
+}
+
+func (b *Bot) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	b.clock().Sleep(d)
+}
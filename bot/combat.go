@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ====================
+// Combat state
+// ====================
+
+// CombatConfig controla o uso da flag de combate do player
+// (config.OFF_COMBAT_RAW, Config.IsPlayerInCombat). Sem provider nada muda.
+type CombatConfig struct {
+	// Sem target e tomando dano em combate: ataca o NPC mais perto até
+	// AggroRadius metros (0 = desativado)
+	AggroRadius float32 `json:"aggro_radius"`
+	// Não há flag de hostilidade na memória: só mobs da mob list ou de
+	// AggroNames contam como atacáveis (guarda/NPC de cidade nunca)
+	AggroNames []string `json:"aggro_names,omitempty"`
+	// Abandona o target se o combate não começar até ConfirmMs depois do
+	// target confirmado (0 = não confirma)
+	ConfirmMs int `json:"confirm_ms"`
+	// Loot e descanso esperam o fim do combate. Flag presa por mais que
+	// MaxWaitMs é ignorada (0 = espera sempre)
+	WaitForEnd bool `json:"wait_for_end"`
+	MaxWaitMs  int  `json:"max_wait_ms"`
+}
+
+func DefaultCombatConfig() CombatConfig {
+	return CombatConfig{
+		AggroRadius: 10,
+		ConfirmMs:   3000,
+		WaitForEnd:  true,
+		MaxWaitMs:   10000,
+	}
+}
+
+// Motivo de abandono quando o combate não começa depois do target
+const AbandonNotEngaged = "not engaged"
+
+// Janela em que uma queda de HP do player conta como "sendo atacado"
+const aggroDamageWindow = 2 * time.Second
+
+// SetCombat troca a config de combate em runtime.
+func (b *Bot) SetCombat(cc CombatConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Combat = cc
+}
+
+// InCombat retorna a flag de combate do player (ok false = sem provider)
+// e desde quando ela está no valor atual.
+func (b *Bot) InCombat() (inCombat bool, since time.Time, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.inCombat, b.combatChangedAt, b.config.IsPlayerInCombat != nil
+}
+
+// sampleCombat lê a flag de combate e emite entrada/saída.
+func (b *Bot) sampleCombat() {
+	b.mu.RLock()
+	fn := b.config.IsPlayerInCombat
+	b.mu.RUnlock()
+	if fn == nil {
+		return
+	}
+	combat := fn()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if combat && b.currentTarget != nil && b.state == StateCombat {
+		b.engageConfirmed = true
+	}
+	if combat == b.inCombat {
+		return
+	}
+	b.inCombat = combat
+	b.combatChangedAt = b.now()
+	e := Event{Type: EventCombatLeave}
+	if combat {
+		e.Type = EventCombatEnter
+	}
+	if b.currentTarget != nil {
+		e.Target = *b.currentTarget
+	}
+	b.emitLocked(e)
+}
+
+// combatBlocksLocked indica se loot/descanso devem esperar o combate
+// acabar. Chamar com b.mu travado.
+func (b *Bot) combatBlocksLocked(now time.Time) bool {
+	cc := b.config.Combat
+	if b.config.IsPlayerInCombat == nil || !cc.WaitForEnd || !b.inCombat {
+		return false
+	}
+	return cc.MaxWaitMs <= 0 || now.Sub(b.combatChangedAt) < time.Duration(cc.MaxWaitMs)*time.Millisecond
+}
+
+// tookDamageLocked indica se o HP do player caiu nos últimos window.
+func (b *Bot) tookDamageLocked(now time.Time, window time.Duration) bool {
+	for i := len(b.hpTrace) - 1; i > 0; i-- {
+		cur, prev := b.hpTrace[i], b.hpTrace[i-1]
+		if now.Sub(cur.At) > window {
+			break
+		}
+		if cur.HP < prev.HP {
+			return true
+		}
+	}
+	return false
+}
+
+// findAggressor escolhe o add que está atacando o player: em combate, sem
+// target e tomando dano, o NPC atacável vivo mais perto até AggroRadius
+// (mobs da kill queue primeiro). Leash, blacklists e mob rules valem como
+// na kill queue.
+func (b *Bot) findAggressor(entities []EntityInfo) *EntityInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	radius := b.config.Combat.AggroRadius
+	if b.config.IsPlayerInCombat == nil || radius <= 0 || !b.inCombat || b.currentTarget != nil {
+		return nil
+	}
+	if !b.tookDamageLocked(now, aggroDamageWindow) {
+		return nil
+	}
+
+	var candidates []EntityInfo
+	for _, e := range entities {
+		if !e.IsNPC || e.IsMate || e.HP == 0 || e.Distance > radius || !b.attackableLocked(e) {
+			continue
+		}
+		if b.config.LeashRadius > 0 && b.anchor != nil &&
+			dist2D(e.PosX, e.PosY, b.anchor.X, b.anchor.Y) > b.config.LeashRadius {
+			continue
+		}
+		if b.isBlacklisted(e.EntityID, now) || b.checkMobRules(e, entities) != "" {
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		_, qi := b.killQueue[candidates[i].EntityID]
+		_, qj := b.killQueue[candidates[j].EntityID]
+		if qi != qj {
+			return qi
+		}
+		return candidates[i].Distance < candidates[j].Distance
+	})
+	return &candidates[0]
+}
+
+// attackableLocked indica se o NPC pode virar aggressor: mob list ou
+// Combat.AggroNames. Chamar com b.mu travado.
+func (b *Bot) attackableLocked(e EntityInfo) bool {
+	partial := b.config.PartialMatch
+	return matchName(e.Name, b.config.MobNames, partial) || matchName(e.Name, b.config.Combat.AggroNames, partial)
+}
+
+// takeAggressor passa o add na frente da fila e vai para TARGETING.
+func (b *Bot) takeAggressor(entities []EntityInfo) bool {
+	aggressor := b.findAggressor(entities)
+	if aggressor == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	id := aggressor.EntityID
+	if _, queued := b.killQueue[id]; queued {
+		for i, qid := range b.killQueueOrder {
+			if qid == id {
+				b.killQueueOrder = append(b.killQueueOrder[:i], b.killQueueOrder[i+1:]...)
+				break
+			}
+		}
+	} else {
+		b.emitLocked(Event{Type: EventQueueAdd, Target: *aggressor, Reason: "aggressor", Queue: len(b.killQueueOrder) + 1})
+	}
+	b.killQueue[id] = *aggressor
+	b.killQueueOrder = append([]uint32{id}, b.killQueueOrder...)
+	b.currentTarget = aggressor
+	b.setStateLocked(StateTargeting)
+	b.mu.Unlock()
+
+	fmt.Printf("[BOT] Aggressor: %s (ID:%d HP:%d Dist:%.0fm)\n",
+		aggressor.Name, aggressor.EntityID, aggressor.HP, aggressor.Distance)
+	return true
+}
+
+// startEngageLocked começa a janela de confirmação do engage. Chamar com
+// b.mu travado ao entrar em COMBAT.
+func (b *Bot) startEngageLocked() {
+	b.engageStart = b.now()
+	b.engageConfirmed = false
+}
+
+// checkEngaged abandona o target se o combate não começou até ConfirmMs
+// depois do target confirmado. Retorna true se abandonou.
+func (b *Bot) checkEngaged(target EntityInfo) bool {
+	b.mu.RLock()
+	confirm := time.Duration(b.config.Combat.ConfirmMs) * time.Millisecond
+	enabled := b.config.IsPlayerInCombat != nil && confirm > 0
+	confirmed := b.engageConfirmed
+	since := b.now().Sub(b.engageStart)
+	b.mu.RUnlock()
+
+	if !enabled || confirmed || since < confirm {
+		return false
+	}
+	b.abandonTarget(target, AbandonNotEngaged, fmt.Sprintf("no combat flag after %s", since.Round(100*time.Millisecond)))
+	return true
+}
@@ -0,0 +1,108 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCombat(t *testing.T) {
+	newWorld := func(mobs ...sim.Mob) (*sim.World, *bot.Bot, *[]bot.Event) {
+		w := sim.NewWorld()
+		w.Damage["1"] = 50
+		w.CorpseTime = 10 * time.Second
+		for _, m := range mobs {
+			w.AddMob(m)
+		}
+		cfg := w.Config("Wolf")
+		cfg.IsPlayerInCombat = w.PlayerInCombat
+		b := w.NewBot(cfg)
+		events := &[]bot.Event{}
+		b.Events().Subscribe(func(e bot.Event) { *events = append(*events, e) },
+			bot.EventCombatEnter, bot.EventCombatLeave, bot.EventQueueAdd, bot.EventLoot, bot.EventTargetLost)
+		return w, b, events
+	}
+	find := func(events []bot.Event, typ bot.EventType) *bot.Event {
+		for i := range events {
+			if events[i].Type == typ {
+				return &events[i]
+			}
+		}
+		return nil
+	}
+
+	// Sem hostilidade na memória: NPC fora da mob list e de aggro_names
+	// (guarda de cidade, add desconhecido) nunca vira aggressor
+	w, b, events := newWorld(sim.Mob{ID: 1, Name: "Boar", X: 5, MaxHP: 100, Aggro: true},
+		sim.Mob{ID: 2, Name: "Town Guard", X: 3, MaxHP: 1000})
+	w.Run(b, int(3*time.Second/step), step)
+	if e := find(*events, bot.EventQueueAdd); e != nil {
+		t.Fatalf("attacked non-attackable NPC %+v", e.Target)
+	}
+
+	// aggro_names com mob rule never: continua de fora
+	w, b, events = newWorld(sim.Mob{ID: 1, Name: "Boar", X: 5, MaxHP: 100, Aggro: true})
+	cc := bot.DefaultCombatConfig()
+	cc.AggroNames = []string{"Boar"}
+	b.SetCombat(cc)
+	b.SetMobRules([]bot.MobRule{{Name: "Boar", Never: true}})
+	w.Run(b, int(3*time.Second/step), step)
+	if e := find(*events, bot.EventQueueAdd); e != nil {
+		t.Fatalf("aggressor ignored mob rule never: %+v", e.Target)
+	}
+
+	// Idle sem mobs da lista: o add de aggro_names que está batendo vira o
+	// target e o loot espera a flag de combate desligar
+	w, b, events = newWorld(sim.Mob{ID: 1, Name: "Boar", X: 5, MaxHP: 100, Aggro: true})
+	b.SetCombat(cc)
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("aggressor not killed (state %s, HP %d)", b.GetState(), w.PlayerHP)
+	}
+	if e := find(*events, bot.EventQueueAdd); e == nil || e.Reason != "aggressor" || e.Target.EntityID != 1 {
+		t.Fatalf("expected queue_add aggressor for mob 1, got %+v", e)
+	}
+	if find(*events, bot.EventCombatEnter) == nil {
+		t.Fatalf("missing combat_enter")
+	}
+	if inCombat, _, _ := b.InCombat(); !inCombat || find(*events, bot.EventLoot) != nil {
+		t.Fatalf("looted while still in combat")
+	}
+	if !w.RunUntil(b, 500, step, func() bool { return find(*events, bot.EventLoot) != nil }) {
+		t.Fatalf("never looted after combat ended")
+	}
+	leave, loot := find(*events, bot.EventCombatLeave), find(*events, bot.EventLoot)
+	if leave == nil || loot.At.Before(leave.At) {
+		t.Fatalf("loot before combat_leave (%+v)", leave)
+	}
+
+	// Target confirmado mas o combate nunca começa: abandona em ~ConfirmMs,
+	// bem antes do NoProgressTimeout
+	w, b, events = newWorld(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100, Immune: true})
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().Abandoned == 1 }) {
+		t.Fatalf("unreachable mob never abandoned (state %s)", b.GetState())
+	}
+	if e := find(*events, bot.EventTargetLost); e == nil || !strings.HasSuffix(e.Reason, bot.AbandonNotEngaged) {
+		t.Fatalf("expected target_lost %q, got %+v", bot.AbandonNotEngaged, e)
+	}
+	if elapsed := w.Clock.Now().Sub(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)); elapsed > 4*time.Second {
+		t.Fatalf("abandoned after %s, want ~3s", elapsed)
+	}
+
+	// Descanso não começa em combate (ataque automático desligado)
+	w, b, _ = newWorld(sim.Mob{ID: 1, Name: "Boar", X: 5, MaxHP: 100, Aggro: true})
+	w.PlayerHP = 300
+	b.SetCombat(bot.CombatConfig{ConfirmMs: 3000, WaitForEnd: true, MaxWaitMs: 10000})
+	rc := bot.DefaultRestConfig()
+	rc.Enabled, rc.StartHP, rc.UntilHP = true, 50, 90
+	b.SetRest(rc)
+	w.Run(b, int(5*time.Second/step), step)
+	if s := b.GetState(); s == bot.StateResting {
+		t.Fatalf("rested while in combat")
+	}
+	w.Despawn(1)
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetState() == bot.StateResting }) {
+		t.Fatalf("did not rest after combat ended (state %s)", b.GetState())
+	}
+}
@@ -1,9 +1,11 @@
 package bot
 
 import (
+	"archefriend/action"
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 // FileConfig é a config carregável de arquivo JSON
@@ -15,6 +17,11 @@ type FileConfig struct {
 	ScanIntervalMs int    `json:"scan_interval_ms"`
 	TargetDelayMs  int    `json:"target_delay_ms"`
 
+	// Target prioritization (fifo, nearest, lowest_hp, priority, cluster, damaged_first)
+	Strategy      string         `json:"strategy"`
+	Priorities    map[string]int `json:"priorities,omitempty"`     // pesos por nome (strategy "priority")
+	ClusterRadius float32        `json:"cluster_radius,omitempty"` // raio da strategy "cluster"
+
 	// Keys para ações automáticas
 	AttackKey    string `json:"attack_key"`    // Ex: "1", "F", "SPACE"
 	LootKey      string `json:"loot_key"`      // Ex: "F", "E"
@@ -23,6 +30,49 @@ type FileConfig struct {
 	AutoAttack   bool   `json:"auto_attack"`   // Atacar automaticamente
 	AutoLoot     bool   `json:"auto_loot"`     // Lootar automaticamente
 
+	// Rotação de skills (opcional, substitui attack_key em combate)
+	Rotation *Rotation `json:"rotation,omitempty"`
+
+	// Arquivo JSON com a behavior tree (vazio = comportamento padrão)
+	BehaviorTree string `json:"behavior_tree,omitempty"`
+
+	// Patrulha: rotas nomeadas (gravadas via hotkey) e a rota ativa
+	Route    string           `json:"route"` // vazio = sem patrulha
+	Routes   map[string]Route `json:"routes,omitempty"`
+	Movement MovementConfig   `json:"movement"`
+
+	// Approach: anda até o target além desta distância (0 = desativado)
+	EngageDistance    float32 `json:"engage_distance"`
+	ApproachTimeoutMs int     `json:"approach_timeout_ms"`
+
+	// Regras por nome (never, only_if_alone, max_level, max_hp,
+	// min_player_distance) e blacklist permanente editada pela janela do bot
+	MobRules  []MobRule    `json:"mob_rules,omitempty"`
+	Blacklist MobBlacklist `json:"blacklist"`
+
+	// Stuck/evade: abandona targets sem dano ou que não dá para selecionar
+	// e ignora o mob por blacklist_time_ms (0 = desativa cada checagem)
+	NoProgressTimeoutMs int `json:"no_progress_timeout_ms"`
+	MaxTargetMismatches int `json:"max_target_mismatches"`
+	BlacklistTimeMs     int `json:"blacklist_time_ms"`
+
+	// Leash: mobs além deste raio do anchor são ignorados e o bot volta ao
+	// anchor depois das lutas (0 = desativado). Sem anchor = posição ao iniciar.
+	LeashRadius float32   `json:"leash_radius"`
+	Anchor      *Waypoint `json:"anchor,omitempty"`
+
+	// Descanso entre pulls (HP/MP), tecla de sentar/comida opcional
+	Rest RestConfig `json:"rest"`
+
+	// Morte do player: stop, alert ou resurrect (sequência + volta ao anchor)
+	Death DeathConfig `json:"death"`
+
+	// Flag de combate: ataca quem bate, confirma engage, loot/descanso esperam
+	Combat CombatConfig `json:"combat"`
+
+	// Sessões salvas para o bot_report ("" = não salva)
+	SessionDir string `json:"session_dir"`
+
 	// Potion settings
 	HPPotionKey       string  `json:"hp_potion_key"`       // Ex: "5", "H"
 	HPPotionThreshold float32 `json:"hp_potion_threshold"` // % HP para usar (ex: 50.0 = 50%)
@@ -32,8 +82,68 @@ type FileConfig struct {
 	MPPotionEnabled   bool    `json:"mp_potion_enabled"`
 	PotionCooldownMs  int     `json:"potion_cooldown_ms"`  // Cooldown em ms (21000 = 21s)
 
+	// Regras de threshold (substituem as potions acima quando definidas)
+	// e intervalo do modo guardian (ver thresholds.go/guardian.go)
+	Thresholds         []ThresholdRule `json:"thresholds,omitempty"`
+	GuardianIntervalMs int             `json:"guardian_interval_ms,omitempty"`
+
+	// Retry/backoff das ações com "confirm" (thresholds, skill_id da rotação)
+	Actions action.Policy `json:"actions"`
+
+	// Goals: kill N, rodar N minutos ou parar num horário; ao completar
+	// para, troca de preset ou envia teclas. Progresso em goal_state_file
+	Goals         []Goal `json:"goals,omitempty"`
+	GoalStateFile string `json:"goal_state_file"`
+
 	// Presets de mob lists (troca rápida via hotkey)
-	Presets map[string][]string `json:"presets"`
+	Presets map[string]Preset `json:"presets"`
+}
+
+// Preset é uma mob list com estratégia opcional. No JSON aceita tanto o
+// formato antigo (lista de nomes) quanto um objeto:
+//
+//	"preset1": ["Young Flamingo"]
+//	"preset2": {"mobs": ["Imp", "Spider"], "strategy": "priority", "priorities": {"Imp": 10}}
+type Preset struct {
+	MobNames      []string       `json:"mobs"`
+	Strategy      string         `json:"strategy,omitempty"` // vazio = mantém a estratégia global
+	Priorities    map[string]int `json:"priorities,omitempty"`
+	ClusterRadius float32        `json:"cluster_radius,omitempty"`
+	Rotation      *Rotation      `json:"rotation,omitempty"` // nil = rotação global
+	Route         string         `json:"route,omitempty"`    // vazio = rota global
+}
+
+// presetObject evita recursão no (Un)MarshalJSON
+type presetObject Preset
+
+func (p *Preset) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err == nil {
+		*p = Preset{MobNames: names}
+		return nil
+	}
+
+	var obj presetObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("preset must be a list of mob names or an object: %v", err)
+	}
+	*p = Preset(obj)
+	if p.MobNames == nil {
+		p.MobNames = []string{}
+	}
+	return nil
+}
+
+func (p Preset) MarshalJSON() ([]byte, error) {
+	// Sem estratégia própria salva no formato antigo (lista)
+	if p.Strategy == "" && len(p.Priorities) == 0 && p.ClusterRadius == 0 && p.Rotation == nil && p.Route == "" {
+		names := p.MobNames
+		if names == nil {
+			names = []string{}
+		}
+		return json.Marshal(names)
+	}
+	return json.Marshal(presetObject(p))
 }
 
 func DefaultFileConfig() FileConfig {
@@ -44,6 +154,20 @@ func DefaultFileConfig() FileConfig {
 		PartialMatch:   false,
 		ScanIntervalMs: 20,  // Faster: ~50 scans/sec (matches ESP cache rate)
 		TargetDelayMs:  50,  // Faster: reduced from 150ms
+		Strategy:       StrategyFIFO,
+		Movement:       DefaultMovementConfig(),
+		Rest:           DefaultRestConfig(),
+		Death:          DefaultDeathConfig(),
+		Combat:         DefaultCombatConfig(),
+		Actions:        action.DefaultPolicy(),
+		SessionDir:     "sessions",
+		GoalStateFile:  "bot_goals_state.json",
+		Blacklist:      MobBlacklist{Names: []string{}, IDs: []uint32{}},
+		EngageDistance:    0,     // desativado: ataca de onde estiver
+		ApproachTimeoutMs: 10000, // desiste do mob após 10s tentando chegar
+		NoProgressTimeoutMs: 10000, // abandona mob sem perder HP por 10s
+		MaxTargetMismatches: 3,     // abandona após 3 SetTarget falhos seguidos
+		BlacklistTimeMs:     60000, // ignora mob abandonado por 1min
 		AttackKey:      "1",    // Tecla padrão de ataque
 		LootKey:        "F",    // Tecla padrão de loot
 		AttackDelay:    500,    // 500ms entre ataques
@@ -58,10 +182,10 @@ func DefaultFileConfig() FileConfig {
 		MPPotionThreshold: 30.0,    // Usar quando MP < 30%
 		MPPotionEnabled:   false,   // Desabilitado por padrão
 		PotionCooldownMs:  21000,   // 21 segundos de cooldown
-		Presets: map[string][]string{
-			"preset1": {"Young Flamingo"},
-			"preset2": {"Wandering Imp", "Forest Spider"},
-			"preset3": {},
+		Presets: map[string]Preset{
+			"preset1": {MobNames: []string{"Young Flamingo"}},
+			"preset2": {MobNames: []string{"Wandering Imp", "Forest Spider"}},
+			"preset3": {MobNames: []string{}},
 		},
 	}
 }
@@ -101,22 +225,83 @@ func (b *Bot) ApplyFileConfig(fc *FileConfig) {
 	b.SetMobNames(fc.MobNames)
 	b.SetMaxRange(fc.MaxRange)
 	b.SetPartialMatch(fc.PartialMatch)
+	if err := b.SetStrategy(fc.Strategy, fc.Priorities, fc.ClusterRadius); err != nil {
+		fmt.Printf("[BOT] %v - mantendo %s\n", err, b.GetStrategy())
+	}
 	b.SetAttackKey(fc.AttackKey)
 	b.SetLootKey(fc.LootKey)
 	b.SetAutoAttack(fc.AutoAttack)
 	b.SetAutoLoot(fc.AutoLoot)
+	if err := b.SetRotation(fc.Rotation); err != nil {
+		fmt.Printf("[BOT] Rotation: %v - usando attack key\n", err)
+		b.SetRotation(nil)
+	}
 	if fc.AttackDelay > 0 {
 		b.SetAttackDelay(fc.AttackDelay)
 	}
 	if fc.LootDelay > 0 {
 		b.SetLootDelay(fc.LootDelay)
 	}
+	b.SetRoute(fc.ActiveRoute(""), fc.Movement)
+	b.SetApproach(fc.EngageDistance, time.Duration(fc.ApproachTimeoutMs)*time.Millisecond)
+	b.SetMobRules(fc.MobRules)
+	b.SetMobBlacklist(fc.Blacklist)
+	b.SetStuckDetection(time.Duration(fc.NoProgressTimeoutMs)*time.Millisecond,
+		fc.MaxTargetMismatches, time.Duration(fc.BlacklistTimeMs)*time.Millisecond)
+	b.SetLeash(fc.Anchor, fc.LeashRadius)
+	b.SetRest(fc.Rest)
+	b.SetDeath(fc.Death)
+	b.SetCombat(fc.Combat)
+	b.SetSessionDir(fc.SessionDir)
+	if err := b.LoadBehaviorTreeFile(fc.BehaviorTree); err != nil {
+		fmt.Printf("[BOT] Behavior tree: %v - mantendo a atual\n", err)
+	}
 	// Potion settings
 	b.SetHPPotion(fc.HPPotionKey, fc.HPPotionThreshold, fc.HPPotionEnabled)
 	b.SetMPPotion(fc.MPPotionKey, fc.MPPotionThreshold, fc.MPPotionEnabled)
 	if fc.PotionCooldownMs > 0 {
 		b.SetPotionCooldown(fc.PotionCooldownMs)
 	}
+	if err := b.SetThresholds(fc.Thresholds); err != nil {
+		fmt.Printf("[BOT] Thresholds: %v - usando potions da config\n", err)
+		b.SetThresholds(nil)
+	}
+	b.SetActionPolicy(fc.Actions)
+	if err := b.SetGoals(fc.Goals, fc.GoalStateFile); err != nil {
+		fmt.Printf("[BOT] Goals: %v - ignorando goals\n", err)
+		b.SetGoals(nil, fc.GoalStateFile)
+	}
+}
+
+// ThresholdRules retorna as regras do arquivo, ou as potions de HP/MP
+// convertidas quando não há "thresholds" (usado pelo guardian).
+func (fc *FileConfig) ThresholdRules() []ThresholdRule {
+	if len(fc.Thresholds) > 0 {
+		return fc.Thresholds
+	}
+	cfg := DefaultConfig()
+	cfg.HPPotionKey, cfg.HPPotionThreshold, cfg.HPPotionEnabled = fc.HPPotionKey, fc.HPPotionThreshold, fc.HPPotionEnabled
+	cfg.MPPotionKey, cfg.MPPotionThreshold, cfg.MPPotionEnabled = fc.MPPotionKey, fc.MPPotionThreshold, fc.MPPotionEnabled
+	if fc.PotionCooldownMs > 0 {
+		cfg.PotionCooldown = time.Duration(fc.PotionCooldownMs) * time.Millisecond
+	}
+	return LegacyPotionRules(cfg)
+}
+
+// LoadBehaviorTreeFile carrega a árvore do arquivo ("" = árvore padrão)
+func (b *Bot) LoadBehaviorTreeFile(filename string) error {
+	if filename == "" {
+		return b.SetBehaviorTree(nil)
+	}
+	spec, err := LoadBehaviorTree(filename)
+	if err != nil {
+		return err
+	}
+	if err := b.SetBehaviorTree(spec); err != nil {
+		return err
+	}
+	fmt.Printf("[BOT] Behavior tree loaded from %s\n", filename)
+	return nil
 }
 
 // LoadConfig carrega e aplica config do arquivo
@@ -125,11 +310,20 @@ func (b *Bot) LoadConfig(filename string) error {
 	if err != nil {
 		return err
 	}
+	b.SetConfigFile(filename)
 	b.ApplyFileConfig(fc)
 	fmt.Printf("[BOT] Config loaded from %s\n", filename)
 	return nil
 }
 
+// SetConfigFile define o bot_config.json usado pelos goals que trocam de
+// preset (perfil do personagem).
+func (b *Bot) SetConfigFile(filename string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.ConfigFile = filename
+}
+
 // LoadPreset carrega um preset de mob names do config
 func (b *Bot) LoadPreset(filename string, presetName string) error {
 	fc, err := LoadFileConfig(filename)
@@ -137,12 +331,75 @@ func (b *Bot) LoadPreset(filename string, presetName string) error {
 		return err
 	}
 
-	names, ok := fc.Presets[presetName]
+	preset, ok := fc.Presets[presetName]
 	if !ok {
 		return fmt.Errorf("preset '%s' not found", presetName)
 	}
 
-	b.SetMobNames(names)
-	fmt.Printf("[BOT] Preset '%s' loaded: %v\n", presetName, names)
+	if err := b.ApplyPreset(fc, preset); err != nil {
+		return err
+	}
+	b.SetPresetName(presetName)
+	fmt.Printf("[BOT] Preset '%s' loaded: %v\n", presetName, preset.MobNames)
+	return nil
+}
+
+// ApplyPreset aplica a mob list do preset, a estratégia e a rotação dele.
+// Presets sem estratégia/rotação própria voltam para as globais do FileConfig.
+func (b *Bot) ApplyPreset(fc *FileConfig, preset Preset) error {
+	rotation := preset.Rotation
+	if rotation == nil && fc != nil {
+		rotation = fc.Rotation
+	}
+	if err := b.SetRotation(rotation); err != nil {
+		return err
+	}
+	if fc != nil {
+		b.SetRoute(fc.ActiveRoute(preset.Route), fc.Movement)
+	}
+
+	strategy, priorities, radius := preset.Strategy, preset.Priorities, preset.ClusterRadius
+	if strategy == "" && fc != nil {
+		strategy, radius = fc.Strategy, fc.ClusterRadius
+		if priorities == nil {
+			priorities = fc.Priorities
+		}
+	}
+	if err := b.SetStrategy(strategy, priorities, radius); err != nil {
+		return err
+	}
+	b.SetMobNames(preset.MobNames)
 	return nil
-}
\ No newline at end of file
+}
+// ActiveRoute retorna a rota pelo nome (override) ou a rota global.
+// nil quando não há rota ou ela não existe.
+func (fc *FileConfig) ActiveRoute(override string) *Route {
+	name := override
+	if name == "" {
+		name = fc.Route
+	}
+	if name == "" {
+		return nil
+	}
+	r, ok := fc.Routes[name]
+	if !ok {
+		fmt.Printf("[BOT] Route '%s' não encontrada\n", name)
+		return nil
+	}
+	return &r
+}
+
+// AddWaypoint adiciona um waypoint na rota ativa (cria "default" se não houver).
+// Retorna o nome da rota e o total de waypoints.
+func (fc *FileConfig) AddWaypoint(wp Waypoint) (string, int) {
+	if fc.Route == "" {
+		fc.Route = "default"
+	}
+	if fc.Routes == nil {
+		fc.Routes = make(map[string]Route)
+	}
+	r := fc.Routes[fc.Route]
+	r.Waypoints = append(r.Waypoints, wp)
+	fc.Routes[fc.Route] = r
+	return fc.Route, len(r.Waypoints)
+}
@@ -0,0 +1,266 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ====================
+// Death
+// ====================
+
+// Políticas ao morrer
+const (
+	DeathStop      = "stop"      // para o bot
+	DeathAlert     = "alert"     // fica parado em DEAD esperando res manual
+	DeathResurrect = "resurrect" // envia a sequência de res e volta ao anchor
+)
+
+// DeathConfig define o que o bot faz quando o player morre. Em qualquer
+// política o bot para todo input enquanto estiver morto e emite um alert.
+type DeathConfig struct {
+	Policy           string   `json:"policy"`             // stop, alert, resurrect
+	ResurrectKeys    []string `json:"resurrect_keys"`     // sequência (ex: ["ENTER"])
+	ResurrectDelayMs int      `json:"resurrect_delay_ms"` // espera antes da sequência
+	KeyIntervalMs    int      `json:"key_interval_ms"`    // entre teclas da sequência
+	ReviveTimeoutMs  int      `json:"revive_timeout_ms"`  // sem reviver: repete a sequência
+	MaxAttempts      int      `json:"max_attempts"`       // sequências por morte antes de parar
+	MaxDeaths        int      `json:"max_deaths"`         // para o bot após N mortes (0 = sem limite)
+}
+
+func DefaultDeathConfig() DeathConfig {
+	return DeathConfig{
+		Policy:           DeathStop,
+		ResurrectDelayMs: 5000,
+		KeyIntervalMs:    1000,
+		ReviveTimeoutMs:  15000,
+		MaxAttempts:      3,
+		MaxDeaths:        3,
+	}
+}
+
+// HPSample é uma amostra de HP% do player (trace guardado para as mortes).
+type HPSample struct {
+	At time.Time
+	HP float32
+}
+
+// DeathRecord registra uma morte com o contexto de quando aconteceu.
+type DeathRecord struct {
+	At         time.Time
+	State      BotState // estado do bot no momento
+	LastTarget string
+	TargetID   uint32
+	TargetHP   float64 // HP% do target
+	HPTrace    []HPSample
+}
+
+// String formata a morte para o log: "... HP trace: 80% 55% 20% 0%".
+func (d DeathRecord) String() string {
+	target := "none"
+	if d.LastTarget != "" {
+		target = fmt.Sprintf("%s (ID:%d %.0f%%)", d.LastTarget, d.TargetID, d.TargetHP)
+	}
+	trace := make([]string, 0, len(d.HPTrace))
+	for _, s := range d.HPTrace {
+		trace = append(trace, fmt.Sprintf("%.0f%%", s.HP))
+	}
+	return fmt.Sprintf("state %s | target %s | HP trace (%s): %s",
+		d.State, target, hpTraceWindow, strings.Join(trace, " "))
+}
+
+const (
+	hpSampleInterval = 500 * time.Millisecond
+	hpTraceWindow    = 10 * time.Second
+)
+
+// SetDeath troca a política de morte em runtime.
+func (b *Bot) SetDeath(dc DeathConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Death = dc
+}
+
+// GetDeaths retorna as mortes registradas nesta sessão.
+func (b *Bot) GetDeaths() []DeathRecord {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]DeathRecord(nil), b.deaths...)
+}
+
+// sampleHP guarda o HP% do player a cada hpSampleInterval (últimos hpTraceWindow).
+func (b *Bot) sampleHP() {
+	now := b.now()
+	b.mu.RLock()
+	last := b.lastHPSample
+	b.mu.RUnlock()
+	if now.Sub(last) < hpSampleInterval {
+		return
+	}
+	hp, ok := b.playerHPPercent()
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastHPSample = now
+	b.hpTrace = append(b.hpTrace, HPSample{At: now, HP: hp})
+	cut := 0
+	for cut < len(b.hpTrace) && now.Sub(b.hpTrace[cut].At) > hpTraceWindow {
+		cut++
+	}
+	b.hpTrace = b.hpTrace[cut:]
+}
+
+// tickDeath checa a flag de morte do player. Roda antes da behavior tree
+// (uma árvore customizada não tem como esquecer dela): retorna true
+// enquanto o player estiver morto, e nesse caso a árvore não roda.
+func (b *Bot) tickDeath() bool {
+	b.mu.RLock()
+	isDead := b.config.IsPlayerDead
+	state := b.state
+	b.mu.RUnlock()
+	if isDead == nil {
+		return false
+	}
+
+	dead := isDead()
+	if state != StateDead {
+		if !dead {
+			return false
+		}
+		b.onPlayerDeath()
+		return true
+	}
+
+	if !dead {
+		b.onPlayerRevived()
+		return true
+	}
+	b.tickResurrect()
+	return true
+}
+
+// onPlayerDeath solta tudo, registra a morte e aplica a política.
+func (b *Bot) onPlayerDeath() {
+	b.stopMoving()
+	b.stopApproach()
+	b.stopReturn()
+
+	now := b.now()
+	b.mu.Lock()
+	rec := DeathRecord{At: now, State: b.state}
+	if b.currentTarget != nil {
+		rec.LastTarget = b.currentTarget.Name
+		rec.TargetID = b.currentTarget.EntityID
+		rec.TargetHP = hpPercent(*b.currentTarget)
+		b.emitLocked(Event{Type: EventTargetLost, Target: *b.currentTarget, Reason: "player died"})
+	}
+	rec.HPTrace = append([]HPSample(nil), b.hpTrace...)
+	rec.HPTrace = append(rec.HPTrace, HPSample{At: now, HP: 0})
+	b.deaths = append(b.deaths, rec)
+	b.stats.Deaths++
+	b.session.Deaths++
+	deaths := b.stats.Deaths
+
+	b.setStateLocked(StateDead)
+	b.currentTarget = nil
+	b.pendingLoot = nil
+	b.cancelActionsLocked("player morreu")
+	b.deathAt = now
+	b.resStep = 0
+	b.resAttempts = 0
+	dc := b.config.Death
+	b.resNextAt = now.Add(time.Duration(dc.ResurrectDelayMs) * time.Millisecond)
+	b.mu.Unlock()
+
+	fmt.Printf("[BOT] DEATH #%d: %s\n", deaths, rec)
+
+	policy := dc.Policy
+	switch {
+	case policy != DeathAlert && policy != DeathResurrect:
+		policy = DeathStop
+	case policy == DeathResurrect && len(dc.ResurrectKeys) == 0:
+		policy = DeathAlert
+	case policy == DeathResurrect && dc.MaxDeaths > 0 && deaths >= dc.MaxDeaths:
+		fmt.Printf("[BOT] %d deaths (max %d) - parando\n", deaths, dc.MaxDeaths)
+		policy = DeathStop
+	}
+	b.mu.Lock()
+	b.deathPolicy = policy
+	b.emitLocked(Event{Type: EventAlert, Reason: fmt.Sprintf("Player died (#%d) - policy %s", deaths, policy)})
+	b.mu.Unlock()
+
+	if policy == DeathStop {
+		b.Stop()
+	}
+}
+
+// tickResurrect envia a sequência de res (uma tecla por vez, no intervalo
+// configurado) e repete se o player não reviver até o timeout.
+func (b *Bot) tickResurrect() {
+	b.mu.RLock()
+	dc := b.config.Death
+	policy := b.deathPolicy
+	step := b.resStep
+	next := b.resNextAt
+	sendKey := b.config.SendKey
+	b.mu.RUnlock()
+
+	if policy != DeathResurrect || len(dc.ResurrectKeys) == 0 || sendKey == nil {
+		return
+	}
+	now := b.now()
+	if now.Before(next) {
+		return
+	}
+
+	if step < len(dc.ResurrectKeys) {
+		key := dc.ResurrectKeys[step]
+		sendKey(key)
+		fmt.Printf("[BOT] Resurrect: %s (%d/%d)\n", key, step+1, len(dc.ResurrectKeys))
+		b.mu.Lock()
+		b.resStep++
+		if b.resStep == len(dc.ResurrectKeys) {
+			// Sequência enviada: espera reviver
+			b.resAttempts++
+			b.resNextAt = now.Add(time.Duration(dc.ReviveTimeoutMs) * time.Millisecond)
+		} else {
+			b.resNextAt = now.Add(time.Duration(dc.KeyIntervalMs) * time.Millisecond)
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	// Timeout esperando reviver
+	b.mu.Lock()
+	attempts := b.resAttempts
+	b.mu.Unlock()
+	if dc.MaxAttempts > 0 && attempts >= dc.MaxAttempts {
+		fmt.Printf("[BOT] Resurrect failed after %d attempts - parando\n", attempts)
+		b.mu.Lock()
+		b.emitLocked(Event{Type: EventAlert, Reason: fmt.Sprintf("Resurrect failed after %d attempts", attempts)})
+		b.deathPolicy = DeathStop
+		b.mu.Unlock()
+		b.Stop()
+		return
+	}
+	fmt.Printf("[BOT] Resurrect: still dead - retrying (%d/%d)\n", attempts+1, dc.MaxAttempts)
+	b.mu.Lock()
+	b.resStep = 0
+	b.mu.Unlock()
+}
+
+// onPlayerRevived volta ao trabalho: anda até o anchor se estiver longe.
+func (b *Bot) onPlayerRevived() {
+	b.mu.Lock()
+	deadFor := b.now().Sub(b.deathAt)
+	b.setStateLocked(StateIdle)
+	b.hpTrace = nil
+	b.mu.Unlock()
+
+	fmt.Printf("[BOT] Alive again after %s\n", deadFor.Round(time.Second))
+	b.startReturnToAnchor()
+}
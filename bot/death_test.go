@@ -0,0 +1,76 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+)
+
+func TestDeath(t *testing.T) {
+	newWorld := func(dc bot.DeathConfig) (*sim.World, *bot.Bot, *[]string) {
+		w := sim.NewWorld()
+		w.Damage["1"] = 5
+		w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 1000})
+		cfg := w.Config("Wolf")
+		cfg.Anchor = &Waypoint{}
+		cfg.Death = dc
+		alerts := &[]string{}
+		b := w.NewBot(cfg)
+		b.Events().Subscribe(func(e bot.Event) { *alerts = append(*alerts, e.Reason) }, bot.EventAlert)
+		return w, b, alerts
+	}
+
+	// stop: morre em combate, registra o contexto e não envia mais nada
+	w, b, alerts := newWorld(bot.DefaultDeathConfig())
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetState() == bot.StateCombat }) {
+		t.Fatalf("stop: never entered combat")
+	}
+	w.Run(b, 100, step)
+	w.SetPlayerDead(true)
+	w.Run(b, 1, step)
+	if s := b.GetState(); s != bot.StateDead {
+		t.Fatalf("stop: expected DEAD, got %s", s)
+	}
+	keys := len(w.KeyLog())
+	w.Run(b, 500, step)
+	if got := len(w.KeyLog()); got != keys {
+		t.Fatalf("stop: %d keys sent while dead", got-keys)
+	}
+	deaths := b.GetDeaths()
+	if len(deaths) != 1 || deaths[0].LastTarget != "Wolf" || len(deaths[0].HPTrace) < 2 {
+		t.Fatalf("stop: unexpected death record %+v", deaths)
+	}
+	if b.GetStats().Deaths != 1 || len(*alerts) != 1 {
+		t.Fatalf("stop: expected 1 death and 1 alert, got %d/%d", b.GetStats().Deaths, len(*alerts))
+	}
+
+	// resurrect: sequência de res, revive no cemitério e volta ao anchor
+	dc := bot.DefaultDeathConfig()
+	dc.Policy = bot.DeathResurrect
+	dc.ResurrectKeys = []string{"ESC", "ENTER"}
+	dc.ResurrectDelayMs = 1000
+	dc.KeyIntervalMs = 500
+	w, b, _ = newWorld(dc)
+	w.Damage["1"] = 50
+	w.Mob(1).HP, w.Mob(1).MaxHP = 100, 100
+	w.OnKey = func(key string) {
+		if key == "ENTER" && w.IsPlayerDead() {
+			w.SetPlayerDead(false)
+			w.SetPlayerHP(500)
+			w.PlayerX, w.PlayerY = 30, 0
+		}
+	}
+	w.SetPlayerDead(true)
+	w.Run(b, 1, step)
+	if !w.RunUntil(b, 200, step, func() bool { return b.GetState() == bot.StateReturning }) {
+		t.Fatalf("resurrect: expected RETURNING after revive, got %s (ESC %d, ENTER %d)",
+			b.GetState(), w.KeyCount("ESC"), w.KeyCount("ENTER"))
+	}
+	if w.KeyCount("ESC") != 1 || w.KeyCount("ENTER") != 1 {
+		t.Fatalf("resurrect: expected the sequence once")
+	}
+	if !w.RunUntil(b, 3000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		x, y, _, _ := w.GetPlayerPos()
+		t.Fatalf("resurrect: mob not killed after revive (state %s, player %.0f,%.0f)", b.GetState(), x, y)
+	}
+}
@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ====================
+// Events
+// ====================
+
+// EventType identifica o tipo de evento do bot.
+type EventType string
+
+const (
+	EventStateChanged   EventType = "state_changed"   // From -> State
+	EventTargetAcquired EventType = "target_acquired" // SetTarget confirmado no client
+	EventTargetLost     EventType = "target_lost"     // target solto sem matar (Reason)
+	EventTargetKilled   EventType = "target_killed"
+	EventCombatTick     EventType = "combat_tick" // a cada tick em COMBAT
+	EventPotionUsed     EventType = "potion_used" // threshold rule disparou: Reason = nome da regra, Value = % no uso
+	EventLoot           EventType = "loot"        // tecla de loot enviada
+	EventQueueAdd       EventType = "queue_add"
+	EventQueueRemove    EventType = "queue_remove" // Reason = KILLED, OUT OF RANGE, ...
+	EventAlert          EventType = "alert"        // pede atenção (ex: morte), Reason = mensagem
+	EventCombatEnter    EventType = "combat_enter" // flag de combate do player ligou
+	EventCombatLeave    EventType = "combat_leave" // flag de combate do player desligou
+)
+
+// Event é um evento do bot. Só os campos do tipo são preenchidos.
+type Event struct {
+	Type   EventType
+	At     time.Time
+	State  BotState   // estado do bot quando o evento aconteceu
+	From   BotState   // estado anterior (state_changed)
+	Target EntityInfo // target/mob do evento (zero = nenhum)
+	Reason string
+	Key    string  // potion/loot
+	Value  float32 // potion: valor do recurso da regra (%)
+	Queue  int     // tamanho da fila (queue_add/queue_remove)
+}
+
+func (e Event) String() string {
+	switch e.Type {
+	case EventStateChanged:
+		return fmt.Sprintf("%s: %s -> %s", e.Type, e.From, e.State)
+	case EventPotionUsed:
+		return fmt.Sprintf("%s: %s %s (%.0f%%)", e.Type, e.Reason, e.Key, e.Value)
+	case EventQueueAdd, EventQueueRemove:
+		return fmt.Sprintf("%s: %s (ID:%d) [%d] %s", e.Type, e.Target.Name, e.Target.EntityID, e.Queue, e.Reason)
+	case EventAlert:
+		return fmt.Sprintf("%s: %s", e.Type, e.Reason)
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s (ID:%d) - %s", e.Type, e.Target.Name, e.Target.EntityID, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s (ID:%d)", e.Type, e.Target.Name, e.Target.EntityID)
+}
+
+// EventHandler recebe os eventos do bot.
+type EventHandler func(Event)
+
+type subscription struct {
+	types   map[EventType]bool // vazio = todos
+	handler EventHandler
+}
+
+// EventBus distribui os eventos do bot para vários assinantes (overlay,
+// log, stats, alertas) sem que o bot conheça nenhum deles.
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   map[int]subscription
+	nextID int
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]subscription)}
+}
+
+// Subscribe registra um handler para os tipos dados (nenhum = todos).
+// Retorna a função que cancela a inscrição.
+func (eb *EventBus) Subscribe(handler EventHandler, types ...EventType) (unsubscribe func()) {
+	sub := subscription{handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	eb.mu.Lock()
+	id := eb.nextID
+	eb.nextID++
+	eb.subs[id] = sub
+	eb.mu.Unlock()
+
+	return func() {
+		eb.mu.Lock()
+		delete(eb.subs, id)
+		eb.mu.Unlock()
+	}
+}
+
+// Publish entrega o evento aos assinantes, na ordem de inscrição.
+func (eb *EventBus) Publish(e Event) {
+	eb.mu.RLock()
+	ids := make([]int, 0, len(eb.subs))
+	for id, sub := range eb.subs {
+		if sub.types == nil || sub.types[e.Type] {
+			ids = append(ids, id)
+		}
+	}
+	handlers := make([]EventHandler, 0, len(ids))
+	sort.Ints(ids)
+	for _, id := range ids {
+		handlers = append(handlers, eb.subs[id].handler)
+	}
+	eb.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+// Events retorna o bus de eventos do bot para inscrever handlers.
+func (b *Bot) Events() *EventBus {
+	return b.events
+}
+
+// emitLocked enfileira um evento. Chamar com b.mu travado: os eventos só
+// são entregues no fim do tick (flushEvents), fora do lock, então os
+// handlers podem chamar qualquer getter do bot.
+func (b *Bot) emitLocked(e Event) {
+	e.At = b.now()
+	e.State = b.state
+	b.pendingEvents = append(b.pendingEvents, e)
+}
+
+// emit enfileira um evento (sem o lock).
+func (b *Bot) emit(e Event) {
+	b.mu.Lock()
+	b.emitLocked(e)
+	b.mu.Unlock()
+}
+
+// flushEvents entrega os eventos enfileirados.
+func (b *Bot) flushEvents() {
+	b.mu.Lock()
+	pending := b.pendingEvents
+	b.pendingEvents = nil
+	b.mu.Unlock()
+
+	for _, e := range pending {
+		b.events.Publish(e)
+	}
+}
+
+// setStateLocked troca o estado e emite state_changed. Chamar com b.mu travado.
+func (b *Bot) setStateLocked(s BotState) {
+	if b.state == s {
+		return
+	}
+	from := b.state
+	b.state = s
+	b.emitLocked(Event{Type: EventStateChanged, From: from})
+}
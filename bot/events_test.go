@@ -0,0 +1,74 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 50
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 28, MaxHP: 100, VX: 20}) // sai da range
+	cfg := w.Config("Wolf")
+	cfg.HPPotionEnabled = true
+	w.Heal[cfg.HPPotionKey] = 10 // evento só com a potion confirmada
+	b := w.NewBot(cfg)
+
+	var all []bot.Event
+	var states []string
+	b.Events().Subscribe(func(e bot.Event) { all = append(all, e) })
+	unsubscribe := b.Events().Subscribe(func(e bot.Event) {
+		states = append(states, e.From.String()+">"+e.State.String())
+	}, bot.EventStateChanged)
+
+	w.SetPlayerHP(400)
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed (state %s)", b.GetState())
+	}
+	w.Run(b, int(time.Second/step), step)
+
+	find := func(typ bot.EventType, id uint32) *bot.Event {
+		for i := range all {
+			if all[i].Type == typ && all[i].Target.EntityID == id {
+				return &all[i]
+			}
+		}
+		return nil
+	}
+	if find(bot.EventQueueAdd, 1) == nil || find(bot.EventQueueAdd, 2) == nil {
+		t.Fatalf("missing queue_add events")
+	}
+	if e := find(bot.EventQueueRemove, 2); e == nil || e.Reason != bot.SkipOutOfRange {
+		t.Fatalf("expected queue_remove out of range for mob 2, got %+v", e)
+	}
+	if e := find(bot.EventQueueRemove, 1); e == nil || e.Reason != "KILLED" {
+		t.Fatalf("expected queue_remove KILLED for mob 1, got %+v", e)
+	}
+	if find(bot.EventTargetAcquired, 1) == nil || find(bot.EventTargetKilled, 1) == nil || find(bot.EventLoot, 1) == nil {
+		t.Fatalf("missing target/loot events")
+	}
+	if e := find(bot.EventPotionUsed, 0); e == nil || e.Reason != "hp_potion" || e.Value != 40 {
+		t.Fatalf("expected HP potion event at 40%%, got %+v", e)
+	}
+	want := []string{"IDLE>TARGETING", "TARGETING>COMBAT", "COMBAT>LOOTING", "LOOTING>IDLE"}
+	if len(states) < len(want) || strings.Join(states[:len(want)], " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected transitions: %v", states)
+	}
+
+	// Target solto sem matar tem motivo; inscrição cancelada não recebe mais
+	unsubscribe()
+	n := len(states)
+	w.AddMob(sim.Mob{ID: 3, Name: "Wolf", X: 5, MaxHP: 100})
+	w.RejectTarget = func(id uint32) bool { return id == 3 }
+	w.Run(b, 10, step)
+	if e := find(bot.EventTargetLost, 3); e == nil || !strings.HasPrefix(e.Reason, "target mismatch") {
+		t.Fatalf("expected target_lost mismatch for mob 3, got %+v", e)
+	}
+	if len(states) != n {
+		t.Fatalf("unsubscribed handler still called")
+	}
+}
@@ -0,0 +1,328 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"archefriend/action"
+)
+
+// ====================
+// Goals
+// ====================
+
+// Ações ao completar um goal
+const (
+	GoalStop   = "stop"   // para o bot (padrão)
+	GoalPreset = "preset" // carrega outro preset (LoadPreset) e continua
+	GoalKeys   = "keys"   // envia uma sequência de teclas e continua
+)
+
+// Goal é uma meta do bot. Os goals rodam em sequência: ao completar um,
+// executa Then e passa para o próximo. Exatamente um de Kill/Minutes/At:
+//
+//	{"kill": "Bluescale Archerfish", "count": 40, "then": {"action": "preset", "preset": "preset2"}}
+//	{"minutes": 60, "then": {"action": "keys", "keys": ["ESC", "M"]}}
+//	{"at": "23:30"}
+type Goal struct {
+	Name    string     `json:"name,omitempty"`
+	Kill    string     `json:"kill,omitempty"`    // nome do mob (match da config)
+	Count   int        `json:"count,omitempty"`   // kills de Kill
+	Minutes int        `json:"minutes,omitempty"` // tempo rodando (bot parado não conta)
+	At      string     `json:"at,omitempty"`      // horário local HH:MM
+	Then    GoalAction `json:"then"`
+}
+
+// GoalAction é o que fazer quando o goal completa.
+type GoalAction struct {
+	Action  string         `json:"action,omitempty"` // stop (padrão), preset, keys
+	Preset  string         `json:"preset,omitempty"`
+	Keys    []string       `json:"keys,omitempty"`
+	Confirm action.Confirm `json:"confirm,omitempty"` // keys: repete a sequência até confirmar (vazio = uma vez)
+}
+
+// GoalState é o progresso salvo em GoalStateFile: reiniciar a ferramenta
+// continua a contagem.
+type GoalState struct {
+	Index    int       `json:"index"` // goal atual (len(goals) = todos completos)
+	Goal     string    `json:"goal"`  // assinatura do goal atual (muda = reinicia)
+	Kills    int       `json:"kills"`
+	RunMs    int64     `json:"run_ms"`
+	Deadline time.Time `json:"deadline,omitempty"` // goals "at"
+}
+
+const (
+	// Intervalo entre ticks acima disso conta como bot parado
+	goalMaxTickGap = 2 * time.Second
+	// Salva o progresso de tempo a cada
+	goalSaveInterval = 10 * time.Second
+)
+
+func (g Goal) validate() error {
+	n := 0
+	if g.Kill != "" {
+		n++
+		if g.Count <= 0 {
+			return fmt.Errorf("goal kill '%s' sem count", g.Kill)
+		}
+	}
+	if g.Minutes > 0 {
+		n++
+	}
+	if g.At != "" {
+		n++
+		if _, err := time.Parse("15:04", g.At); err != nil {
+			return fmt.Errorf("goal at '%s': use HH:MM", g.At)
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("goal precisa de exatamente um de kill, minutes ou at")
+	}
+	switch g.Then.Action {
+	case "", GoalStop:
+	case GoalPreset:
+		if g.Then.Preset == "" {
+			return fmt.Errorf("goal then preset sem nome")
+		}
+	case GoalKeys:
+		if len(g.Then.Keys) == 0 {
+			return fmt.Errorf("goal then keys sem teclas")
+		}
+	default:
+		return fmt.Errorf("goal then: ação desconhecida %q", g.Then.Action)
+	}
+	return nil
+}
+
+func validateGoals(goals []Goal) error {
+	for i, g := range goals {
+		if err := g.validate(); err != nil {
+			return fmt.Errorf("goal %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (g Goal) signature() string {
+	data, _ := json.Marshal(g)
+	return string(data)
+}
+
+func (g Goal) String() string {
+	if g.Name != "" {
+		return g.Name
+	}
+	switch {
+	case g.Kill != "":
+		return fmt.Sprintf("kill %d %s", g.Count, g.Kill)
+	case g.Minutes > 0:
+		return fmt.Sprintf("run %dmin", g.Minutes)
+	default:
+		return "until " + g.At
+	}
+}
+
+// nextClock retorna o próximo HH:MM depois de from (hoje ou amanhã).
+func nextClock(from time.Time, hhmm string) time.Time {
+	t, _ := time.Parse("15:04", hhmm)
+	at := time.Date(from.Year(), from.Month(), from.Day(), t.Hour(), t.Minute(), 0, 0, from.Location())
+	if !at.After(from) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// SetGoals troca os goals e carrega o progresso salvo em stateFile ("" =
+// não persiste). Progresso de outros goals é descartado.
+func (b *Bot) SetGoals(goals []Goal, stateFile string) error {
+	if err := validateGoals(goals); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Goals = goals
+	b.config.GoalStateFile = stateFile
+	b.loadGoalStateLocked()
+	if len(goals) > 0 {
+		fmt.Printf("[BOT] Goals: %d (%s)\n", len(goals), b.goalProgressLocked())
+	}
+	return nil
+}
+
+// ResetGoals recomeça do primeiro goal, sem progresso.
+func (b *Bot) ResetGoals() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.goalState = GoalState{}
+	b.startGoalLocked()
+	b.saveGoalStateLocked()
+}
+
+// GetGoalState retorna o progresso atual.
+func (b *Bot) GetGoalState() GoalState {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.goalState
+}
+
+// GoalProgress descreve o goal atual para o overlay (false = sem goals).
+func (b *Bot) GoalProgress() (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.config.Goals) == 0 {
+		return "", false
+	}
+	return b.goalProgressLocked(), true
+}
+
+func (b *Bot) goalProgressLocked() string {
+	goals := b.config.Goals
+	st := b.goalState
+	if st.Index >= len(goals) {
+		return "Goals: done"
+	}
+	g := goals[st.Index]
+	prefix := fmt.Sprintf("Goal %d/%d", st.Index+1, len(goals))
+	switch {
+	case g.Kill != "":
+		return fmt.Sprintf("%s: %s %d/%d", prefix, g.Kill, st.Kills, g.Count)
+	case g.Minutes > 0:
+		return fmt.Sprintf("%s: %d/%dmin", prefix, st.RunMs/60000, g.Minutes)
+	default:
+		return fmt.Sprintf("%s: until %s", prefix, st.Deadline.Format("15:04"))
+	}
+}
+
+// loadGoalStateLocked lê o progresso salvo; se o goal salvo não for mais
+// o goal daquele índice, começa do zero.
+func (b *Bot) loadGoalStateLocked() {
+	b.goalState = GoalState{}
+	if file := b.config.GoalStateFile; file != "" {
+		if data, err := os.ReadFile(file); err == nil {
+			var st GoalState
+			if err := json.Unmarshal(data, &st); err != nil {
+				fmt.Printf("[BOT] Goal state %s inválido: %v\n", file, err)
+			} else if st.Index == len(b.config.Goals) && st.Goal == "" ||
+				st.Index < len(b.config.Goals) && st.Goal == b.config.Goals[st.Index].signature() {
+				b.goalState = st
+				return
+			}
+		}
+	}
+	b.startGoalLocked()
+}
+
+// startGoalLocked prepara o goal atual (assinatura e deadline de "at").
+func (b *Bot) startGoalLocked() {
+	st := &b.goalState
+	st.Kills, st.RunMs, st.Deadline, st.Goal = 0, 0, time.Time{}, ""
+	if st.Index >= len(b.config.Goals) {
+		return
+	}
+	g := b.config.Goals[st.Index]
+	st.Goal = g.signature()
+	if g.At != "" {
+		st.Deadline = nextClock(b.now(), g.At)
+	}
+}
+
+func (b *Bot) saveGoalStateLocked() {
+	file := b.config.GoalStateFile
+	if file == "" || len(b.config.Goals) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(b.goalState, "", "  ")
+	if err == nil {
+		err = os.WriteFile(file, data, 0644)
+	}
+	if err != nil {
+		fmt.Printf("[BOT] Erro ao salvar goals: %v\n", err)
+	}
+	b.goalSavedAt = b.now()
+}
+
+// goalKillLocked conta o kill para o goal atual. Chamar com b.mu travado.
+func (b *Bot) goalKillLocked(name string) {
+	goals := b.config.Goals
+	st := &b.goalState
+	if st.Index >= len(goals) {
+		return
+	}
+	g := goals[st.Index]
+	if g.Kill != "" && matchName(name, []string{g.Kill}, b.config.PartialMatch) {
+		st.Kills++
+		b.saveGoalStateLocked()
+	}
+}
+
+// tickGoals acumula o tempo rodando e completa o goal atual.
+func (b *Bot) tickGoals() {
+	now := b.now()
+	b.mu.Lock()
+	goals := b.config.Goals
+	if len(goals) == 0 || b.goalState.Index >= len(goals) {
+		b.mu.Unlock()
+		return
+	}
+	st := &b.goalState
+	if gap := now.Sub(b.goalLastTick); !b.goalLastTick.IsZero() && gap > 0 && gap < goalMaxTickGap {
+		st.RunMs += gap.Milliseconds()
+	}
+	b.goalLastTick = now
+
+	g := goals[st.Index]
+	done := false
+	switch {
+	case g.Kill != "":
+		done = st.Kills >= g.Count
+	case g.Minutes > 0:
+		done = st.RunMs >= int64(g.Minutes)*60000
+	default:
+		done = !now.Before(st.Deadline)
+	}
+	if !done {
+		if now.Sub(b.goalSavedAt) >= goalSaveInterval {
+			b.saveGoalStateLocked()
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	// Próximo goal antes da ação: reiniciar depois de um stop não para de novo
+	index := st.Index
+	st.Index++
+	b.startGoalLocked()
+	b.saveGoalStateLocked()
+	configFile := b.config.ConfigFile
+	sendKey := b.config.SendKey
+	b.mu.Unlock()
+
+	fmt.Printf("[BOT] Goal %d/%d complete: %s\n", index+1, len(goals), g)
+	b.emit(Event{Type: EventAlert, Reason: fmt.Sprintf("Goal complete: %s", g)})
+
+	switch g.Then.Action {
+	case GoalPreset:
+		if err := b.LoadPreset(configFile, g.Then.Preset); err != nil {
+			fmt.Printf("[BOT] Goal: preset '%s': %v - parando\n", g.Then.Preset, err)
+			b.Stop()
+		}
+	case GoalKeys:
+		// O goal tem prioridade sobre um press da árvore em andamento
+		b.mu.Lock()
+		delete(b.inflight, slotPress)
+		b.mu.Unlock()
+		keys := g.Then.Keys
+		b.startAction(slotPress, "goal keys", func() {
+			for i, key := range keys {
+				sendKey(key)
+				if i < len(keys)-1 {
+					b.sleep(KeySequenceInterval)
+				}
+			}
+		}, g.Then.Confirm, nil)
+	default:
+		b.Stop()
+	}
+}
@@ -0,0 +1,90 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGoals(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "bot_config.json")
+	fc := bot.DefaultFileConfig()
+	fc.Presets = map[string]bot.Preset{"fish": {MobNames: []string{"Archerfish"}}}
+	if err := bot.SaveFileConfig(configFile, &fc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relógio do mundo começa às 12:00
+	goals := []bot.Goal{
+		{Kill: "Wolf", Count: 2, Then: bot.GoalAction{Action: bot.GoalPreset, Preset: "fish"}},
+		{Minutes: 1, Then: bot.GoalAction{Action: bot.GoalKeys, Keys: []string{"F9"}}},
+		{At: "12:30"},
+	}
+	w := sim.NewWorld()
+	w.Damage["1"] = 50
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 8, MaxHP: 100})
+	cfg := w.Config("Wolf")
+	cfg.Goals = goals
+	cfg.GoalStateFile = filepath.Join(dir, "goals_state.json")
+	cfg.ConfigFile = configFile
+	b := w.NewBot(cfg)
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("first wolf not killed (state %s)", b.GetState())
+	}
+
+	// Reiniciar a ferramenta continua a contagem
+	b = w.NewBot(cfg)
+	if st := b.GetGoalState(); st.Index != 0 || st.Kills != 1 {
+		t.Fatalf("progress not resumed: %+v", st)
+	}
+	var alerts []string
+	b.Events().Subscribe(func(e bot.Event) { alerts = append(alerts, e.Reason) }, bot.EventAlert)
+	if progress, _ := b.GoalProgress(); progress != "Goal 1/3: Wolf 1/2" {
+		t.Fatalf("unexpected progress %q", progress)
+	}
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetGoalState().Index == 1 }) {
+		t.Fatalf("kill goal not completed: %+v", b.GetGoalState())
+	}
+	if names := b.GetConfig().MobNames; len(names) != 1 || names[0] != "Archerfish" {
+		t.Fatalf("preset not loaded on goal: %v", names)
+	}
+
+	// Tempo rodando: 1 minuto e envia F9
+	w.Run(b, int(59*time.Second/step), step)
+	if b.GetGoalState().Index != 1 || w.KeyCount("F9") != 0 {
+		t.Fatalf("minutes goal completed early: %+v", b.GetGoalState())
+	}
+	w.Run(b, int(2*time.Second/step), step)
+	if b.GetGoalState().Index != 2 || w.KeyCount("F9") != 1 {
+		t.Fatalf("minutes goal: %+v F9 %d", b.GetGoalState(), w.KeyCount("F9"))
+	}
+
+	// Horário: para às 12:30
+	if !w.RunUntil(b, 3600, time.Second, func() bool { return b.GetGoalState().Index == 3 }) {
+		t.Fatalf("clock goal not completed: %+v", b.GetGoalState())
+	}
+	if now := w.Clock.Now(); now.Hour() != 12 || now.Minute() != 30 {
+		t.Fatalf("clock goal completed at %s", now.Format("15:04"))
+	}
+	if len(alerts) != 3 || alerts[2] != "Goal complete: until 12:30" {
+		t.Fatalf("unexpected goal alerts: %v", alerts)
+	}
+	if progress, _ := b.GoalProgress(); progress != "Goals: done" {
+		t.Fatalf("unexpected progress %q", progress)
+	}
+
+	// Goals editados não herdam progresso de outros goals
+	if err := b.SetGoals(goals[:1], cfg.GoalStateFile); err != nil {
+		t.Fatal(err)
+	}
+	if st := b.GetGoalState(); st.Index != 0 || st.Kills != 0 {
+		t.Fatalf("stale progress for new goals: %+v", st)
+	}
+	if err := b.SetGoals([]bot.Goal{{Kill: "Wolf"}}, ""); err == nil {
+		t.Fatalf("kill goal without count accepted")
+	}
+}
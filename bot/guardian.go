@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"archefriend/action"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ====================
+// Guardian
+// ====================
+
+// GuardianConfig configura o modo guardian: só as regras de threshold,
+// sem targeting nem movimento (jogando manualmente com potions automáticas).
+type GuardianConfig struct {
+	Rules     []ThresholdRule
+	Interval  time.Duration // entre avaliações (0 = 100ms)
+	SendKey   func(key string)
+	Resources Resources
+	Clock     Clock            // nil = relógio do sistema
+	Actions   *action.Executor // confirmação das regras (nil = executor próprio)
+}
+
+// Guardian roda o ThresholdEngine sozinho, num loop próprio.
+type Guardian struct {
+	mu       sync.Mutex
+	config   GuardianConfig
+	engine   *ThresholdEngine
+	running  bool
+	stopChan chan struct{}
+	fired    int
+}
+
+func NewGuardian(cfg GuardianConfig) (*Guardian, error) {
+	engine, err := NewThresholdEngine(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 100 * time.Millisecond
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+	if cfg.Actions == nil {
+		cfg.Actions = action.NewExecutor(action.DefaultPolicy(), cfg.Clock.Now, cfg.Clock.Sleep)
+		cfg.Actions.Resource = cfg.Resources.Read
+	}
+	return &Guardian{config: cfg, engine: engine}, nil
+}
+
+func (g *Guardian) Start() {
+	g.mu.Lock()
+	if g.running {
+		g.mu.Unlock()
+		return
+	}
+	g.running = true
+	g.stopChan = make(chan struct{})
+	stop := g.stopChan
+	interval := g.config.Interval
+	g.mu.Unlock()
+
+	fmt.Printf("[GUARDIAN] Started (%d rules)\n", len(g.engine.Rules()))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				g.Step()
+			}
+		}
+	}()
+}
+
+func (g *Guardian) Stop() {
+	g.mu.Lock()
+	if !g.running {
+		g.mu.Unlock()
+		return
+	}
+	g.running = false
+	close(g.stopChan)
+	fired := g.fired
+	g.mu.Unlock()
+	fmt.Printf("[GUARDIAN] Stopped (%d actions)\n", fired)
+}
+
+func (g *Guardian) IsRunning() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.running
+}
+
+// Step avalia as regras uma vez (o loop chama a cada Interval; a
+// simulação chama direto).
+func (g *Guardian) Step() {
+	fire, ok := g.engine.Evaluate(g.config.Clock.Now(), g.config.Resources)
+	if !ok || g.config.SendKey == nil {
+		return
+	}
+	if _, ok := fire.Do(g.config.Actions, g.config.SendKey, g.config.Clock.Sleep); !ok {
+		fmt.Printf("[GUARDIAN] %s - não confirmado\n", fire)
+		return
+	}
+	g.engine.Commit(fire, g.config.Clock.Now())
+	g.mu.Lock()
+	g.fired++
+	g.mu.Unlock()
+	fmt.Printf("[GUARDIAN] %s\n", fire)
+}
@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ====================
+// Navigation (waypoints / patrol routes)
+// ====================
+//
+// Coordenadas: X/Y é o plano horizontal e Z a altura (mesma convenção do
+// esp.GetPlayerPosition). Não lemos a rotação do personagem: o heading é
+// estimado pelo deslocamento entre ticks enquanto o forward está pressionado.
+
+// Waypoint é um ponto da rota.
+type Waypoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	Z float32 `json:"z"`
+}
+
+// Route é uma lista de waypoints. Loop=true volta do último para o
+// primeiro; senão percorre em vai-e-volta.
+type Route struct {
+	Waypoints []Waypoint `json:"waypoints"`
+	Loop      bool       `json:"loop"`
+}
+
+// MovementConfig são as teclas e parâmetros de movimento.
+type MovementConfig struct {
+	ForwardKey    string  `json:"forward_key"`     // "W"
+	TurnLeftKey   string  `json:"turn_left_key"`   // "A"
+	TurnRightKey  string  `json:"turn_right_key"`  // "D"
+	ArriveRadius  float32 `json:"arrive_radius"`   // metros para considerar o waypoint atingido
+	TurnTolerance float32 `json:"turn_tolerance"`  // graus de erro aceitos sem virar
+	TurnRate      float32 `json:"turn_rate"`       // graus/s que o personagem gira segurando a tecla
+	MinSampleDist float32 `json:"min_sample_dist"` // deslocamento mínimo para estimar heading
+}
+
+// DefaultMovementConfig - WASD padrão do client.
+func DefaultMovementConfig() MovementConfig {
+	return MovementConfig{
+		ForwardKey:    "W",
+		TurnLeftKey:   "A",
+		TurnRightKey:  "D",
+		ArriveRadius:  3.0,
+		TurnTolerance: 20.0,
+		TurnRate:      180.0,
+		MinSampleDist: 0.5,
+	}
+}
+
+func (m MovementConfig) withDefaults() MovementConfig {
+	d := DefaultMovementConfig()
+	if m.ForwardKey == "" {
+		m.ForwardKey = d.ForwardKey
+	}
+	if m.TurnLeftKey == "" {
+		m.TurnLeftKey = d.TurnLeftKey
+	}
+	if m.TurnRightKey == "" {
+		m.TurnRightKey = d.TurnRightKey
+	}
+	if m.ArriveRadius <= 0 {
+		m.ArriveRadius = d.ArriveRadius
+	}
+	if m.TurnTolerance <= 0 {
+		m.TurnTolerance = d.TurnTolerance
+	}
+	if m.TurnRate <= 0 {
+		m.TurnRate = d.TurnRate
+	}
+	if m.MinSampleDist <= 0 {
+		m.MinSampleDist = d.MinSampleDist
+	}
+	return m
+}
+
+// Navigator segue uma rota segurando forward e corrigindo o heading.
+// Não é thread-safe: usado só pelo tick do bot.
+type Navigator struct {
+	route Route
+	cfg   MovementConfig
+	index int
+	dir   int // +1 / -1 (vai-e-volta)
+
+	heading      float64 // radianos, atan2(dy, dx)
+	headingKnown bool
+	sampleX      float32
+	sampleY      float32
+	hasSample    bool
+
+	forwardDown bool
+	turnKey     string
+	turnUntil   time.Time
+}
+
+// NewNavigator cria o navegador (rota pode ser vazia).
+func NewNavigator(route Route, cfg MovementConfig) *Navigator {
+	return &Navigator{route: route, cfg: cfg.withDefaults(), dir: 1}
+}
+
+// HasRoute indica se há waypoints para patrulhar.
+func (n *Navigator) HasRoute() bool {
+	return len(n.route.Waypoints) > 0
+}
+
+// Index retorna o waypoint atual.
+func (n *Navigator) Index() int {
+	return n.index
+}
+
+// Waypoint retorna o waypoint atual.
+func (n *Navigator) Waypoint() Waypoint {
+	if !n.HasRoute() {
+		return Waypoint{}
+	}
+	return n.route.Waypoints[n.index]
+}
+
+// keyIO agrupa como o navigator manda teclas (hold ou tap).
+type keyIO struct {
+	down func(string)
+	up   func(string)
+	tap  func(string)
+}
+
+func (k keyIO) press(key string) {
+	if k.down != nil {
+		k.down(key)
+	} else if k.tap != nil {
+		k.tap(key)
+	}
+}
+
+func (k keyIO) release(key string) {
+	if k.up != nil {
+		k.up(key)
+	}
+}
+
+// Stop solta todas as teclas de movimento e invalida o heading amostrado
+// (o personagem pode ser virado durante o combate).
+func (n *Navigator) Stop(io keyIO) {
+	if n.forwardDown {
+		io.release(n.cfg.ForwardKey)
+		n.forwardDown = false
+	}
+	if n.turnKey != "" {
+		io.release(n.turnKey)
+		n.turnKey = ""
+	}
+	n.headingKnown = false
+	n.hasSample = false
+}
+
+// Step avança a navegação até o waypoint atual. Retorna o motivo para log
+// quando algo relevante acontece ("" caso contrário).
+func (n *Navigator) Step(io keyIO, x, y float32, now time.Time) string {
+	if !n.HasRoute() {
+		return ""
+	}
+	msg := ""
+
+	// Chegou no waypoint? avança
+	wp := n.route.Waypoints[n.index]
+	if dist2D(x, y, wp.X, wp.Y) <= n.cfg.ArriveRadius {
+		n.advance()
+		wp = n.route.Waypoints[n.index]
+		msg = fmt.Sprintf("waypoint -> %d (%.0f, %.0f)", n.index, wp.X, wp.Y)
+	}
+
+	if turn := n.StepToward(io, x, y, wp, now); msg == "" {
+		msg = turn
+	}
+	return msg
+}
+
+// StepToward anda em direção a goal (sem checar chegada). Usado pela
+// patrulha e pelo approach até o target, que pode estar se movendo.
+// Retorna uma descrição da curva quando vira ("" caso contrário).
+func (n *Navigator) StepToward(io keyIO, x, y float32, wp Waypoint, now time.Time) string {
+	// Atualiza heading pelo deslocamento desde a última amostra
+	if !n.hasSample {
+		n.sampleX, n.sampleY, n.hasSample = x, y, true
+	} else if dist2D(x, y, n.sampleX, n.sampleY) >= n.cfg.MinSampleDist {
+		n.heading = math.Atan2(float64(y-n.sampleY), float64(x-n.sampleX))
+		n.headingKnown = true
+		n.sampleX, n.sampleY = x, y
+	}
+
+	// Fim de uma curva em andamento
+	if n.turnKey != "" && !now.Before(n.turnUntil) {
+		io.release(n.turnKey)
+		n.turnKey = ""
+	}
+
+	if !n.forwardDown || io.down == nil {
+		io.press(n.cfg.ForwardKey)
+		n.forwardDown = true
+	}
+
+	// Sem heading ainda: só anda para frente até ter amostra
+	if !n.headingKnown || n.turnKey != "" {
+		return ""
+	}
+
+	want := math.Atan2(float64(wp.Y-y), float64(wp.X-x))
+	diff := normalizeAngle(want - n.heading)
+	diffDeg := diff * 180 / math.Pi
+	if math.Abs(diffDeg) <= float64(n.cfg.TurnTolerance) {
+		return ""
+	}
+
+	// Vira proporcional ao erro; positivo = anti-horário = esquerda
+	key := n.cfg.TurnLeftKey
+	if diff < 0 {
+		key = n.cfg.TurnRightKey
+	}
+	hold := time.Duration(math.Abs(diffDeg) / float64(n.cfg.TurnRate) * float64(time.Second))
+	io.press(key)
+	if io.down != nil {
+		n.turnKey = key
+		n.turnUntil = now.Add(hold)
+	}
+	// O heading é re-estimado depois da curva
+	n.headingKnown = false
+	n.sampleX, n.sampleY = x, y
+	return fmt.Sprintf("turn %s %.0f°", key, diffDeg)
+}
+
+func (n *Navigator) advance() {
+	count := len(n.route.Waypoints)
+	if count <= 1 {
+		return
+	}
+	if n.route.Loop {
+		n.index = (n.index + 1) % count
+		return
+	}
+	next := n.index + n.dir
+	if next < 0 || next >= count {
+		n.dir = -n.dir
+		next = n.index + n.dir
+	}
+	n.index = next
+}
+
+func normalizeAngle(a float64) float64 {
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	for a < -math.Pi {
+		a += 2 * math.Pi
+	}
+	return a
+}
+
+func dist2D(x1, y1, x2, y2 float32) float32 {
+	dx := float64(x1 - x2)
+	dy := float64(y1 - y2)
+	return float32(math.Sqrt(dx*dx + dy*dy))
+}
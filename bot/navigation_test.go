@@ -0,0 +1,156 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+// aliases para os testes ficarem legíveis
+type (
+	Route    = bot.Route
+	Waypoint = bot.Waypoint
+)
+
+func TestPatrolRoute(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 50
+	w.PlayerHeading = 2.5 // começa virado para longe da rota
+	// Wolf longe do início, fora da range (30m), perto do 2º waypoint
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 80, Y: 45, MaxHP: 100})
+
+	cfg := w.Config("Wolf")
+	cfg.Route = &Route{Loop: true, Waypoints: []Waypoint{
+		{X: 40, Y: 0}, {X: 80, Y: 40}, {X: 0, Y: 40},
+	}}
+	b := w.NewBot(cfg)
+
+	// Anda até o mob entrar na range, para e mata
+	if !w.RunUntil(b, 3000, step, func() bool { return b.GetState() == bot.StateCombat }) {
+		x, y, _, _ := w.GetPlayerPos()
+		t.Fatalf("never reached mob (state %s, player at %.0f,%.0f)", b.GetState(), x, y)
+	}
+	if w.IsHeld(w.ForwardKey) {
+		t.Fatalf("still moving while in combat")
+	}
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed on route")
+	}
+
+	// Retoma a rota e completa uma volta (passa perto do 3º waypoint)
+	near := func(x0, y0 float32) func() bool {
+		return func() bool {
+			x, y, _, _ := w.GetPlayerPos()
+			return (x-x0)*(x-x0)+(y-y0)*(y-y0) <= 9
+		}
+	}
+	if !w.RunUntil(b, 5000, step, near(0, 40)) {
+		x, y, _, _ := w.GetPlayerPos()
+		t.Fatalf("route not resumed (state %s, player at %.0f,%.0f)", b.GetState(), x, y)
+	}
+	if s := b.GetState(); s != bot.StatePatrolling {
+		t.Fatalf("expected PATROLLING, got %s", s)
+	}
+
+	b.Stop()
+	if w.IsHeld(w.ForwardKey) || w.IsHeld(w.TurnLeftKey) || w.IsHeld(w.TurnRightKey) {
+		t.Fatalf("movement keys held after Stop")
+	}
+}
+
+func TestApproach(t *testing.T) {
+	newWorld := func(mob sim.Mob) (*sim.World, *bot.Bot) {
+		w := sim.NewWorld()
+		w.Damage["1"] = 50
+		w.AttackRange = 5 // melee
+		w.PlayerHeading = -1.5
+		w.AddMob(mob)
+		cfg := w.Config("Wolf")
+		cfg.EngageDistance = 4
+		cfg.ApproachTimeout = 8 * time.Second
+		return w, w.NewBot(cfg)
+	}
+
+	// sim.Mob parado a 20m: anda até ele e mata
+	w, b := newWorld(sim.Mob{ID: 1, Name: "Wolf", X: 14, Y: 14, MaxHP: 100})
+	if !w.RunUntil(b, 2000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		x, y, _, _ := w.GetPlayerPos()
+		t.Fatalf("static: not killed (state %s, player %.0f,%.0f)", b.GetState(), x, y)
+	}
+	if w.IsHeld(w.ForwardKey) {
+		t.Fatalf("static: still moving after kill")
+	}
+
+	// sim.Mob andando para longe mais devagar que o player: alcança
+	w, b = newWorld(sim.Mob{ID: 1, Name: "Wolf", X: 15, Y: 0, VX: 0, VY: 2, MaxHP: 100})
+	if !w.RunUntil(b, 3000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		m := w.Mob(1)
+		x, y, _, _ := w.GetPlayerPos()
+		t.Fatalf("moving: not killed (state %s, player %.0f,%.0f, mob %.0f,%.0f)", b.GetState(), x, y, m.X, m.Y)
+	}
+
+	// sim.Mob mais rápido que o player (circulando): timeout e fica ignorado
+	w, b = newWorld(sim.Mob{ID: 1, Name: "Wolf", X: 15, Y: 0, VX: 0, VY: 6, MaxHP: 100})
+	w.Run(b, int(9*time.Second/step), step)
+	w.MoveMob(1, 15, 0, 0)
+	w.Mob(1).VY = 0
+	w.Run(b, 10, step)
+	if tgt := b.GetCurrentTarget(); tgt != nil {
+		t.Fatalf("fast: expected target dropped after timeout, state %s", b.GetState())
+	}
+	if w.IsHeld(w.ForwardKey) {
+		t.Fatalf("fast: still moving after timeout")
+	}
+}
+
+func TestLeash(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 50
+	// Dentro da range do player (30m) mas a 22m do anchor: nunca entra na fila
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: -5, Y: 22, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 10, Y: 0, MaxHP: 100})
+
+	cfg := w.Config("Wolf")
+	cfg.LeashRadius = 15
+	b := w.NewBot(cfg)
+
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob inside leash not killed (state %s)", b.GetState())
+	}
+	for _, e := range b.GetKillQueue() {
+		if e.EntityID == 1 {
+			t.Fatalf("mob outside leash entered the queue")
+		}
+	}
+	if _, radius, ok := b.GetLeash(); !ok || radius != 15 {
+		t.Fatalf("expected leash anchored at start position")
+	}
+
+	// Player arrastado para fora do leash durante a luta: volta antes do próximo
+	w.PlayerX, w.PlayerY = 22, 0
+	w.PlayerHeading = 0 // de costas para o anchor
+	w.AddMob(sim.Mob{ID: 3, Name: "Wolf", X: 5, Y: 5, MaxHP: 100})
+	if !w.RunUntil(b, 200, step, func() bool { return b.GetState() == bot.StateReturning }) {
+		t.Fatalf("expected RETURNING outside leash, got %s", b.GetState())
+	}
+	if !w.RunUntil(b, 2000, step, func() bool { return b.GetState() != bot.StateReturning }) {
+		x, y, _, _ := w.GetPlayerPos()
+		t.Fatalf("never got back to anchor (player at %.0f,%.0f)", x, y)
+	}
+	if x, y, _, _ := w.GetPlayerPos(); x*x+y*y > 4*4 {
+		t.Fatalf("left RETURNING away from anchor (player at %.1f,%.1f)", x, y)
+	}
+	if w.IsHeld(w.ForwardKey) {
+		t.Fatalf("still moving after reaching anchor")
+	}
+	if got := b.GetStats().TargetsSet; got != 1 {
+		t.Fatalf("picked a target while returning (%d targets set)", got)
+	}
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 2 }) {
+		t.Fatalf("next mob not killed after returning (state %s)", b.GetState())
+	}
+	if w.Mob(1).Dead() {
+		t.Fatalf("mob outside leash was killed")
+	}
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// ====================
+// Constants (SetTarget)
+// ====================
+
+const (
+	OFFSET_SET_TARGET     uintptr = 0x1BE090
+	PTR_ENEMY_TARGET_BASE uintptr = 0x19EBF4
+	OFF_TARGET_ID         uintptr = 0x08
+
+	MEM_COMMIT             = 0x1000
+	MEM_RESERVE            = 0x2000
+	MEM_RELEASE            = 0x8000
+	PAGE_EXECUTE_READWRITE = 0x40
+)
+
+var (
+	kernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procVirtualAllocEx     = kernel32.NewProc("VirtualAllocEx")
+	procVirtualFreeEx      = kernel32.NewProc("VirtualFreeEx")
+	procWriteProcessMem    = kernel32.NewProc("WriteProcessMemory")
+	procReadProcessMem     = kernel32.NewProc("ReadProcessMemory")
+	procCreateRemoteThread = kernel32.NewProc("CreateRemoteThread")
+)
+
+// New cria o bot ligado ao processo do jogo (SetTarget via shellcode).
+func New(handle windows.Handle, x2game uintptr, provider EntityProvider, cfg Config) *Bot {
+	return NewWithTargeter(&processTargeter{handle: handle, x2game: x2game}, provider, cfg)
+}
+
+// processTargeter implementa Targeter escrevendo no processo do jogo.
+type processTargeter struct {
+	handle windows.Handle
+	x2game uintptr
+}
+
+// ====================
+// SetTarget (shellcode)
+// ====================
+
+func (t *processTargeter) SetTarget(unitId uint32) error {
+	addr := t.x2game + OFFSET_SET_TARGET
+
+	shellcode := []byte{
+		0x6A, 0x00, // push 0 (flag)
+		0x68, 0x00, 0x00, 0x00, 0x00, // push unitId
+		0xB8, 0x00, 0x00, 0x00, 0x00, // mov eax, addr
+		0xFF, 0xD0, // call eax
+		0x83, 0xC4, 0x08, // add esp, 8
+		0xC3, // ret
+	}
+
+	*(*uint32)(unsafe.Pointer(&shellcode[3])) = unitId
+	*(*uint32)(unsafe.Pointer(&shellcode[8])) = uint32(addr)
+
+	alloc, err := virtualAllocEx(t.handle, 256)
+	if err != nil {
+		return err
+	}
+	defer virtualFreeEx(t.handle, alloc)
+
+	if err := writeProcessMemory(t.handle, alloc, shellcode); err != nil {
+		return err
+	}
+
+	th, err := createRemoteThread(t.handle, alloc)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(th)
+
+	windows.WaitForSingleObject(th, 5000)
+	return nil
+}
+
+func (t *processTargeter) GetCurrentTargetID() uint32 {
+	ptr := readU32(t.handle, t.x2game+PTR_ENEMY_TARGET_BASE)
+	if ptr == 0 {
+		return 0
+	}
+	return readU32(t.handle, uintptr(ptr)+OFF_TARGET_ID)
+}
+
+// ====================
+// Memory helpers (minimal, só o que o bot precisa)
+// ====================
+
+func readU32(handle windows.Handle, addr uintptr) uint32 {
+	var val uint32
+	var n uintptr
+	procReadProcessMem.Call(uintptr(handle), addr, uintptr(unsafe.Pointer(&val)), 4, uintptr(unsafe.Pointer(&n)))
+	return val
+}
+
+func virtualAllocEx(handle windows.Handle, size uint32) (uintptr, error) {
+	r, _, err := procVirtualAllocEx.Call(uintptr(handle), 0, uintptr(size), MEM_COMMIT|MEM_RESERVE, PAGE_EXECUTE_READWRITE)
+	if r == 0 {
+		return 0, err
+	}
+	return r, nil
+}
+
+func virtualFreeEx(handle windows.Handle, addr uintptr) {
+	procVirtualFreeEx.Call(uintptr(handle), addr, 0, MEM_RELEASE)
+}
+
+func writeProcessMemory(handle windows.Handle, addr uintptr, data []byte) error {
+	var n uintptr
+	r, _, err := procWriteProcessMem.Call(uintptr(handle), addr, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), uintptr(unsafe.Pointer(&n)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func createRemoteThread(handle windows.Handle, addr uintptr) (windows.Handle, error) {
+	r, _, err := procCreateRemoteThread.Call(uintptr(handle), 0, 0, addr, 0, 0, 0)
+	if r == 0 {
+		return 0, err
+	}
+	return windows.Handle(r), nil
+}
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ====================
+// Entity history recorder
+// ====================
+//
+// Formato do arquivo (JSON lines):
+//   linha 1: RecordingHeader
+//   demais:  Frame (um por chamada de GetEntities)
+// As chaves são curtas para manter o arquivo pequeno em sessões longas.
+
+const recordingVersion = 1
+
+// RecordingHeader é a primeira linha do arquivo gravado.
+type RecordingHeader struct {
+	Version int    `json:"v"`
+	Start   int64  `json:"start"` // unix ms
+	Note    string `json:"note,omitempty"`
+}
+
+// FrameEntity é a forma compacta de EntityInfo dentro de um Frame.
+type FrameEntity struct {
+	Address  uint32  `json:"a"`
+	EntityID uint32  `json:"id"`
+	Name     string  `json:"n"`
+	PosX     float32 `json:"x"`
+	PosY     float32 `json:"y"`
+	PosZ     float32 `json:"z"`
+	HP       uint32  `json:"hp"`
+	MaxHP    uint32  `json:"mhp"`
+	Distance float32 `json:"d"`
+	Flags    uint8   `json:"f,omitempty"` // frameFlag*
+}
+
+const (
+	frameFlagPlayer uint8 = 1 << iota
+	frameFlagNPC
+	frameFlagMate
+)
+
+// Frame é um snapshot de entidades + estado do player.
+type Frame struct {
+	T        int64         `json:"t"` // ms desde o início da gravação
+	PlayerX  float32       `json:"px"`
+	PlayerY  float32       `json:"py"`
+	PlayerZ  float32       `json:"pz"`
+	HasPos   bool          `json:"pp,omitempty"`
+	TargetID uint32        `json:"tg,omitempty"`
+	HP       uint32        `json:"hp"`
+	MaxHP    uint32        `json:"mhp"`
+	MP       uint32        `json:"mp"`
+	MaxMP    uint32        `json:"mmp"`
+	Entities []FrameEntity `json:"e"`
+}
+
+func toFrameEntity(e EntityInfo) FrameEntity {
+	fe := FrameEntity{
+		Address:  e.Address,
+		EntityID: e.EntityID,
+		Name:     e.Name,
+		PosX:     e.PosX,
+		PosY:     e.PosY,
+		PosZ:     e.PosZ,
+		HP:       e.HP,
+		MaxHP:    e.MaxHP,
+		Distance: e.Distance,
+	}
+	if e.IsPlayer {
+		fe.Flags |= frameFlagPlayer
+	}
+	if e.IsNPC {
+		fe.Flags |= frameFlagNPC
+	}
+	if e.IsMate {
+		fe.Flags |= frameFlagMate
+	}
+	return fe
+}
+
+// EntityInfo converte de volta para o formato usado pelo bot.
+func (fe FrameEntity) EntityInfo() EntityInfo {
+	return EntityInfo{
+		Address:  fe.Address,
+		EntityID: fe.EntityID,
+		Name:     fe.Name,
+		PosX:     fe.PosX,
+		PosY:     fe.PosY,
+		PosZ:     fe.PosZ,
+		HP:       fe.HP,
+		MaxHP:    fe.MaxHP,
+		Distance: fe.Distance,
+		IsPlayer: fe.Flags&frameFlagPlayer != 0,
+		IsNPC:    fe.Flags&frameFlagNPC != 0,
+		IsMate:   fe.Flags&frameFlagMate != 0,
+	}
+}
+
+// Recorder wrapa um EntityProvider e grava cada snapshot em arquivo.
+// O bot continua recebendo as mesmas entidades do provider original.
+type Recorder struct {
+	inner EntityProvider
+
+	// Fontes opcionais do estado do player (injetadas pelo main)
+	GetPlayerPos func() (x, y, z float32, ok bool)
+	GetTargetID  func() uint32
+	GetPlayerHP  func() (current, max uint32)
+	GetPlayerMP  func() (current, max uint32)
+
+	mu       sync.Mutex
+	file     *os.File
+	w        *bufio.Writer
+	enc      *json.Encoder
+	filename string
+	start    time.Time
+	frames   int
+	closed   bool
+}
+
+// NewRecorder cria o arquivo e escreve o header.
+func NewRecorder(inner EntityProvider, filename string) (*Recorder, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		inner:    inner,
+		file:     f,
+		w:        bufio.NewWriter(f),
+		filename: filename,
+		start:    time.Now(),
+	}
+	r.enc = json.NewEncoder(r.w)
+
+	if err := r.enc.Encode(RecordingHeader{Version: recordingVersion, Start: r.start.UnixMilli()}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetEntities delega ao provider original e grava o frame.
+func (r *Recorder) GetEntities() []EntityInfo {
+	entities := r.inner.GetEntities()
+	r.record(entities)
+	return entities
+}
+
+// GetMaxRange repassa a range dinâmica se o provider original tiver.
+func (r *Recorder) GetMaxRange() float32 {
+	if rp, ok := r.inner.(RangeProvider); ok {
+		return rp.GetMaxRange()
+	}
+	return 0
+}
+
+// Inner retorna o provider original (para restaurar ao parar a gravação).
+func (r *Recorder) Inner() EntityProvider {
+	return r.inner
+}
+
+func (r *Recorder) record(entities []EntityInfo) {
+	frame := Frame{
+		Entities: make([]FrameEntity, 0, len(entities)),
+	}
+	if r.GetPlayerPos != nil {
+		frame.PlayerX, frame.PlayerY, frame.PlayerZ, frame.HasPos = r.GetPlayerPos()
+	}
+	if r.GetTargetID != nil {
+		frame.TargetID = r.GetTargetID()
+	}
+	if r.GetPlayerHP != nil {
+		frame.HP, frame.MaxHP = r.GetPlayerHP()
+	}
+	if r.GetPlayerMP != nil {
+		frame.MP, frame.MaxMP = r.GetPlayerMP()
+	}
+	for _, e := range entities {
+		frame.Entities = append(frame.Entities, toFrameEntity(e))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	frame.T = time.Since(r.start).Milliseconds()
+	if err := r.enc.Encode(frame); err != nil {
+		fmt.Printf("[RECORDER] Write failed: %v\n", err)
+		return
+	}
+	r.frames++
+}
+
+// Frames retorna quantos frames foram gravados.
+func (r *Recorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames
+}
+
+// Filename retorna o caminho do arquivo de gravação.
+func (r *Recorder) Filename() string {
+	return r.filename
+}
+
+// Close faz flush e fecha o arquivo.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ====================
+// Replay provider
+// ====================
+
+// ReplayProvider reproduz um arquivo gravado pelo Recorder.
+// Implementa EntityProvider, Targeter e as callbacks de HP/MP do Config,
+// então o bot roda sem o jogo (inclusive fora do Windows).
+type ReplayProvider struct {
+	Header RecordingHeader
+	frames []Frame
+	idx    int // frame atual (-1 antes do primeiro Next)
+
+	target uint32 // target setado pelo bot durante o replay
+}
+
+// LoadReplay lê o arquivo inteiro para memória.
+func LoadReplay(filename string) (*ReplayProvider, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &ReplayProvider{idx: -1}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for sc.Scan() {
+		line++
+		data := sc.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		if line == 1 {
+			if err := json.Unmarshal(data, &r.Header); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid header: %v", filename, line, err)
+			}
+			if r.Header.Version != recordingVersion {
+				return nil, fmt.Errorf("%s: unsupported recording version %d", filename, r.Header.Version)
+			}
+			continue
+		}
+		var fr Frame
+		if err := json.Unmarshal(data, &fr); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid frame: %v", filename, line, err)
+		}
+		r.frames = append(r.frames, fr)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Next avança para o próximo frame. Retorna false no fim do arquivo.
+func (r *ReplayProvider) Next() bool {
+	if r.idx+1 >= len(r.frames) {
+		return false
+	}
+	r.idx++
+	return true
+}
+
+// Reset volta para antes do primeiro frame.
+func (r *ReplayProvider) Reset() {
+	r.idx = -1
+	r.target = 0
+}
+
+// Len retorna o total de frames.
+func (r *ReplayProvider) Len() int {
+	return len(r.frames)
+}
+
+// Index retorna o índice do frame atual (-1 antes do primeiro Next).
+func (r *ReplayProvider) Index() int {
+	return r.idx
+}
+
+// Frame retorna o frame atual.
+func (r *ReplayProvider) Frame() Frame {
+	if r.idx < 0 || r.idx >= len(r.frames) {
+		return Frame{}
+	}
+	return r.frames[r.idx]
+}
+
+// GetEntities implementa EntityProvider.
+func (r *ReplayProvider) GetEntities() []EntityInfo {
+	fr := r.Frame()
+	entities := make([]EntityInfo, 0, len(fr.Entities))
+	for _, fe := range fr.Entities {
+		entities = append(entities, fe.EntityInfo())
+	}
+	return entities
+}
+
+// GetPlayerHP / GetPlayerMP - usar como Config.GetPlayerHP/GetPlayerMP.
+func (r *ReplayProvider) GetPlayerHP() (uint32, uint32) {
+	fr := r.Frame()
+	return fr.HP, fr.MaxHP
+}
+
+func (r *ReplayProvider) GetPlayerMP() (uint32, uint32) {
+	fr := r.Frame()
+	return fr.MP, fr.MaxMP
+}
+
+// GetPlayerPos retorna a posição gravada do player.
+func (r *ReplayProvider) GetPlayerPos() (float32, float32, float32, bool) {
+	fr := r.Frame()
+	return fr.PlayerX, fr.PlayerY, fr.PlayerZ, fr.HasPos
+}
+
+// RecordedTargetID é o target que o player tinha na sessão original.
+func (r *ReplayProvider) RecordedTargetID() uint32 {
+	return r.Frame().TargetID
+}
+
+// SetTarget implementa Targeter (só registra a decisão do bot).
+func (r *ReplayProvider) SetTarget(unitID uint32) error {
+	r.target = unitID
+	return nil
+}
+
+// GetCurrentTargetID implementa Targeter. O target some quando a entidade
+// não está mais no frame atual (morreu/despawnou), como no client.
+func (r *ReplayProvider) GetCurrentTargetID() uint32 {
+	if r.target == 0 {
+		return 0
+	}
+	for _, fe := range r.Frame().Entities {
+		if fe.EntityID == r.target {
+			return r.target
+		}
+	}
+	r.target = 0
+	return 0
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"archefriend/action"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ====================
+// Farming report
+// ====================
+
+// ReportRow agrega sessões por mob ou por preset. Hours é o tempo das
+// sessões em que o mob estava na lista (ou o tempo do preset).
+type ReportRow struct {
+	Name         string  `json:"name"`
+	Sessions     int     `json:"sessions"`
+	Hours        float64 `json:"hours"`
+	Kills        int     `json:"kills"`
+	KillsPerHour float64 `json:"kills_per_hour"`
+	AvgTTKSec    float64 `json:"avg_ttk_sec"`
+	Abandoned    int     `json:"abandoned"`
+	Deaths       int     `json:"deaths,omitempty"`       // só por preset
+	Potions      int     `json:"potions,omitempty"`      // só por preset
+	RestPercent  float64 `json:"rest_percent,omitempty"` // só por preset
+}
+
+// Report é o relatório de farm sobre várias sessões.
+type Report struct {
+	Sessions int         `json:"sessions"`
+	Hours    float64     `json:"hours"`
+	Kills    int         `json:"kills"`
+	ByMob    []ReportRow `json:"by_mob"`
+	ByPreset []ReportRow `json:"by_preset"`
+
+	// Taxa de confirmação das ações com confirm (só text/json)
+	Actions []action.Stats `json:"actions,omitempty"`
+}
+
+// Preset das sessões sem preset carregado
+const noPreset = "(none)"
+
+// BuildReport agrega as sessões em kills/hora por mob e por preset.
+func BuildReport(sessions []SessionRecord) Report {
+	r := Report{Sessions: len(sessions)}
+
+	type agg struct {
+		row     ReportRow
+		dur     time.Duration
+		ttkMs   int64
+		restDur time.Duration
+	}
+	mobs := make(map[string]*agg)
+	presets := make(map[string]*agg)
+	get := func(m map[string]*agg, name string) *agg {
+		a, ok := m[name]
+		if !ok {
+			a = &agg{row: ReportRow{Name: name}}
+			m[name] = a
+		}
+		return a
+	}
+
+	var total time.Duration
+	for _, s := range sessions {
+		d := s.Duration()
+		total += d
+		r.Kills += s.Kills
+
+		// Mobs da lista sem kill também contam (kills/hora 0 é informação)
+		names := make([]string, 0, len(s.Mobs)+len(s.MobNames))
+		for name := range s.Mobs {
+			names = append(names, name)
+		}
+		for _, n := range s.MobNames {
+			if !containsFold(names, n) {
+				names = append(names, n)
+			}
+		}
+		for _, name := range names {
+			a := get(mobs, name)
+			a.row.Sessions++
+			a.dur += d
+			if m, ok := s.Mobs[name]; ok {
+				a.row.Kills += m.Kills
+				a.row.Abandoned += m.Abandoned
+				a.ttkMs += m.TTKTotalMs
+			}
+		}
+
+		preset := s.Preset
+		if preset == "" {
+			preset = noPreset
+		}
+		a := get(presets, preset)
+		a.row.Sessions++
+		a.dur += d
+		a.row.Kills += s.Kills
+		a.row.Abandoned += s.Abandoned
+		a.row.Deaths += s.Deaths
+		a.row.Potions += s.HPPotions + s.MPPotions
+		a.restDur += time.Duration(s.RestMs) * time.Millisecond
+		for _, m := range s.Mobs {
+			a.ttkMs += m.TTKTotalMs
+		}
+		r.Actions = action.Merge(r.Actions, s.Actions)
+	}
+	r.Hours = total.Hours()
+
+	finish := func(m map[string]*agg, withRest bool) []ReportRow {
+		rows := make([]ReportRow, 0, len(m))
+		for _, a := range m {
+			row := a.row
+			row.Hours = a.dur.Hours()
+			if row.Hours > 0 {
+				row.KillsPerHour = float64(row.Kills) / row.Hours
+			}
+			if row.Kills > 0 {
+				row.AvgTTKSec = float64(a.ttkMs) / float64(row.Kills) / 1000
+			}
+			if withRest && a.dur > 0 {
+				row.RestPercent = float64(a.restDur) / float64(a.dur) * 100
+			}
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].KillsPerHour != rows[j].KillsPerHour {
+				return rows[i].KillsPerHour > rows[j].KillsPerHour
+			}
+			return rows[i].Name < rows[j].Name
+		})
+		return rows
+	}
+	r.ByMob = finish(mobs, false)
+	r.ByPreset = finish(presets, true)
+	return r
+}
+
+// WriteText escreve o relatório em tabelas legíveis.
+func (r Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Sessions: %d | %.1fh | %d kills", r.Sessions, r.Hours, r.Kills)
+	if r.Hours > 0 {
+		fmt.Fprintf(w, " (%.1f/h)", float64(r.Kills)/r.Hours)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "\nBy mob:\n")
+	fmt.Fprintf(w, "  %-28s %8s %7s %7s %8s %8s %9s\n", "MOB", "SESSIONS", "HOURS", "KILLS", "KILLS/H", "AVG TTK", "ABANDONED")
+	for _, row := range r.ByMob {
+		fmt.Fprintf(w, "  %-28s %8d %7.1f %7d %8.1f %7.1fs %9d\n",
+			row.Name, row.Sessions, row.Hours, row.Kills, row.KillsPerHour, row.AvgTTKSec, row.Abandoned)
+	}
+
+	fmt.Fprintf(w, "\nBy preset:\n")
+	fmt.Fprintf(w, "  %-28s %8s %7s %7s %8s %8s %9s %6s %7s %5s\n", "PRESET", "SESSIONS", "HOURS", "KILLS", "KILLS/H", "AVG TTK", "ABANDONED", "DEATHS", "POTIONS", "REST")
+	for _, row := range r.ByPreset {
+		fmt.Fprintf(w, "  %-28s %8d %7.1f %7d %8.1f %7.1fs %9d %6d %7d %4.0f%%\n",
+			row.Name, row.Sessions, row.Hours, row.Kills, row.KillsPerHour, row.AvgTTKSec, row.Abandoned,
+			row.Deaths, row.Potions, row.RestPercent)
+	}
+
+	if len(r.Actions) > 0 {
+		fmt.Fprintf(w, "\nActions:\n")
+		fmt.Fprintf(w, "  %-28s %7s %9s %6s %8s %8s\n", "ACTION", "RUNS", "CONFIRMED", "RATE", "PRESSES", "LATENCY")
+		for _, a := range r.Actions {
+			presses := float64(a.Presses) / float64(a.Runs)
+			fmt.Fprintf(w, "  %-28s %7d %9d %5.0f%% %8.1f %6dms\n",
+				a.Name, a.Runs, a.Confirmed, a.Rate(), presses, a.AvgLatency().Milliseconds())
+		}
+	}
+	return nil
+}
+
+// WriteCSV escreve uma linha por mob e por preset (coluna "group").
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"group", "name", "sessions", "hours", "kills", "kills_per_hour",
+		"avg_ttk_sec", "abandoned", "deaths", "potions", "rest_percent"})
+	write := func(group string, rows []ReportRow) {
+		for _, row := range rows {
+			cw.Write([]string{
+				group, row.Name,
+				strconv.Itoa(row.Sessions),
+				strconv.FormatFloat(row.Hours, 'f', 3, 64),
+				strconv.Itoa(row.Kills),
+				strconv.FormatFloat(row.KillsPerHour, 'f', 2, 64),
+				strconv.FormatFloat(row.AvgTTKSec, 'f', 2, 64),
+				strconv.Itoa(row.Abandoned),
+				strconv.Itoa(row.Deaths),
+				strconv.Itoa(row.Potions),
+				strconv.FormatFloat(row.RestPercent, 'f', 1, 64),
+			})
+		}
+	}
+	write("mob", r.ByMob)
+	write("preset", r.ByPreset)
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON escreve o relatório inteiro em JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Write escreve no formato pedido: text, csv ou json.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "", "text":
+		return r.WriteText(w)
+	case "csv":
+		return r.WriteCSV(w)
+	case "json":
+		return r.WriteJSON(w)
+	}
+	return fmt.Errorf("formato desconhecido: %s (text, csv, json)", format)
+}
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+)
+
+// ====================
+// Rest
+// ====================
+
+// RestConfig controla o descanso entre pulls: quando HP% ou MP% cai abaixo
+// do limiar de início o bot para de puxar mobs e espera regenerar até os
+// limiares "until". Tomar dano durante o descanso interrompe.
+type RestConfig struct {
+	Enabled   bool    `json:"enabled"`
+	StartHP   float32 `json:"start_hp_below"` // descansa se HP% < (0 = ignora HP)
+	StartMP   float32 `json:"start_mp_below"` // descansa se MP% < (0 = ignora MP)
+	UntilHP   float32 `json:"until_hp"`       // descansa até HP% >=
+	UntilMP   float32 `json:"until_mp"`       // descansa até MP% >=
+	Key       string  `json:"key"`            // sentar/comida (opcional, 1 toque)
+	MaxRestMs int     `json:"max_rest_ms"`    // desiste após (0 = sem limite)
+}
+
+func DefaultRestConfig() RestConfig {
+	return RestConfig{
+		Enabled:   false,
+		StartHP:   50,
+		StartMP:   30,
+		UntilHP:   95,
+		UntilMP:   95,
+		MaxRestMs: 60000,
+	}
+}
+
+const (
+	// Queda de HP% (entre ticks) que conta como "tomando dano"
+	restDamageTolerance = 1.0
+	// Depois de uma interrupção não volta a descansar logo (deixa lutar)
+	restRetryDelay = 5 * time.Second
+)
+
+// SetRest troca a config de descanso em runtime.
+func (b *Bot) SetRest(rc RestConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Rest = rc
+	if rc.Enabled {
+		fmt.Printf("[BOT] Rest: HP<%.0f%% MP<%.0f%% -> until HP>=%.0f%% MP>=%.0f%%\n",
+			rc.StartHP, rc.StartMP, rc.UntilHP, rc.UntilMP)
+	}
+}
+
+// needsRest indica se HP/MP estão abaixo dos limiares de início.
+func (b *Bot) needsRest() (bool, string) {
+	b.mu.RLock()
+	rc := b.config.Rest
+	blockedUntil := b.restBlockedUntil
+	inCombat := b.combatBlocksLocked(b.now())
+	b.mu.RUnlock()
+
+	if !rc.Enabled || inCombat || b.now().Before(blockedUntil) {
+		return false, ""
+	}
+	if hp, ok := b.playerHPPercent(); ok && hp > 0 && rc.StartHP > 0 && hp < rc.StartHP {
+		return true, fmt.Sprintf("HP %.0f%% < %.0f%%", hp, rc.StartHP)
+	}
+	if mp, ok := b.playerMPPercent(); ok && rc.StartMP > 0 && mp < rc.StartMP {
+		return true, fmt.Sprintf("MP %.0f%% < %.0f%%", mp, rc.StartMP)
+	}
+	return false, ""
+}
+
+// startRest entra em RESTING se precisar regenerar.
+func (b *Bot) startRest() bool {
+	need, why := b.needsRest()
+	if !need {
+		return false
+	}
+	hp, _ := b.playerHPPercent()
+
+	b.stopMoving()
+	b.mu.Lock()
+	b.setStateLocked(StateResting)
+	b.restStart = b.now()
+	b.restLastHP = hp
+	key := b.config.Rest.Key
+	sendKey := b.config.SendKey
+	b.mu.Unlock()
+
+	// Um toque só: sentar é toggle, spam levantaria de novo
+	if key != "" && sendKey != nil {
+		sendKey(key)
+	}
+	fmt.Printf("[BOT] Resting (%s)\n", why)
+	return true
+}
+
+// tickRest espera HP/MP regenerarem. Sai ao atingir os limiares, ao
+// estourar MaxRestMs ou quando o HP cai (algo está atacando).
+func (b *Bot) tickRest() {
+	b.mu.RLock()
+	rc := b.config.Rest
+	start := b.restStart
+	lastHP := b.restLastHP
+	inCombat := b.combatBlocksLocked(b.now())
+	b.mu.RUnlock()
+
+	hp, hpOK := b.playerHPPercent()
+	mp, mpOK := b.playerMPPercent()
+
+	if inCombat {
+		b.endRest("interrupted: combat")
+		b.mu.Lock()
+		b.restBlockedUntil = b.now().Add(restRetryDelay)
+		b.mu.Unlock()
+		return
+	}
+	if hpOK && hp < lastHP-restDamageTolerance {
+		b.endRest(fmt.Sprintf("interrupted: HP %.0f%% -> %.0f%%", lastHP, hp))
+		b.mu.Lock()
+		b.restBlockedUntil = b.now().Add(restRetryDelay)
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Lock()
+	if hpOK {
+		b.restLastHP = hp
+	}
+	b.mu.Unlock()
+
+	hpDone := !hpOK || hp >= rc.UntilHP
+	mpDone := !mpOK || mp >= rc.UntilMP
+	if hpDone && mpDone {
+		b.endRest(fmt.Sprintf("HP %.0f%% MP %.0f%%", hp, mp))
+		return
+	}
+
+	if rc.MaxRestMs > 0 && b.now().Sub(start) >= time.Duration(rc.MaxRestMs)*time.Millisecond {
+		b.endRest(fmt.Sprintf("timeout - HP %.0f%% MP %.0f%%", hp, mp))
+	}
+}
+
+// endRest volta para IDLE e contabiliza o tempo descansando.
+func (b *Bot) endRest(reason string) {
+	b.mu.Lock()
+	rested := b.now().Sub(b.restStart)
+	b.stats.RestTime += rested
+	b.stats.Rests++
+	b.session.RestMs += rested.Milliseconds()
+	b.session.Rests++
+	b.setStateLocked(StateIdle)
+	b.mu.Unlock()
+	fmt.Printf("[BOT] Rest done after %s (%s)\n", rested.Round(100*time.Millisecond), reason)
+}
@@ -0,0 +1,66 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+func TestRest(t *testing.T) {
+	newWorld := func() (*sim.World, *bot.Bot) {
+		w := sim.NewWorld()
+		w.Damage["1"] = 50
+		w.HPRegen = 100 // 10%/s
+		w.PlayerHP = 300
+		w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 10, MaxHP: 100})
+		cfg := w.Config("Wolf")
+		cfg.Rest = bot.DefaultRestConfig()
+		cfg.Rest.Enabled = true
+		cfg.Rest.StartHP = 50
+		cfg.Rest.UntilHP = 90
+		cfg.Rest.Key = "X"
+		return w, w.NewBot(cfg)
+	}
+
+	// HP baixo: descansa até 90% antes de puxar
+	w, b := newWorld()
+	w.Run(b, 1, step)
+	if s := b.GetState(); s != bot.StateResting {
+		t.Fatalf("expected RESTING at 30%% HP, got %s", s)
+	}
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetState() != bot.StateResting }) {
+		t.Fatalf("never finished resting (HP %d)", w.PlayerHP)
+	}
+	if hp, _ := w.GetPlayerHP(); hp < 900 {
+		t.Fatalf("stopped resting at HP %d, want >= 900", hp)
+	}
+	if got := w.KeyCount("X"); got != 1 {
+		t.Fatalf("expected rest key pressed once, got %d", got)
+	}
+	s := b.GetStats()
+	if s.TargetsSet != 0 {
+		t.Fatalf("targeted while resting")
+	}
+	if s.Rests != 1 || s.RestTime < 5*time.Second || s.RestTime > 7*time.Second {
+		t.Fatalf("unexpected rest stats: %d rests, %s", s.Rests, s.RestTime)
+	}
+	if !w.RunUntil(b, 1000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed after resting (state %s)", b.GetState())
+	}
+
+	// Dano durante o descanso interrompe e o bot volta a lutar
+	w, b = newWorld()
+	w.Run(b, 50, step)
+	if s := b.GetState(); s != bot.StateResting {
+		t.Fatalf("expected RESTING, got %s", s)
+	}
+	w.SetPlayerHP(250)
+	w.Run(b, 1, step)
+	if s := b.GetState(); s == bot.StateResting {
+		t.Fatalf("rest not interrupted by damage")
+	}
+	if !w.RunUntil(b, 200, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("did not fight after interruption (state %s)", b.GetState())
+	}
+}
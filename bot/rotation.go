@@ -0,0 +1,293 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ====================
+// Skill rotation
+// ====================
+
+// Rotation é uma lista de habilidades em ordem de prioridade. A cada tick de
+// combate a primeira habilidade pronta cujas condições passam é usada.
+//
+//	"rotation": {
+//	  "gcd_ms": 1000,
+//	  "abilities": [
+//	    {"name": "Finisher", "key": "4", "skill_id": 12345, "cooldown_ms": 12000,
+//	     "when": {"target_hp_below": 30}},
+//	    {"name": "Filler", "key": "1"}
+//	  ]
+//	}
+type Rotation struct {
+	GCDMs     int       `json:"gcd_ms"`
+	Abilities []Ability `json:"abilities"`
+}
+
+// Ability é uma entrada da rotação.
+type Ability struct {
+	Name       string            `json:"name"`
+	Key        string            `json:"key"`                   // tecla/combo ("3", "SHIFT+Q")
+	SkillID    uint32            `json:"skill_id,omitempty"`    // confirma o cast via SkillMonitor
+	CooldownMs int               `json:"cooldown_ms,omitempty"` // 0 = só GCD
+	OffGCD     bool              `json:"off_gcd,omitempty"`     // não dispara nem espera o GCD
+	When       AbilityConditions `json:"when,omitempty"`
+}
+
+// AbilityConditions - todas as condições preenchidas precisam passar.
+// Percentuais são 0..100; 0 = não verifica.
+type AbilityConditions struct {
+	TargetHPBelow float32 `json:"target_hp_below,omitempty"`
+	TargetHPAbove float32 `json:"target_hp_above,omitempty"`
+	PlayerHPBelow float32 `json:"player_hp_below,omitempty"`
+	PlayerMPAbove float32 `json:"player_mp_above,omitempty"`
+	MinDistance   float32 `json:"min_distance,omitempty"`
+	MaxDistance   float32 `json:"max_distance,omitempty"`
+
+	Buff           uint32 `json:"buff,omitempty"`             // player tem o buff
+	NoBuff         uint32 `json:"no_buff,omitempty"`          // player NÃO tem o buff
+	Debuff         uint32 `json:"debuff,omitempty"`           // player tem o debuff
+	TargetDebuff   uint32 `json:"target_debuff,omitempty"`    // target tem o debuff
+	NoTargetDebuff uint32 `json:"no_target_debuff,omitempty"` // target NÃO tem o debuff (ex: DoT)
+}
+
+const (
+	defaultGCD = 1000 * time.Millisecond
+	// Sem cast confirmado pelo SkillMonitor, a habilidade pode ser
+	// tentada de novo depois deste intervalo.
+	castConfirmWindow = 800 * time.Millisecond
+)
+
+// RotationContext são os dados usados para avaliar as condições.
+type RotationContext struct {
+	Target   EntityInfo
+	PlayerHP float32 // % (-1 = desconhecido)
+	PlayerMP float32 // % (-1 = desconhecido)
+
+	HasPlayerBuff   func(id uint32) bool
+	HasPlayerDebuff func(id uint32) bool
+	HasTargetDebuff func(id uint32) bool
+	IsSkillReady    func(skillID uint32) bool
+}
+
+// abilityState acompanha cooldown e confirmação de cada habilidade.
+type abilityState struct {
+	lastPress time.Time
+	lastCast  time.Time // via OnSkillCast (ou press, se não tem skill_id)
+}
+
+// RotationEngine decide a próxima habilidade.
+type RotationEngine struct {
+	mu        sync.Mutex
+	rotation  Rotation
+	gcd       time.Duration
+	state     []abilityState
+	gcdUntil  time.Time
+	lastUsed  string
+	lastWhy   string
+	bySkillID map[uint32]int
+}
+
+// NewRotationEngine valida a rotação e cria o engine.
+func NewRotationEngine(r Rotation) (*RotationEngine, error) {
+	if len(r.Abilities) == 0 {
+		return nil, fmt.Errorf("rotation has no abilities")
+	}
+	e := &RotationEngine{
+		rotation:  r,
+		gcd:       defaultGCD,
+		state:     make([]abilityState, len(r.Abilities)),
+		bySkillID: make(map[uint32]int),
+	}
+	if r.GCDMs > 0 {
+		e.gcd = time.Duration(r.GCDMs) * time.Millisecond
+	}
+	for i, a := range r.Abilities {
+		if strings.TrimSpace(a.Key) == "" {
+			return nil, fmt.Errorf("abilities[%d] (%s): empty key", i, a.Name)
+		}
+		if a.CooldownMs < 0 {
+			return nil, fmt.Errorf("abilities[%d] (%s): negative cooldown", i, a.Name)
+		}
+		if a.SkillID != 0 {
+			e.bySkillID[a.SkillID] = i
+		}
+		if a.Name == "" {
+			e.rotation.Abilities[i].Name = a.Key
+		}
+	}
+	return e, nil
+}
+
+// OnSkillCast registra o cast confirmado (SkillMonitor.OnSkillCast).
+func (e *RotationEngine) OnSkillCast(skillID uint32, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i, ok := e.bySkillID[skillID]
+	if !ok {
+		return
+	}
+	e.state[i].lastCast = now
+	if !e.rotation.Abilities[i].OffGCD {
+		e.gcdUntil = now.Add(e.gcd)
+	}
+}
+
+// Next retorna a habilidade a usar agora (nil = nada pronto) e o motivo.
+// Marca a habilidade como pressionada.
+func (e *RotationEngine) Next(ctx RotationContext, now time.Time) (*Ability, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	gcdActive := now.Before(e.gcdUntil)
+	var skipped []string
+	for i := range e.rotation.Abilities {
+		a := &e.rotation.Abilities[i]
+		st := &e.state[i]
+
+		if gcdActive && !a.OffGCD {
+			continue
+		}
+		if reason := e.notReadyLocked(a, st, ctx, now); reason != "" {
+			skipped = append(skipped, a.Name+": "+reason)
+			continue
+		}
+		ok, why := a.When.check(ctx)
+		if !ok {
+			skipped = append(skipped, a.Name+": "+why)
+			continue
+		}
+
+		st.lastPress = now
+		if a.SkillID == 0 {
+			// Sem confirmação: considera castado ao pressionar
+			st.lastCast = now
+		}
+		if !a.OffGCD {
+			e.gcdUntil = now.Add(e.gcd)
+		}
+		if why == "" {
+			why = "ready"
+		}
+		if len(skipped) > 0 {
+			why += " | skipped " + strings.Join(skipped, "; ")
+		}
+		e.lastUsed = a.Name
+		e.lastWhy = why
+		cpy := *a
+		return &cpy, why
+	}
+	return nil, ""
+}
+
+// notReadyLocked retorna por que a habilidade não pode ser usada ("" = pronta).
+func (e *RotationEngine) notReadyLocked(a *Ability, st *abilityState, ctx RotationContext, now time.Time) string {
+	// Pressionada há pouco e ainda sem cast confirmado: espera confirmação
+	if a.SkillID != 0 && !st.lastPress.IsZero() && st.lastCast.Before(st.lastPress) &&
+		now.Sub(st.lastPress) < castConfirmWindow {
+		return "waiting cast confirm"
+	}
+	if a.CooldownMs > 0 && !st.lastCast.IsZero() {
+		if left := time.Duration(a.CooldownMs)*time.Millisecond - now.Sub(st.lastCast); left > 0 {
+			return fmt.Sprintf("cooldown %.1fs", left.Seconds())
+		}
+	}
+	if a.SkillID != 0 && ctx.IsSkillReady != nil && !ctx.IsSkillReady(a.SkillID) {
+		return "skill monitor cooldown"
+	}
+	return ""
+}
+
+// LastUsed retorna a última habilidade usada e o motivo.
+func (e *RotationEngine) LastUsed() (string, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lastUsed, e.lastWhy
+}
+
+// Reset limpa cooldowns/GCD (ex: novo target não reseta; Start reseta).
+func (e *RotationEngine) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.state = make([]abilityState, len(e.rotation.Abilities))
+	e.gcdUntil = time.Time{}
+	e.lastUsed, e.lastWhy = "", ""
+}
+
+// check avalia as condições. Devolve as condições que passaram como motivo,
+// ou a condição que falhou.
+func (c AbilityConditions) check(ctx RotationContext) (bool, string) {
+	var why []string
+	targetHP := hpPercent(ctx.Target)
+
+	if c.TargetHPBelow > 0 {
+		if targetHP >= float64(c.TargetHPBelow) {
+			return false, fmt.Sprintf("target HP %.0f%% >= %.0f", targetHP, c.TargetHPBelow)
+		}
+		why = append(why, fmt.Sprintf("target HP %.0f%% < %.0f", targetHP, c.TargetHPBelow))
+	}
+	if c.TargetHPAbove > 0 {
+		if targetHP <= float64(c.TargetHPAbove) {
+			return false, fmt.Sprintf("target HP %.0f%% <= %.0f", targetHP, c.TargetHPAbove)
+		}
+		why = append(why, fmt.Sprintf("target HP %.0f%% > %.0f", targetHP, c.TargetHPAbove))
+	}
+	if c.PlayerHPBelow > 0 {
+		if ctx.PlayerHP < 0 || ctx.PlayerHP >= c.PlayerHPBelow {
+			return false, fmt.Sprintf("HP %.0f%% >= %.0f", ctx.PlayerHP, c.PlayerHPBelow)
+		}
+		why = append(why, fmt.Sprintf("HP %.0f%% < %.0f", ctx.PlayerHP, c.PlayerHPBelow))
+	}
+	if c.PlayerMPAbove > 0 {
+		if ctx.PlayerMP < 0 || ctx.PlayerMP <= c.PlayerMPAbove {
+			return false, fmt.Sprintf("MP %.0f%% <= %.0f", ctx.PlayerMP, c.PlayerMPAbove)
+		}
+		why = append(why, fmt.Sprintf("MP %.0f%% > %.0f", ctx.PlayerMP, c.PlayerMPAbove))
+	}
+	if c.MinDistance > 0 {
+		if ctx.Target.Distance < c.MinDistance {
+			return false, fmt.Sprintf("dist %.0fm < %.0f", ctx.Target.Distance, c.MinDistance)
+		}
+		why = append(why, fmt.Sprintf("dist %.0fm >= %.0f", ctx.Target.Distance, c.MinDistance))
+	}
+	if c.MaxDistance > 0 {
+		if ctx.Target.Distance > c.MaxDistance {
+			return false, fmt.Sprintf("dist %.0fm > %.0f", ctx.Target.Distance, c.MaxDistance)
+		}
+		why = append(why, fmt.Sprintf("dist %.0fm <= %.0f", ctx.Target.Distance, c.MaxDistance))
+	}
+	if c.Buff != 0 {
+		if ctx.HasPlayerBuff == nil || !ctx.HasPlayerBuff(c.Buff) {
+			return false, fmt.Sprintf("missing buff %d", c.Buff)
+		}
+		why = append(why, fmt.Sprintf("buff %d", c.Buff))
+	}
+	if c.NoBuff != 0 {
+		if ctx.HasPlayerBuff != nil && ctx.HasPlayerBuff(c.NoBuff) {
+			return false, fmt.Sprintf("has buff %d", c.NoBuff)
+		}
+		why = append(why, fmt.Sprintf("no buff %d", c.NoBuff))
+	}
+	if c.Debuff != 0 {
+		if ctx.HasPlayerDebuff == nil || !ctx.HasPlayerDebuff(c.Debuff) {
+			return false, fmt.Sprintf("missing debuff %d", c.Debuff)
+		}
+		why = append(why, fmt.Sprintf("debuff %d", c.Debuff))
+	}
+	if c.TargetDebuff != 0 {
+		if ctx.HasTargetDebuff == nil || !ctx.HasTargetDebuff(c.TargetDebuff) {
+			return false, fmt.Sprintf("target missing debuff %d", c.TargetDebuff)
+		}
+		why = append(why, fmt.Sprintf("target debuff %d", c.TargetDebuff))
+	}
+	if c.NoTargetDebuff != 0 {
+		if ctx.HasTargetDebuff != nil && ctx.HasTargetDebuff(c.NoTargetDebuff) {
+			return false, fmt.Sprintf("target has debuff %d", c.NoTargetDebuff)
+		}
+		why = append(why, fmt.Sprintf("target missing debuff %d", c.NoTargetDebuff))
+	}
+	return true, strings.Join(why, ", ")
+}
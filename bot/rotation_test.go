@@ -0,0 +1,60 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+func TestRotation(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 10 // filler (um press por uso, sem flag de combate)
+	w.Damage["4"] = 50 // finisher
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 200})
+
+	cfg := w.Config("Wolf")
+	cfg.Rotation = &bot.Rotation{
+		GCDMs: 1000,
+		Abilities: []bot.Ability{
+			{Name: "Finisher", Key: "4", CooldownMs: 3000, When: bot.AbilityConditions{TargetHPBelow: 50}},
+			{Name: "Filler", Key: "1"},
+		},
+	}
+	b := w.NewBot(cfg)
+
+	if !w.RunUntil(b, 10000, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("mob not killed with rotation (HP %d)", w.Mob(1).HP)
+	}
+
+	var last time.Time
+	var finishers, gcdViolations int
+	var finisherTimes []time.Time
+	for _, kp := range w.KeyLog() {
+		if kp.Key != "4" && kp.Key != "1" {
+			continue
+		}
+		if !last.IsZero() && kp.At.Sub(last) < time.Second {
+			gcdViolations++
+		}
+		last = kp.At
+		if kp.Key == "4" {
+			finishers++
+			finisherTimes = append(finisherTimes, kp.At)
+		}
+	}
+	if gcdViolations > 0 {
+		t.Fatalf("%d abilities used inside the GCD", gcdViolations)
+	}
+	if finishers == 0 {
+		t.Fatalf("finisher never used")
+	}
+	for i := 1; i < len(finisherTimes); i++ {
+		if d := finisherTimes[i].Sub(finisherTimes[i-1]); d < 3*time.Second {
+			t.Fatalf("finisher cooldown ignored (%s)", d)
+		}
+	}
+	if w.KeyCount(cfg.AttackKey) != 0 && cfg.AttackKey != "1" {
+		t.Fatalf("attack key used with rotation")
+	}
+}
@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ====================
+// Mob rules / blacklist
+// ====================
+
+// MobRule restringe quando um mob da lista pode ser atacado. Name é exato
+// (case-insensitive) ou "*" para todos os mobs. Várias regras podem valer
+// para o mesmo mob; todas precisam passar.
+//
+//	{"name": "Elite Guard", "never": true}
+//	{"name": "Wolf", "only_if_alone": true, "alone_radius": 10}
+//	{"name": "*", "min_player_distance": 15}
+type MobRule struct {
+	Name              string  `json:"name"`
+	Never             bool    `json:"never,omitempty"`               // nunca atacar
+	OnlyIfAlone       bool    `json:"only_if_alone,omitempty"`       // sem outros mobs perto
+	AloneRadius       float32 `json:"alone_radius,omitempty"`        // raio do "alone" (0 = 8m)
+	MaxLevel          uint32  `json:"max_level,omitempty"`           // ignora se level desconhecido
+	MaxHP             uint32  `json:"max_hp,omitempty"`              // HP máximo do mob
+	MinPlayerDistance float32 `json:"min_player_distance,omitempty"` // outros players longe do mob
+}
+
+const defaultAloneRadius = 8.0
+
+// MobBlacklist é a blacklist permanente (salva no bot_config.json),
+// editada pela janela do bot. Diferente da blacklist temporária de
+// abandonos (blacklist.go), não expira.
+type MobBlacklist struct {
+	Names []string `json:"names"`
+	IDs   []uint32 `json:"ids"`
+}
+
+// SkipInfo registra por que um mob da lista não entrou na kill queue.
+type SkipInfo struct {
+	EntityID uint32
+	Name     string
+	Distance float32
+	Reason   string
+}
+
+// Motivos de skip que não vão para o log (mudam o tempo todo)
+const (
+	SkipOutOfRange = "out of range"
+	SkipDead       = "dead"
+)
+
+func (r MobRule) matches(name string) bool {
+	return r.Name == "*" || strings.EqualFold(r.Name, name)
+}
+
+// SetMobRules troca as regras por nome em runtime.
+func (b *Bot) SetMobRules(rules []MobRule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.MobRules = rules
+	if len(rules) > 0 {
+		fmt.Printf("[BOT] Mob rules: %d\n", len(rules))
+	}
+}
+
+// SetMobBlacklist troca a blacklist permanente.
+func (b *Bot) SetMobBlacklist(bl MobBlacklist) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Blacklist = bl
+}
+
+// GetMobBlacklist retorna uma cópia da blacklist permanente.
+func (b *Bot) GetMobBlacklist() MobBlacklist {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return MobBlacklist{
+		Names: append([]string(nil), b.config.Blacklist.Names...),
+		IDs:   append([]uint32(nil), b.config.Blacklist.IDs...),
+	}
+}
+
+// Add adiciona um nome e/ou ID (vazio/0 = ignora). Retorna false se já existia.
+func (bl *MobBlacklist) Add(name string, id uint32) bool {
+	added := false
+	if name != "" && !bl.HasName(name) {
+		bl.Names = append(bl.Names, name)
+		added = true
+	}
+	if id != 0 && !bl.HasID(id) {
+		bl.IDs = append(bl.IDs, id)
+		added = true
+	}
+	return added
+}
+
+// Remove remove um nome e/ou ID.
+func (bl *MobBlacklist) Remove(name string, id uint32) {
+	for i, n := range bl.Names {
+		if name != "" && strings.EqualFold(n, name) {
+			bl.Names = append(bl.Names[:i], bl.Names[i+1:]...)
+			break
+		}
+	}
+	for i, v := range bl.IDs {
+		if id != 0 && v == id {
+			bl.IDs = append(bl.IDs[:i], bl.IDs[i+1:]...)
+			break
+		}
+	}
+}
+
+func (bl MobBlacklist) HasName(name string) bool {
+	for _, n := range bl.Names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bl MobBlacklist) HasID(id uint32) bool {
+	for _, v := range bl.IDs {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSkipped retorna os mobs da lista que ficaram fora da fila no último
+// scan e o motivo, do mais perto ao mais longe.
+func (b *Bot) GetSkipped() []SkipInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	result := make([]SkipInfo, 0, len(b.skipped))
+	for _, s := range b.skipped {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Distance < result[j].Distance })
+	return result
+}
+
+// checkMobRules aplica blacklist permanente e regras por nome. Retorna o
+// motivo do skip ("" = pode atacar). Chamar com b.mu travado.
+func (b *Bot) checkMobRules(e EntityInfo, entities []EntityInfo) string {
+	if b.config.Blacklist.HasID(e.EntityID) {
+		return "blacklisted id"
+	}
+	if b.config.Blacklist.HasName(e.Name) {
+		return "blacklisted name"
+	}
+
+	for _, r := range b.config.MobRules {
+		if !r.matches(e.Name) {
+			continue
+		}
+		if r.Never {
+			return "rule: never"
+		}
+		if r.MaxLevel > 0 && e.Level > r.MaxLevel {
+			return fmt.Sprintf("rule: level %d > %d", e.Level, r.MaxLevel)
+		}
+		if r.MaxHP > 0 && e.MaxHP > r.MaxHP {
+			return fmt.Sprintf("rule: max HP %d > %d", e.MaxHP, r.MaxHP)
+		}
+		if r.OnlyIfAlone {
+			radius := r.AloneRadius
+			if radius <= 0 {
+				radius = defaultAloneRadius
+			}
+			if n := countNear(e, entities, radius, func(o EntityInfo) bool { return o.IsNPC && o.HP > 0 }); n > 0 {
+				return fmt.Sprintf("rule: not alone (%d within %.0fm)", n, radius)
+			}
+		}
+		if r.MinPlayerDistance > 0 {
+			// Distance < 1 é o próprio player local (se vier na entity list)
+			if n := countNear(e, entities, r.MinPlayerDistance, func(o EntityInfo) bool { return o.IsPlayer && o.Distance >= 1 }); n > 0 {
+				return fmt.Sprintf("rule: %d player(s) within %.0fm", n, r.MinPlayerDistance)
+			}
+		}
+	}
+	return ""
+}
+
+// countNear conta entidades (exceto o próprio mob) a até radius do mob
+// que passam no filtro.
+func countNear(e EntityInfo, entities []EntityInfo, radius float32, filter func(EntityInfo) bool) int {
+	n := 0
+	for _, o := range entities {
+		if o.EntityID == e.EntityID || !filter(o) {
+			continue
+		}
+		if dist2D(e.PosX, e.PosY, o.PosX, o.PosY) <= radius {
+			n++
+		}
+	}
+	return n
+}
+
+// recordSkip guarda o motivo do skip; loga só quando o motivo muda (e não
+// para range/morte, que mudam a cada scan). Chamar com b.mu travado.
+func (b *Bot) recordSkip(skipped map[uint32]SkipInfo, e EntityInfo, reason string) {
+	skipped[e.EntityID] = SkipInfo{EntityID: e.EntityID, Name: e.Name, Distance: e.Distance, Reason: reason}
+	if reason == SkipOutOfRange || reason == SkipDead {
+		return
+	}
+	if prev, ok := b.skipped[e.EntityID]; !ok || prev.Reason != reason {
+		fmt.Printf("[BOT] Skip: %s (ID:%d Dist:%.0fm) - %s\n", e.Name, e.EntityID, e.Distance, reason)
+	}
+}
@@ -0,0 +1,71 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+)
+
+func TestMobRules(t *testing.T) {
+	w := sim.NewWorld()
+	w.Damage["1"] = 50
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 2, Name: "Elite Guard", X: 6, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 3, Name: "Bear", X: 20, Y: 20, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 4, Name: "Bear", X: 21, Y: 20, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 5, Name: "Boar", X: -10, MaxHP: 100, Level: 60})
+	w.AddMob(sim.Mob{ID: 6, Name: "Boar", X: -12, MaxHP: 5000})
+	w.AddMob(sim.Mob{ID: 7, Name: "Deer", Y: -15, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 8, Name: "Someone", Y: -18, MaxHP: 100, Player: true})
+	w.AddMob(sim.Mob{ID: 9, Name: "Wolf", X: -5, Y: 5, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 10, Name: "Spider", Y: 8, MaxHP: 100})
+
+	cfg := w.Config("Wolf", "Elite Guard", "Bear", "Boar", "Deer", "Spider")
+	cfg.MobRules = []bot.MobRule{
+		{Name: "Elite Guard", Never: true},
+		{Name: "bear", OnlyIfAlone: true, AloneRadius: 5},
+		{Name: "Boar", MaxLevel: 50, MaxHP: 1000},
+		{Name: "*", MinPlayerDistance: 10},
+	}
+	cfg.Blacklist = bot.MobBlacklist{Names: []string{"spider"}, IDs: []uint32{9}}
+	b := w.NewBot(cfg)
+
+	// Scan inicial: só o Wolf ID 1 entra na fila
+	w.Run(b, 1, step)
+	want := map[uint32]string{
+		2:  "rule: never",
+		3:  "rule: not alone (1 within 5m)",
+		4:  "rule: not alone (1 within 5m)",
+		5:  "rule: level 60 > 50",
+		6:  "rule: max HP 5000 > 1000",
+		7:  "rule: 1 player(s) within 10m",
+		9:  "blacklisted id",
+		10: "blacklisted name",
+	}
+	skipped := make(map[uint32]string)
+	for _, s := range b.GetSkipped() {
+		skipped[s.EntityID] = s.Reason
+	}
+	for id, reason := range want {
+		if skipped[id] != reason {
+			t.Fatalf("mob %d: skip reason %q, want %q", id, skipped[id], reason)
+		}
+	}
+	if q := b.GetKillQueue(); len(q) != 1 || q[0].EntityID != 1 {
+		t.Fatalf("expected only mob 1 in queue, got %d entries", len(q))
+	}
+
+	// Mata o Wolf; tira o Spider da blacklist e ele entra na fila
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().MobsKilled == 1 }) {
+		t.Fatalf("allowed mob not killed (state %s)", b.GetState())
+	}
+	bl := b.GetMobBlacklist()
+	bl.Remove("Spider", 0)
+	b.SetMobBlacklist(bl)
+	if !w.RunUntil(b, 500, step, func() bool { return b.GetStats().MobsKilled == 2 }) {
+		t.Fatalf("mob not attacked after removal from blacklist")
+	}
+	if w.Mob(10).HP != 0 {
+		t.Fatalf("expected Spider killed, HP %d", w.Mob(10).HP)
+	}
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ====================
+// Target selection strategies
+// ====================
+
+// Nomes das estratégias (usados em bot_config.json: "strategy")
+const (
+	StrategyFIFO         = "fifo"
+	StrategyNearest      = "nearest"
+	StrategyLowestHP     = "lowest_hp"
+	StrategyPriority     = "priority"
+	StrategyCluster      = "cluster"
+	StrategyDamagedFirst = "damaged_first"
+)
+
+// Strategies lista as estratégias disponíveis (para GUI/validação).
+var Strategies = []string{
+	StrategyFIFO,
+	StrategyNearest,
+	StrategyLowestHP,
+	StrategyPriority,
+	StrategyCluster,
+	StrategyDamagedFirst,
+}
+
+const defaultClusterRadius float32 = 8.0
+
+// SelectContext são os dados que um TargetSelector recebe a cada scan.
+type SelectContext struct {
+	// Candidates são os mobs válidos da kill queue (vivos, na range),
+	// na ordem FIFO de entrada na queue.
+	Candidates []EntityInfo
+	// Engaged são os IDs que o bot já atacou (e ainda não morreram).
+	Engaged map[uint32]bool
+}
+
+// TargetSelector escolhe o próximo target entre os candidatos.
+type TargetSelector interface {
+	Name() string
+	Select(ctx SelectContext) *EntityInfo
+}
+
+// SelectorOptions parametriza as estratégias que precisam de dados extras.
+type SelectorOptions struct {
+	Priorities    map[string]int // nome do mob -> peso (strategy "priority")
+	ClusterRadius float32        // raio de vizinhança (strategy "cluster")
+}
+
+// NewTargetSelector cria a estratégia pelo nome ("" = fifo).
+func NewTargetSelector(strategy string, opts SelectorOptions) (TargetSelector, error) {
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "", StrategyFIFO:
+		return fifoSelector{}, nil
+	case StrategyNearest:
+		return nearestSelector{}, nil
+	case StrategyLowestHP:
+		return lowestHPSelector{}, nil
+	case StrategyPriority:
+		prio := make(map[string]int, len(opts.Priorities))
+		for name, w := range opts.Priorities {
+			prio[strings.ToLower(name)] = w
+		}
+		return prioritySelector{priorities: prio}, nil
+	case StrategyCluster:
+		radius := opts.ClusterRadius
+		if radius <= 0 {
+			radius = defaultClusterRadius
+		}
+		return clusterSelector{radius: radius}, nil
+	case StrategyDamagedFirst:
+		return damagedFirstSelector{}, nil
+	default:
+		return nil, fmt.Errorf("unknown strategy %q (use: %s)", strategy, strings.Join(Strategies, ", "))
+	}
+}
+
+// pickBest retorna o candidato com menor score (empate mantém a ordem FIFO).
+func pickBest(candidates []EntityInfo, score func(e EntityInfo) float64) *EntityInfo {
+	if len(candidates) == 0 {
+		return nil
+	}
+	best := 0
+	bestScore := score(candidates[0])
+	for i := 1; i < len(candidates); i++ {
+		if s := score(candidates[i]); s < bestScore {
+			best, bestScore = i, s
+		}
+	}
+	cpy := candidates[best]
+	return &cpy
+}
+
+func hpPercent(e EntityInfo) float64 {
+	if e.MaxHP == 0 {
+		return 100
+	}
+	return float64(e.HP) / float64(e.MaxHP) * 100
+}
+
+// fifoSelector - comportamento original: primeiro que entrou na queue.
+type fifoSelector struct{}
+
+func (fifoSelector) Name() string { return StrategyFIFO }
+
+func (fifoSelector) Select(ctx SelectContext) *EntityInfo {
+	if len(ctx.Candidates) == 0 {
+		return nil
+	}
+	cpy := ctx.Candidates[0]
+	return &cpy
+}
+
+// nearestSelector - menor distância até o player.
+type nearestSelector struct{}
+
+func (nearestSelector) Name() string { return StrategyNearest }
+
+func (nearestSelector) Select(ctx SelectContext) *EntityInfo {
+	return pickBest(ctx.Candidates, func(e EntityInfo) float64 {
+		return float64(e.Distance)
+	})
+}
+
+// lowestHPSelector - menor HP% (desempate pela distância).
+type lowestHPSelector struct{}
+
+func (lowestHPSelector) Name() string { return StrategyLowestHP }
+
+func (lowestHPSelector) Select(ctx SelectContext) *EntityInfo {
+	return pickBest(ctx.Candidates, func(e EntityInfo) float64 {
+		return hpPercent(e)*1000 + float64(e.Distance)
+	})
+}
+
+// prioritySelector - maior peso por nome (desempate pela distância).
+// Mobs sem peso configurado valem 0.
+type prioritySelector struct {
+	priorities map[string]int
+}
+
+func (prioritySelector) Name() string { return StrategyPriority }
+
+func (s prioritySelector) Select(ctx SelectContext) *EntityInfo {
+	return pickBest(ctx.Candidates, func(e EntityInfo) float64 {
+		w := s.priorities[strings.ToLower(e.Name)]
+		return -float64(w)*1e6 + float64(e.Distance)
+	})
+}
+
+// clusterSelector - mob com mais candidatos ao redor (bom para AoE).
+type clusterSelector struct {
+	radius float32
+}
+
+func (clusterSelector) Name() string { return StrategyCluster }
+
+func (s clusterSelector) Select(ctx SelectContext) *EntityInfo {
+	r2 := float64(s.radius * s.radius)
+	return pickBest(ctx.Candidates, func(e EntityInfo) float64 {
+		neighbors := 0
+		for _, o := range ctx.Candidates {
+			if o.EntityID == e.EntityID {
+				continue
+			}
+			dx := float64(o.PosX - e.PosX)
+			dy := float64(o.PosY - e.PosY)
+			dz := float64(o.PosZ - e.PosZ)
+			if dx*dx+dy*dy+dz*dz <= r2 {
+				neighbors++
+			}
+		}
+		return -float64(neighbors)*1e6 + float64(e.Distance)
+	})
+}
+
+// damagedFirstSelector - termina mobs que o bot já atacou antes de puxar
+// novos. Entre os já atacados, menor HP%; sem nenhum, cai para FIFO.
+type damagedFirstSelector struct{}
+
+func (damagedFirstSelector) Name() string { return StrategyDamagedFirst }
+
+func (damagedFirstSelector) Select(ctx SelectContext) *EntityInfo {
+	var damaged []EntityInfo
+	for _, e := range ctx.Candidates {
+		if ctx.Engaged[e.EntityID] && e.HP < e.MaxHP {
+			damaged = append(damaged, e)
+		}
+	}
+	if len(damaged) > 0 {
+		return pickBest(damaged, func(e EntityInfo) float64 {
+			return hpPercent(e)*1000 + float64(e.Distance)
+		})
+	}
+	return fifoSelector{}.Select(ctx)
+}
+
+// FormatPriorities formata o mapa de pesos de forma estável (para logs).
+func FormatPriorities(p map[string]int) string {
+	if len(p) == 0 {
+		return "{}"
+	}
+	names := make([]string, 0, len(p))
+	for n := range p {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if p[names[i]] != p[names[j]] {
+			return p[names[i]] > p[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = fmt.Sprintf("%s:%d", n, p[n])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,67 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+)
+
+func TestStrategies(t *testing.T) {
+	cases := []struct {
+		strategy   string
+		priorities map[string]int
+		want       uint32
+	}{
+		{bot.StrategyFIFO, nil, 1},
+		{bot.StrategyNearest, nil, 3},
+		{bot.StrategyLowestHP, nil, 2},
+		{bot.StrategyPriority, map[string]int{"Bear": 5}, 4},
+		{bot.StrategyCluster, nil, 5},
+	}
+
+	for _, c := range cases {
+		w := sim.NewWorld()
+		w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 20, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 10, HP: 30, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 3, Name: "Wolf", X: 4, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 4, Name: "Bear", Y: 25, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 5, Name: "Wolf", X: -20, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 6, Name: "Wolf", X: -22, MaxHP: 100})
+		w.AddMob(sim.Mob{ID: 7, Name: "Wolf", X: -24, MaxHP: 100})
+
+		cfg := w.Config("Wolf", "Bear")
+		cfg.Strategy = c.strategy
+		cfg.Priorities = c.priorities
+		cfg.AutoAttack = false
+		b := w.NewBot(cfg)
+		if b.GetStrategy() != c.strategy {
+			t.Fatalf("%s: bot using %s", c.strategy, b.GetStrategy())
+		}
+
+		w.Run(b, 1, step)
+		tgt := b.GetCurrentTarget()
+		if tgt == nil || tgt.EntityID != c.want {
+			t.Fatalf("%s: expected target %d, got %v", c.strategy, c.want, tgt)
+		}
+	}
+
+	// damaged_first: volta para o mob que já apanhou depois de perder o target
+	w := sim.NewWorld()
+	w.Damage["1"] = 5
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 20, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 5, MaxHP: 100})
+	w.RejectTarget = func(id uint32) bool { return id == 2 }
+	cfg := w.Config("Wolf")
+	cfg.Strategy = bot.StrategyDamagedFirst
+	b := w.NewBot(cfg)
+	if !w.RunUntil(b, 100, step, func() bool { return w.Mob(1).HP < 100 }) {
+		t.Fatalf("damaged_first: never attacked mob 1")
+	}
+	// Simula perda do target (ex: tab manual) e libera o mob 2
+	w.SetTarget(0)
+	w.RejectTarget = nil
+	w.Run(b, 3, step)
+	if tgt := b.GetCurrentTarget(); tgt == nil || tgt.EntityID != 1 {
+		t.Fatalf("damaged_first: expected to resume mob 1, got %v", tgt)
+	}
+}
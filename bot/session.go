@@ -0,0 +1,253 @@
+package bot
+
+import (
+	"archefriend/action"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ====================
+// Session
+// ====================
+
+// SessionRecord é o resumo de uma sessão de farm: do Start ao Stop (ou até
+// trocar de preset). Salvo em JSON em Config.SessionDir, um arquivo por
+// sessão, e agregado pelo bot_report.
+type SessionRecord struct {
+	ID        string                 `json:"id"` // 20240101_120000_000
+	Preset    string                 `json:"preset,omitempty"`
+	MobNames  []string               `json:"mob_names"`
+	Start     time.Time              `json:"start"`
+	End       time.Time              `json:"end"`
+	Kills     int                    `json:"kills"`
+	Mobs      map[string]*MobSession `json:"mobs"` // por nome do mob
+	HPPotions int                    `json:"hp_potions"`
+	MPPotions int                    `json:"mp_potions"`
+	Deaths    int                    `json:"deaths"`
+	Rests     int                    `json:"rests"`
+	RestMs    int64                  `json:"rest_ms"`
+	Abandoned int                    `json:"abandoned"`
+	Actions   []action.Stats         `json:"actions,omitempty"` // taxas das ações com confirm
+}
+
+// MobSession são os números de um mob dentro da sessão. Time-to-kill conta
+// do target confirmado até a morte.
+type MobSession struct {
+	Kills      int   `json:"kills"`
+	TTKTotalMs int64 `json:"ttk_total_ms"`
+	TTKMinMs   int64 `json:"ttk_min_ms"`
+	TTKMaxMs   int64 `json:"ttk_max_ms"`
+	Abandoned  int   `json:"abandoned"`
+}
+
+// Duration é a duração da sessão (End vazio = ainda rodando, conta 0).
+func (s SessionRecord) Duration() time.Duration {
+	if s.End.IsZero() || s.End.Before(s.Start) {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// AvgTTK é o time-to-kill médio.
+func (m MobSession) AvgTTK() time.Duration {
+	if m.Kills == 0 {
+		return 0
+	}
+	return time.Duration(m.TTKTotalMs/int64(m.Kills)) * time.Millisecond
+}
+
+func newSession(now time.Time, preset string, mobNames []string) *SessionRecord {
+	return &SessionRecord{
+		ID:       fmt.Sprintf("%s_%03d", now.Format("20060102_150405"), now.Nanosecond()/1e6),
+		Preset:   preset,
+		MobNames: append([]string(nil), mobNames...),
+		Start:    now,
+		Mobs:     make(map[string]*MobSession),
+	}
+}
+
+// mob retorna (criando) os números do mob na sessão.
+func (s *SessionRecord) mob(name string) *MobSession {
+	m, ok := s.Mobs[name]
+	if !ok {
+		m = &MobSession{}
+		s.Mobs[name] = m
+	}
+	return m
+}
+
+func (s *SessionRecord) recordKill(name string, ttk time.Duration) {
+	ms := ttk.Milliseconds()
+	m := s.mob(name)
+	if m.Kills == 0 || ms < m.TTKMinMs {
+		m.TTKMinMs = ms
+	}
+	if ms > m.TTKMaxMs {
+		m.TTKMaxMs = ms
+	}
+	m.Kills++
+	m.TTKTotalMs += ms
+	s.Kills++
+}
+
+func (s SessionRecord) copy() SessionRecord {
+	c := s
+	c.MobNames = append([]string(nil), s.MobNames...)
+	c.Mobs = make(map[string]*MobSession, len(s.Mobs))
+	for name, m := range s.Mobs {
+		mc := *m
+		c.Mobs[name] = &mc
+	}
+	return c
+}
+
+// SetSessionDir define onde as sessões são salvas ("" = não salva).
+func (b *Bot) SetSessionDir(dir string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.SessionDir = dir
+}
+
+// GetPresetName retorna o preset ativo ("" = nenhum carregado).
+func (b *Bot) GetPresetName() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.preset
+}
+
+// SetPresetName registra o preset ativo. Com o bot rodando, trocar de
+// preset fecha a sessão atual e começa outra (kills/hora por preset).
+func (b *Bot) SetPresetName(name string) {
+	b.mu.Lock()
+	if b.preset == name {
+		b.mu.Unlock()
+		return
+	}
+	b.preset = name
+	running := b.running
+	b.mu.Unlock()
+
+	if running {
+		b.EndSession()
+		b.beginSession()
+	} else {
+		b.mu.Lock()
+		b.session.Preset = name
+		b.mu.Unlock()
+	}
+}
+
+// GetSession retorna uma cópia da sessão atual (End = agora).
+func (b *Bot) GetSession() SessionRecord {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	s := b.session.copy()
+	s.End = b.now()
+	// Mobs adicionados à lista no meio da sessão também contam
+	for _, n := range b.config.MobNames {
+		if !containsFold(s.MobNames, n) {
+			s.MobNames = append(s.MobNames, n)
+		}
+	}
+	if b.state == StateResting {
+		s.RestMs += b.now().Sub(b.restStart).Milliseconds()
+	}
+	s.Actions = b.actions.Stats()
+	return s
+}
+
+// beginSession começa uma sessão nova (Start e troca de preset).
+func (b *Bot) beginSession() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.session = newSession(b.now(), b.preset, b.config.MobNames)
+	b.ttkID = 0
+	b.actions.ResetStats()
+}
+
+// EndSession fecha a sessão atual e salva em SessionDir. Sessões sem
+// nenhum evento não são salvas. Retorna o arquivo ("" = não salvou).
+func (b *Bot) EndSession() string {
+	s := b.GetSession()
+	b.mu.Lock()
+	dir := b.config.SessionDir
+	b.session = newSession(b.now(), b.preset, b.config.MobNames)
+	b.actions.ResetStats()
+	b.mu.Unlock()
+
+	if dir == "" || (s.Kills == 0 && s.Deaths == 0 && s.Abandoned == 0) {
+		return ""
+	}
+	filename, err := SaveSession(dir, s)
+	if err != nil {
+		fmt.Printf("[BOT] Erro ao salvar sessão: %v\n", err)
+		return ""
+	}
+	fmt.Printf("[BOT] Session saved: %s (%d kills in %s)\n", filename, s.Kills, s.Duration().Round(time.Second))
+	return filename
+}
+
+// SaveSession grava a sessão em dir/session_<id>.json.
+func SaveSession(dir string, s SessionRecord) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, "session_"+s.ID+".json")
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// LoadSessions lê todas as sessões salvas em dir, da mais antiga à mais
+// recente. Arquivos inválidos são ignorados (com aviso).
+func LoadSessions(dir string) ([]SessionRecord, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "session_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]SessionRecord, 0, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var s SessionRecord
+		if err := json.Unmarshal(data, &s); err != nil {
+			fmt.Printf("[BOT] Sessão inválida %s: %v\n", f, err)
+			continue
+		}
+		if s.Mobs == nil {
+			s.Mobs = make(map[string]*MobSession)
+		}
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Start.Before(sessions[j].Start) })
+	return sessions, nil
+}
+
+// hasMob indica se o mob fazia parte da sessão (na lista ou com eventos).
+func (s SessionRecord) hasMob(name string) bool {
+	if _, ok := s.Mobs[name]; ok {
+		return true
+	}
+	return containsFold(s.MobNames, name)
+}
+
+func containsFold(list []string, name string) bool {
+	for _, n := range list {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+
+	w := sim.NewWorld()
+	w.Damage["1"] = 50
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 2, Name: "Wolf", X: 8, MaxHP: 100})
+	w.AddMob(sim.Mob{ID: 3, Name: "Bear", X: 10, MaxHP: 200})
+	cfg := w.Config("Wolf", "Bear", "Deer")
+	cfg.SessionDir = dir
+	b := w.NewBot(cfg)
+	b.SetPresetName("preset1")
+
+	if !w.RunUntil(b, 2000, step, func() bool { return b.GetStats().MobsKilled == 3 }) {
+		t.Fatalf("mobs not killed (state %s)", b.GetState())
+	}
+	w.Run(b, int(10*time.Second/step), step)
+
+	s := b.GetSession()
+	if s.Kills != 3 || s.Mobs["Wolf"] == nil || s.Mobs["Wolf"].Kills != 2 || s.Mobs["Bear"] == nil || s.Mobs["Bear"].Kills != 1 {
+		t.Fatalf("unexpected session kills: %d %+v", s.Kills, s.Mobs)
+	}
+	// Bear tem o dobro de HP: TTK maior que o do Wolf
+	if wolf, bear := s.Mobs["Wolf"].AvgTTK(), s.Mobs["Bear"].AvgTTK(); wolf <= 0 || bear <= wolf {
+		t.Fatalf("unexpected TTK: wolf %s bear %s", wolf, bear)
+	}
+
+	filename := b.EndSession()
+	if filename == "" {
+		t.Fatalf("session not saved")
+	}
+	if b.GetSession().Kills != 0 {
+		t.Fatalf("new session should start empty")
+	}
+
+	sessions, err := bot.LoadSessions(dir)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("load sessions: %d (%v)", len(sessions), err)
+	}
+	if sessions[0].Preset != "preset1" || sessions[0].Kills != 3 {
+		t.Fatalf("saved session: preset %q kills %d", sessions[0].Preset, sessions[0].Kills)
+	}
+
+	// Mesma sessão duas vezes: horas dobram, kills/hora igual
+	r := bot.BuildReport(append(sessions, sessions[0]))
+	if r.Kills != 6 || len(r.ByPreset) != 1 || r.ByPreset[0].Name != "preset1" {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	want := float64(sessions[0].Kills) / sessions[0].Duration().Hours()
+	if kph := r.ByPreset[0].KillsPerHour; kph < want-0.01 || kph > want+0.01 {
+		t.Fatalf("preset kills/h %.2f, want %.2f", kph, want)
+	}
+	// Deer estava na lista sem kill: aparece com 0/h
+	found := false
+	for _, row := range r.ByMob {
+		if row.Name == "Deer" {
+			found = row.Kills == 0 && row.Sessions == 2
+		}
+	}
+	if !found {
+		t.Fatalf("mob on list without kills missing from report")
+	}
+	for _, format := range []string{"text", "csv", "json"} {
+		if err := r.Write(io.Discard, format); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package bot
+
+import (
+	"archefriend/action"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ====================
+// Threshold rules
+// ====================
+
+// Recursos que uma regra pode observar (sempre em %)
+const (
+	ResourcePlayerHP = "player_hp"
+	ResourcePlayerMP = "player_mp"
+	ResourceTargetHP = "target_hp"
+	ResourceMateHP   = "mate_hp"
+)
+
+// Comparações
+const (
+	CompareBelow = "below" // dispara com valor < threshold (padrão)
+	CompareAbove = "above" // dispara com valor > threshold
+)
+
+// ThresholdRule pressiona uma sequência de teclas quando um recurso cruza
+// o threshold. Com Tiers, o tier mais severo que bate é usado primeiro e,
+// se estiver em cooldown, o próximo:
+//
+//	{"name": "hp_potion", "resource": "player_hp", "priority": 10, "tiers": [
+//	  {"value": 30, "keys": ["F3"], "cooldown_ms": 60000},
+//	  {"value": 60, "keys": ["F1"], "cooldown_ms": 21000}]}
+//	{"name": "execute", "resource": "target_hp", "value": 20, "keys": ["4"], "cooldown_ms": 6000}
+//
+// As teclas são repetidas até a confirmação (ver action.Confirm), por
+// padrão o recurso voltando (HP/MP subindo, target HP caindo). Um buff ou
+// cast confirma antes: {"name": "mp", ..., "confirm": {"skill": 23040}}
+type ThresholdRule struct {
+	Name       string          `json:"name"`
+	Resource   string          `json:"resource"`
+	Compare    string          `json:"compare,omitempty"` // below (padrão), above
+	Value      float32         `json:"value,omitempty"`   // threshold em % (sem tiers)
+	Keys       []string        `json:"keys,omitempty"`
+	CooldownMs int             `json:"cooldown_ms"`
+	Priority   int             `json:"priority"` // maior avalia primeiro
+	Tiers      []ThresholdTier `json:"tiers,omitempty"`
+	Disabled   bool            `json:"disabled,omitempty"`
+	Confirm    action.Confirm  `json:"confirm,omitempty"`
+}
+
+// ThresholdTier é um degrau da regra (ex: poção pequena a 60%, grande a 30%).
+type ThresholdTier struct {
+	Value      float32        `json:"value"`
+	Keys       []string       `json:"keys"`
+	CooldownMs int            `json:"cooldown_ms,omitempty"` // 0 = cooldown da regra
+	Confirm    action.Confirm `json:"confirm,omitempty"`     // vazio = confirm da regra
+}
+
+// ThresholdFire é uma regra que disparou: as teclas a enviar e o contexto.
+type ThresholdFire struct {
+	Rule     string
+	Tier     int // índice do tier (0 sem tiers)
+	Resource string
+	Value    float32 // valor do recurso no disparo
+	Keys     []string
+	Confirm  action.Confirm
+}
+
+// Resources lê os recursos observados pelas regras. Funções nil (ou ok
+// false) deixam as regras daquele recurso sem disparar.
+type Resources struct {
+	PlayerHP func() (float32, bool)
+	PlayerMP func() (float32, bool)
+	TargetHP func() (float32, bool)
+	MateHP   func() (float32, bool)
+}
+
+// Read lê um recurso pelo nome (ResourcePlayerHP, ...).
+func (r Resources) Read(resource string) (float32, bool) {
+	var fn func() (float32, bool)
+	switch resource {
+	case ResourcePlayerHP:
+		fn = r.PlayerHP
+	case ResourcePlayerMP:
+		fn = r.PlayerMP
+	case ResourceTargetHP:
+		fn = r.TargetHP
+	case ResourceMateHP:
+		fn = r.MateHP
+	}
+	if fn == nil {
+		return 0, false
+	}
+	return fn()
+}
+
+// ThresholdEngine avalia as regras em ordem de prioridade e dispara no
+// máximo uma por Evaluate (a próxima regra fica para o tick seguinte).
+// Usado pelo bot (action "potions") e pelo Guardian.
+type ThresholdEngine struct {
+	mu       sync.Mutex
+	rules    []ThresholdRule
+	lastUsed map[string]time.Time // "regra#tier"
+}
+
+// NewThresholdEngine valida as regras e ordena por prioridade.
+func NewThresholdEngine(rules []ThresholdRule) (*ThresholdEngine, error) {
+	sorted := make([]ThresholdRule, 0, len(rules))
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		switch r.Resource {
+		case ResourcePlayerHP, ResourcePlayerMP, ResourceTargetHP, ResourceMateHP:
+		default:
+			return nil, fmt.Errorf("threshold '%s': resource desconhecido: %q", r.Name, r.Resource)
+		}
+		switch r.Compare {
+		case "":
+			r.Compare = CompareBelow
+		case CompareBelow, CompareAbove:
+		default:
+			return nil, fmt.Errorf("threshold '%s': compare desconhecido: %q", r.Name, r.Compare)
+		}
+		if len(r.Tiers) == 0 {
+			r.Tiers = []ThresholdTier{{Value: r.Value, Keys: r.Keys}}
+		}
+		for j, t := range r.Tiers {
+			if len(t.Keys) == 0 {
+				return nil, fmt.Errorf("threshold '%s': tier %d sem keys", r.Name, j+1)
+			}
+		}
+		// Tier mais severo primeiro: menor valor para below, maior para above
+		tiers := append([]ThresholdTier(nil), r.Tiers...)
+		above := r.Compare == CompareAbove
+		sort.SliceStable(tiers, func(a, b int) bool {
+			if above {
+				return tiers[a].Value > tiers[b].Value
+			}
+			return tiers[a].Value < tiers[b].Value
+		})
+		r.Tiers = tiers
+		sorted = append(sorted, r)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority > sorted[j].Priority })
+	return &ThresholdEngine{rules: sorted, lastUsed: make(map[string]time.Time)}, nil
+}
+
+// Rules retorna as regras normalizadas, em ordem de avaliação.
+func (e *ThresholdEngine) Rules() []ThresholdRule {
+	return append([]ThresholdRule(nil), e.rules...)
+}
+
+// Evaluate retorna a regra que deve disparar agora. O cooldown só conta
+// depois do Commit (disparo confirmado): potion que não saiu não gasta o
+// tier. Valor 0 conta como morto/sem leitura e nunca dispara.
+func (e *ThresholdEngine) Evaluate(now time.Time, res Resources) (ThresholdFire, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range e.rules {
+		if r.Disabled {
+			continue
+		}
+		value, ok := res.Read(r.Resource)
+		if !ok || value <= 0 {
+			continue
+		}
+		for i, t := range r.Tiers {
+			if !r.triggers(value, t.Value) {
+				continue
+			}
+			cooldown := t.CooldownMs
+			if cooldown == 0 {
+				cooldown = r.CooldownMs
+			}
+			if last, used := e.lastUsed[fireKey(r.Name, i)]; used && now.Sub(last) < time.Duration(cooldown)*time.Millisecond {
+				continue // tier em cooldown: tenta o próximo
+			}
+			confirm := t.Confirm
+			if confirm.Empty() {
+				confirm = r.Confirm
+			}
+			if confirm.Empty() {
+				confirm = r.resourceConfirm()
+			}
+			return ThresholdFire{Rule: r.Name, Tier: i, Resource: r.Resource, Value: value, Keys: t.Keys, Confirm: confirm}, true
+		}
+	}
+	return ThresholdFire{}, false
+}
+
+// Commit marca o cooldown do tier que disparou (chamar só quando o disparo
+// confirmou, ou logo depois do envio se a regra não tem confirm).
+func (e *ThresholdEngine) Commit(f ThresholdFire, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastUsed[fireKey(f.Rule, f.Tier)] = now
+}
+
+func fireKey(rule string, tier int) string {
+	return fmt.Sprintf("%s#%d", rule, tier)
+}
+
+// resourceConfirm é a confirmação padrão: o recurso se afastando do
+// threshold (potion sobe o HP/MP, execute derruba o HP do target).
+func (r ThresholdRule) resourceConfirm() action.Confirm {
+	return action.Confirm{Resource: r.Resource, Drop: r.Compare == CompareAbove || r.Resource == ResourceTargetHP}
+}
+
+func (r ThresholdRule) triggers(value, threshold float32) bool {
+	if r.Compare == CompareAbove {
+		return value > threshold
+	}
+	return value < threshold
+}
+
+// Do envia as teclas bloqueando até confirmar ou desistir (guardian, que
+// roda na própria goroutine): cada tecla uma vez, repetindo no backoff.
+// Retorna os presses da sequência e se confirmou.
+func (f ThresholdFire) Do(actions *action.Executor, sendKey func(string), sleep func(time.Duration)) (int, bool) {
+	res := actions.Do(f.Rule, f.press(sendKey, sleep), f.Confirm)
+	return res.Presses, res.Confirmed
+}
+
+// press é um envio da sequência (uma vez cada tecla).
+func (f ThresholdFire) press(sendKey func(string), sleep func(time.Duration)) func() {
+	return func() {
+		for i, key := range f.Keys {
+			sendKey(key)
+			if i < len(f.Keys)-1 {
+				sleep(KeySequenceInterval)
+			}
+		}
+	}
+}
+
+func (f ThresholdFire) String() string {
+	return fmt.Sprintf("%s (%s %.0f%%) -> %s", f.Rule, f.Resource, f.Value, strings.Join(f.Keys, ", "))
+}
+
+// LegacyPotionRules converte as configs antigas de HP/MP potion em regras.
+func LegacyPotionRules(cfg Config) []ThresholdRule {
+	cooldown := int(cfg.PotionCooldown / time.Millisecond)
+	var rules []ThresholdRule
+	if cfg.HPPotionEnabled && cfg.HPPotionKey != "" {
+		rules = append(rules, ThresholdRule{
+			Name: "hp_potion", Resource: ResourcePlayerHP, Value: cfg.HPPotionThreshold,
+			Keys: []string{cfg.HPPotionKey}, CooldownMs: cooldown, Priority: 2,
+		})
+	}
+	if cfg.MPPotionEnabled && cfg.MPPotionKey != "" {
+		rules = append(rules, ThresholdRule{
+			Name: "mp_potion", Resource: ResourcePlayerMP, Value: cfg.MPPotionThreshold,
+			Keys: []string{cfg.MPPotionKey}, CooldownMs: cooldown, Priority: 1,
+		})
+	}
+	return rules
+}
+
+// MateHPFromEntities retorna o HP% do mate (pet/montaria) mais perto.
+func MateHPFromEntities(entities []EntityInfo) (float32, bool) {
+	var best *EntityInfo
+	for i := range entities {
+		e := &entities[i]
+		if e.IsMate && e.MaxHP > 0 && (best == nil || e.Distance < best.Distance) {
+			best = e
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	return float32(best.HP) / float32(best.MaxHP) * 100, true
+}
+
+// ====================
+// Bot integration
+// ====================
+
+// SetThresholds troca as regras. nil/vazio volta para as potions de HP/MP
+// da config (LegacyPotionRules).
+func (b *Bot) SetThresholds(rules []ThresholdRule) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.Thresholds = rules
+	return b.rebuildThresholdsLocked()
+}
+
+// GetThresholds retorna as regras ativas, em ordem de avaliação.
+func (b *Bot) GetThresholds() []ThresholdRule {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.thresholds == nil {
+		return nil
+	}
+	return b.thresholds.Rules()
+}
+
+// rebuildThresholdsLocked recria o engine a partir da config. Cooldowns
+// em andamento são perdidos. Chamar com b.mu travado.
+func (b *Bot) rebuildThresholdsLocked() error {
+	rules := b.config.Thresholds
+	if len(rules) == 0 {
+		rules = LegacyPotionRules(b.config)
+	}
+	engine, err := NewThresholdEngine(rules)
+	if err != nil {
+		return err
+	}
+	b.thresholds = engine
+	return nil
+}
+
+// botResources lê os recursos do bot: player pelos providers, target pelo
+// target atual e mate pela entity list.
+func (b *Bot) botResources() Resources {
+	return Resources{
+		PlayerHP: b.playerHPPercent,
+		PlayerMP: b.playerMPPercent,
+		TargetHP: func() (float32, bool) {
+			b.mu.RLock()
+			defer b.mu.RUnlock()
+			if b.currentTarget == nil || b.currentTarget.MaxHP == 0 {
+				return 0, false
+			}
+			return float32(hpPercent(*b.currentTarget)), true
+		},
+		MateHP: func() (float32, bool) {
+			return MateHPFromEntities(b.GetEntityProvider().GetEntities())
+		},
+	}
+}
+
+// tickThresholds avalia as regras e envia as teclas da que disparar. A
+// regra fica pendente no slot de threshold (checada nos próximos ticks) e só
+// gasta o cooldown quando confirmar.
+func (b *Bot) tickThresholds() {
+	b.mu.RLock()
+	engine := b.thresholds
+	sendKey := b.config.SendKey
+	if b.config.SendPotionKey != nil {
+		sendKey = b.config.SendPotionKey
+	}
+	b.mu.RUnlock()
+	if engine == nil || sendKey == nil || b.actionBusy(slotThreshold) {
+		return
+	}
+
+	fire, ok := engine.Evaluate(b.now(), b.botResources())
+	if !ok {
+		return
+	}
+
+	b.startAction(slotThreshold, fire.Rule, fire.press(sendKey, b.sleep), fire.Confirm, func(res action.Result) {
+		if !res.Confirmed {
+			fmt.Printf("[BOT] Threshold: %s - não confirmado\n", fire)
+			return
+		}
+		engine.Commit(fire, b.now())
+		b.onThresholdFired(fire, res.Presses)
+	})
+}
+
+// onThresholdFired conta a potion e emite o evento de um disparo confirmado.
+func (b *Bot) onThresholdFired(fire ThresholdFire, presses int) {
+	b.mu.Lock()
+	switch fire.Resource {
+	case ResourcePlayerHP:
+		b.stats.HPPotions++
+		b.session.HPPotions++
+	case ResourcePlayerMP:
+		b.stats.MPPotions++
+		b.session.MPPotions++
+	}
+	b.emitLocked(Event{Type: EventPotionUsed, Reason: fire.Rule, Key: strings.Join(fire.Keys, "+"), Value: fire.Value})
+	b.mu.Unlock()
+	fmt.Printf("[BOT] Threshold: %s [x%d]\n", fire, presses)
+}
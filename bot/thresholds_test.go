@@ -0,0 +1,163 @@
+package bot_test
+
+import (
+	"archefriend/bot"
+	"archefriend/sim"
+	"testing"
+	"time"
+)
+
+func TestPotionThresholds(t *testing.T) {
+	w := sim.NewWorld()
+	cfg := w.Config()
+	cfg.HPPotionEnabled = true
+	cfg.HPPotionThreshold = 50
+	cfg.MPPotionEnabled = true
+	cfg.MPPotionThreshold = 30
+	w.Heal[cfg.HPPotionKey] = 10
+	w.Mana[cfg.MPPotionKey] = 10
+	b := w.NewBot(cfg)
+
+	// Acima do threshold: nada
+	w.SetPlayerHP(600)
+	w.SetPlayerMP(400)
+	w.Run(b, 10, step)
+	if n := w.KeyCount(cfg.HPPotionKey) + w.KeyCount(cfg.MPPotionKey); n != 0 {
+		t.Fatalf("potion used above threshold (%d presses)", n)
+	}
+
+	// Abaixo: HP potion uma vez (HP subiu = confirmado) e respeita o cooldown
+	w.SetPlayerHP(400)
+	w.Run(b, 10, step)
+	if n := w.KeyCount(cfg.HPPotionKey); n != 1 {
+		t.Fatalf("expected 1 HP potion press, got %d", n)
+	}
+	w.Run(b, int((cfg.PotionCooldown-time.Second)/step), step)
+	if n := w.KeyCount(cfg.HPPotionKey); n != 1 {
+		t.Fatalf("HP potion used during cooldown (%d presses)", n)
+	}
+	w.Run(b, int(2*time.Second/step), step)
+	if n := w.KeyCount(cfg.HPPotionKey); n != 2 {
+		t.Fatalf("HP potion not reused after cooldown (%d presses)", n)
+	}
+
+	// HP 0 (morto) não usa potion
+	w.SetPlayerHP(0)
+	w.Run(b, int(cfg.PotionCooldown/step)+1, step)
+	if n := w.KeyCount(cfg.HPPotionKey); n != 2 {
+		t.Fatalf("HP potion used at 0 HP")
+	}
+
+	// MP com cooldown independente do HP
+	w.SetPlayerMP(200)
+	w.Run(b, 2, step)
+	if n := w.KeyCount(cfg.MPPotionKey); n != 1 {
+		t.Fatalf("expected 1 MP potion press, got %d", n)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	hpRule := bot.ThresholdRule{Name: "hp", Resource: bot.ResourcePlayerHP, Priority: 10, Tiers: []bot.ThresholdTier{
+		{Value: 60, Keys: []string{"F1"}, CooldownMs: 21000},
+		{Value: 30, Keys: []string{"F3"}, CooldownMs: 60000},
+	}}
+	mpRule := bot.ThresholdRule{Name: "mp", Resource: bot.ResourcePlayerMP, Value: 30, Keys: []string{"F2"}, CooldownMs: 21000, Priority: 1}
+
+	// Tiers: pequena a 60%, grande a 30%; com a grande em cooldown volta
+	// para a pequena
+	w := sim.NewWorld()
+	w.Heal["F1"], w.Heal["F3"] = 10, 10
+	cfg := w.Config()
+	cfg.Thresholds = []bot.ThresholdRule{hpRule, mpRule}
+	b := w.NewBot(cfg)
+	w.SetPlayerHP(500)
+	w.Run(b, 1, step)
+	if w.KeyCount("F1") != 1 || w.KeyCount("F3") != 0 {
+		t.Fatalf("tier 60%%: F1 %d F3 %d", w.KeyCount("F1"), w.KeyCount("F3"))
+	}
+	w.SetPlayerHP(200)
+	w.Run(b, 1, step)
+	if w.KeyCount("F3") != 1 {
+		t.Fatalf("tier 30%%: F3 %d", w.KeyCount("F3"))
+	}
+	w.Run(b, int(20*time.Second/step), step)
+	if w.KeyCount("F1") != 1 || w.KeyCount("F3") != 1 {
+		t.Fatalf("tiers fired during cooldown: F1 %d F3 %d", w.KeyCount("F1"), w.KeyCount("F3"))
+	}
+	w.Run(b, int(2*time.Second/step), step)
+	if w.KeyCount("F1") != 2 {
+		t.Fatalf("small tier not used while big one on cooldown: F1 %d", w.KeyCount("F1"))
+	}
+
+	// Prioridade: HP e MP baixos no mesmo tick, HP primeiro e MP no seguinte
+	w = sim.NewWorld()
+	w.Heal["F1"], w.Mana["F2"] = 10, 10
+	cfg = w.Config()
+	cfg.Thresholds = []bot.ThresholdRule{hpRule, mpRule}
+	b = w.NewBot(cfg)
+	w.SetPlayerHP(500)
+	w.SetPlayerMP(100)
+	w.Run(b, 1, step)
+	if w.KeyCount("F1") != 1 || w.KeyCount("F2") != 0 {
+		t.Fatalf("priority: F1 %d F2 %d", w.KeyCount("F1"), w.KeyCount("F2"))
+	}
+	w.Run(b, 1, step)
+	if w.KeyCount("F2") != 1 {
+		t.Fatalf("lower priority rule not fired next tick: F2 %d", w.KeyCount("F2"))
+	}
+
+	// Target HP: execute abaixo de 30%
+	w = sim.NewWorld()
+	w.Damage["1"] = 15 // um press por ataque (sem flag de combate)
+	w.Damage["4"] = 5
+	w.AddMob(sim.Mob{ID: 1, Name: "Wolf", X: 5, MaxHP: 100})
+	cfg = w.Config("Wolf")
+	cfg.Thresholds = []bot.ThresholdRule{{Name: "execute", Resource: bot.ResourceTargetHP, Value: 30, Keys: []string{"4"}, CooldownMs: 5000}}
+	b = w.NewBot(cfg)
+	if !w.RunUntil(b, 500, step, func() bool { return w.Mob(1).HP <= 25 }) {
+		t.Fatalf("mob never reached 25%% HP")
+	}
+	if w.KeyCount("4") != 0 {
+		t.Fatalf("execute used above 30%%")
+	}
+	w.Run(b, 2, step)
+	if w.KeyCount("4") != 1 {
+		t.Fatalf("execute not used below 30%%: %d", w.KeyCount("4"))
+	}
+
+	// Regras inválidas são rejeitadas
+	if _, err := bot.NewThresholdEngine([]bot.ThresholdRule{{Resource: "stamina", Keys: []string{"1"}}}); err == nil {
+		t.Fatalf("unknown resource accepted")
+	}
+	if hp, ok := bot.MateHPFromEntities([]bot.EntityInfo{
+		{IsMate: true, HP: 10, MaxHP: 100, Distance: 30},
+		{IsMate: true, HP: 40, MaxHP: 100, Distance: 3},
+		{IsNPC: true, HP: 1, MaxHP: 100},
+	}); !ok || hp != 40 {
+		t.Fatalf("mate HP %.0f (ok %v), want nearest mate 40%%", hp, ok)
+	}
+
+	// Guardian: mesmas regras sem bot
+	w = sim.NewWorld()
+	w.Heal["F1"] = 10
+	g, err := bot.NewGuardian(bot.GuardianConfig{
+		Rules:   []bot.ThresholdRule{hpRule},
+		SendKey: w.SendKey,
+		Clock:   w.Clock,
+		Resources: bot.Resources{PlayerHP: func() (float32, bool) {
+			cur, max := w.GetPlayerHP()
+			return float32(cur) / float32(max) * 100, true
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetPlayerHP(500)
+	for i := 0; i < 50; i++ {
+		w.Clock.Advance(100 * time.Millisecond)
+		g.Step()
+	}
+	if w.KeyCount("F1") != 1 {
+		t.Fatalf("guardian: F1 %d, want 1", w.KeyCount("F1"))
+	}
+}
@@ -44,7 +44,7 @@ if ($LASTEXITCODE -ne 0) {
 Write-Host ""
 Write-Host "Copiando arquivos de configuracao..." -ForegroundColor Green
 
-$jsonFiles = @("reactions.json", "buff_presets.json", "skill_reactions.json", "aimbot_config.json")
+$jsonFiles = @("reactions.json", "buff_presets.json", "skill_reactions.json", "aimbot_config.json", "classification_rules.json")
 foreach ($file in $jsonFiles) {
     if (Test-Path $file) {
         Copy-Item $file "$BUILD_DIR\" -Force
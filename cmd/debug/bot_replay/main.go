@@ -0,0 +1,137 @@
+package main
+
+// bot_replay roda a lógica do bot contra uma sessão gravada (NUMPAD7 no
+// overlay) e compara as decisões de target com as da sessão original.
+// Não depende do jogo nem de Windows:
+//
+//	go run ./cmd/debug/bot_replay -file recordings/bot_20240101_120000.jsonl
+
+import (
+	"archefriend/bot"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	file := flag.String("file", "", "arquivo gravado (.jsonl)")
+	configFile := flag.String("config", "bot_config.json", "config do bot (mobs/range)")
+	mobs := flag.String("mobs", "", "sobrescreve mob names (separados por vírgula)")
+	maxRange := flag.Float64("range", 0, "sobrescreve max range (m)")
+	partial := flag.Bool("partial", false, "força partial match")
+	maxDiffs := flag.Int("diffs", 20, "quantas divergências listar")
+	flag.Parse()
+
+	if *file == "" {
+		fmt.Println("Uso: bot_replay -file <gravação.jsonl> [-config bot_config.json] [-mobs a,b] [-range 30] [-partial]")
+		os.Exit(1)
+	}
+
+	replay, err := bot.LoadReplay(*file)
+	if err != nil {
+		fmt.Printf("[REPLAY] %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("[REPLAY] %s: %d frames\n", *file, replay.Len())
+
+	cfg := bot.DefaultConfig()
+	cfg.TargetDelay = 0 // replay avança frame a frame, sem esperar o client
+	cfg.GetPlayerHP = replay.GetPlayerHP
+	cfg.GetPlayerMP = replay.GetPlayerMP
+	// SendKey fica nil: ataque/loot/potions não geram input no replay
+
+	b := bot.NewWithTargeter(replay, replay, cfg)
+	if fc, err := bot.LoadFileConfig(*configFile); err == nil {
+		b.ApplyFileConfig(fc)
+	} else {
+		fmt.Printf("[REPLAY] Config %s não carregada (%v), usando defaults\n", *configFile, err)
+	}
+	if *mobs != "" {
+		names := strings.Split(*mobs, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		b.SetMobNames(names)
+	}
+	if *maxRange > 0 {
+		b.SetMaxRange(float32(*maxRange))
+	}
+	if *partial {
+		b.SetPartialMatch(true)
+	}
+
+	var (
+		agree, differ, botOnly, recOnly int
+		diffs                           []string
+		lastBot, lastRec                uint32
+		botSwitches, recSwitches        int
+	)
+
+	for replay.Next() {
+		b.Step()
+
+		fr := replay.Frame()
+		botTarget := replay.GetCurrentTargetID()
+		recTarget := fr.TargetID
+
+		if botTarget != lastBot {
+			botSwitches++
+			lastBot = botTarget
+		}
+		if recTarget != lastRec {
+			recSwitches++
+			lastRec = recTarget
+		}
+
+		switch {
+		case botTarget == recTarget:
+			agree++
+			continue
+		case botTarget == 0:
+			recOnly++
+		case recTarget == 0:
+			botOnly++
+		default:
+			differ++
+		}
+
+		if len(diffs) < *maxDiffs {
+			diffs = append(diffs, fmt.Sprintf("  t=%6.1fs frame %5d  bot=%s  recorded=%s  [%s]",
+				float64(fr.T)/1000, replay.Index(),
+				describe(fr, botTarget), describe(fr, recTarget), b.GetState()))
+		}
+	}
+
+	total := agree + differ + botOnly + recOnly
+	fmt.Println()
+	fmt.Println("[REPLAY] ===== Result =====")
+	if total == 0 {
+		fmt.Println("[REPLAY] Nenhum frame")
+		return
+	}
+	fmt.Printf("[REPLAY] Frames: %d | Same target: %d (%.1f%%)\n", total, agree, 100*float64(agree)/float64(total))
+	fmt.Printf("[REPLAY] Different target: %d | Bot only: %d | Recorded only: %d\n", differ, botOnly, recOnly)
+	fmt.Printf("[REPLAY] Target switches: bot %d | recorded %d\n", botSwitches, recSwitches)
+	stats := b.GetStats()
+	fmt.Printf("[REPLAY] Bot: %d targets set, %d kills\n", stats.TargetsSet, stats.MobsKilled)
+
+	if len(diffs) > 0 {
+		fmt.Printf("[REPLAY] First %d divergences:\n", len(diffs))
+		for _, d := range diffs {
+			fmt.Println(d)
+		}
+	}
+}
+
+func describe(fr bot.Frame, id uint32) string {
+	if id == 0 {
+		return "-"
+	}
+	for _, e := range fr.Entities {
+		if e.EntityID == id {
+			return fmt.Sprintf("%s(%d)", e.Name, id)
+		}
+	}
+	return fmt.Sprintf("?(%d)", id)
+}
@@ -0,0 +1,57 @@
+package main
+
+// bot_report agrega as sessões salvas pelo bot (session_dir no
+// bot_config.json) em kills/hora por mob e por preset. Não depende do jogo
+// nem de Windows:
+//
+//	go run ./cmd/debug/bot_report [-dir sessions] [-format text|csv|json]
+//	go run ./cmd/debug/bot_report -preset preset2 -since 2024-01-01 -format csv > farm.csv
+
+import (
+	"archefriend/bot"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "sessions", "pasta das sessões")
+	format := flag.String("format", "text", "formato: text, csv, json")
+	preset := flag.String("preset", "", "só sessões deste preset")
+	since := flag.String("since", "", "só sessões a partir desta data (2006-01-02)")
+	flag.Parse()
+
+	sessions, err := bot.LoadSessions(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[REPORT] %v\n", err)
+		os.Exit(1)
+	}
+
+	var from time.Time
+	if *since != "" {
+		if from, err = time.ParseInLocation("2006-01-02", *since, time.Local); err != nil {
+			fmt.Fprintf(os.Stderr, "[REPORT] -since inválido: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	filtered := sessions[:0]
+	for _, s := range sessions {
+		if *preset != "" && s.Preset != *preset {
+			continue
+		}
+		if !from.IsZero() && s.Start.Before(from) {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	if len(filtered) == 0 {
+		fmt.Fprintf(os.Stderr, "[REPORT] Nenhuma sessão em %s\n", *dir)
+		os.Exit(1)
+	}
+
+	if err := bot.BuildReport(filtered).Write(os.Stdout, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "[REPORT] %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+// bot_tree imprime a behavior tree padrão do bot em JSON, ponto de partida
+// para uma árvore customizada ("behavior_tree" no bot_config.json):
+//
+//	go run ./cmd/debug/bot_tree > my_tree.json
+//
+// Os cenários do bot (relógio virtual, package sim) rodam com go test ./bot.
+
+import (
+	"archefriend/bot"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func main() {
+	data, err := json.MarshalIndent(bot.DefaultBehaviorTree(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[TREE] %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
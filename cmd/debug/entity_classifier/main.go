@@ -177,31 +177,37 @@ func listPlayers() {
 	}
 }
 
-// getRaceAndFaction reads the race string from E+0x370 and determines faction
+// classifier uses the same rules as the overlay (classification_rules.json)
+var classifier = loadClassifier("classification_rules.json")
+
+func loadClassifier(filename string) *esp.Classifier {
+	rules, err := esp.LoadClassificationRules(filename)
+	if err != nil {
+		def := esp.DefaultClassificationRules()
+		return esp.NewClassifier(def)
+	}
+	return esp.NewClassifier(*rules)
+}
+
+// getRaceAndFaction reads the race string and determines faction via the classifier
 func getRaceAndFaction(entityAddr uint32) (race, faction string) {
-	raceData := readMemory(uintptr(entityAddr+0x370), 32)
+	rules := classifier.Rules()
+	raceData := readMemory(uintptr(entityAddr+uint32(rules.RaceOffset)), 32)
 	raceStr := readCString(raceData)
 
-	// Parse "foley_<race>" format
-	if strings.HasPrefix(raceStr, "foley_") {
-		race = strings.TrimPrefix(raceStr, "foley_")
-	} else {
-		race = raceStr
-	}
-
-	// Determine faction by race
-	switch race {
-	case "nuian", "elf", "dwarf":
-		faction = "west"
-	case "hariharan", "firran", "ferre", "returned", "warborn":
-		faction = "east"
-	case "player":
-		faction = "npc" // humanoid NPC
-	default:
+	c := classifier.Classify(esp.ClassifyInput{
+		RaceString: raceStr,
+		IsPlayer:   true,
+		ReadEntity: func(off uint32) uint32 {
+			return binary.LittleEndian.Uint32(readMemory(uintptr(entityAddr+off), 4))
+		},
+	})
+
+	faction = c.Faction
+	if faction == rules.DefaultFaction {
 		faction = "?"
 	}
-
-	return race, faction
+	return c.Race, faction
 }
 
 func classifyPlayers(samples []ClassifiedEntity, reader *bufio.Reader) []ClassifiedEntity {
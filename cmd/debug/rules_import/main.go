@@ -0,0 +1,85 @@
+package main
+
+// rules_import converte reactions.json e skill_reactions.json em regras do
+// rules engine e grava o rules.json com import_legacy desligado (as reações
+// antigas passam a valer só pelo rules.json). Regras já existentes no
+// rules.json são mantidas; as importadas com o mesmo ID são trocadas:
+//
+//	go run ./cmd/debug/rules_import [-reactions reactions.json] [-skills skill_reactions.json] [-out rules.json]
+//	go run ./cmd/debug/rules_import -out -   (só imprime)
+
+import (
+	"archefriend/rules"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	reactions := flag.String("reactions", "reactions.json", "reações de buff/debuff (\"\" = não importa)")
+	skills := flag.String("skills", "skill_reactions.json", "reações de skill (\"\" = não importa)")
+	out := flag.String("out", "rules.json", "rules.json de saída (- = stdout)")
+	flag.Parse()
+
+	var imported []rules.Rule
+	if *reactions != "" {
+		list, err := rules.ImportReactions(*reactions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[RULES] %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "[RULES] %s: %d regras\n", *reactions, len(list))
+		imported = append(imported, list...)
+	}
+	if *skills != "" {
+		list, err := rules.ImportSkillReactions(*skills)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[RULES] %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "[RULES] %s: %d regras\n", *skills, len(list))
+		imported = append(imported, list...)
+	}
+
+	f := rules.File{}
+	if *out != "-" {
+		existing, err := rules.LoadFile(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[RULES] %v\n", err)
+			os.Exit(1)
+		}
+		f.Rules = existing.Rules
+	}
+	f.Rules = merge(f.Rules, imported)
+	f.ImportLegacy = false
+
+	if *out == "-" {
+		data, _ := json.MarshalIndent(f, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	if err := rules.SaveFile(*out, f); err != nil {
+		fmt.Fprintf(os.Stderr, "[RULES] %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "[RULES] %s: %d regras (import_legacy: false)\n", *out, len(f.Rules))
+}
+
+// merge troca as regras de list com o mesmo ID das importadas e adiciona o
+// resto no fim.
+func merge(list, imported []rules.Rule) []rules.Rule {
+	index := make(map[string]int)
+	for i, r := range list {
+		index[r.ID] = i
+	}
+	for _, r := range imported {
+		if i, ok := index[r.ID]; ok {
+			list[i] = r
+			continue
+		}
+		index[r.ID] = len(list)
+		list = append(list, r)
+	}
+	return list
+}
@@ -20,6 +20,7 @@ type Entity struct {
 	MP           uint32
 	MaxMP        uint32
 	IsDead       bool
+	InCombat     bool // só o player local (OFF_COMBAT_RAW)
 	Distance     float32
 	VTable       uint32
 	IsPlayer     bool
@@ -131,6 +132,8 @@ func GetLocalPlayer(handle windows.Handle, x2game uintptr) Entity {
 	player.MaxHP = GetMaxHP(handle, player.Address)
 	player.MP, player.MaxMP = GetLocalPlayerMana(handle, x2game)
 	player.IsDead = memory.ReadU8(handle, uintptr(player.Address)+uintptr(config.OFF_IS_DEAD)) != 0
+	// Valor cru: 0 = fora de combate, qualquer outro = em combate
+	player.InCombat = memory.ReadU32(handle, uintptr(player.Address)+uintptr(config.OFF_COMBAT_RAW)) != 0
 	player.IsTargetable = player.EntityID > 0
 
 	return player
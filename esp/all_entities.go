@@ -1,3 +1,5 @@
+//go:build windows
+
 package esp
 
 import (
@@ -35,6 +37,9 @@ type AllEntitiesManager struct {
 	showEast   bool
 	showPirate bool
 
+	// Race/faction classification (classification_rules.json)
+	classifier *Classifier
+
 	// Hook state
 	hookInstalled     bool
 	hookBuffer        uintptr
@@ -64,6 +69,7 @@ func NewAllEntitiesManager(processHandle uintptr, x2game uintptr, mainManager *M
 		showWest:      true, // Show all factions by default
 		showEast:      true,
 		showPirate:    true,
+		classifier:    NewClassifier(DefaultClassificationRules()),
 		stopChan:      make(chan bool, 1),
 		pauseChan:     make(chan bool, 1),
 		resumeChan:    make(chan bool, 1),
@@ -243,11 +249,52 @@ func (aem *AllEntitiesManager) GetFactionFilters() (west, east, pirate bool) {
 	return aem.showWest, aem.showEast, aem.showPirate
 }
 
-// getRaceAndFaction reads race string from E+0x370 and determines faction
-func (aem *AllEntitiesManager) getRaceAndFaction(entityPtr uint32) (race, faction string) {
-	// Read race string from offset 0x370 (format: "foley_<race>")
+// Filters is the full ESP filter set (switched per zone)
+type Filters struct {
+	Players, NPCs, Mates bool
+	West, East, Pirate   bool
+}
+
+// GetFilters returns all filter states
+func (aem *AllEntitiesManager) GetFilters() Filters {
+	aem.mu.Lock()
+	defer aem.mu.Unlock()
+	return Filters{
+		Players: aem.showPlayers, NPCs: aem.showNPCs, Mates: aem.showMates,
+		West: aem.showWest, East: aem.showEast, Pirate: aem.showPirate,
+	}
+}
+
+// SetFilters replaces all filter states
+func (aem *AllEntitiesManager) SetFilters(f Filters) {
+	aem.mu.Lock()
+	defer aem.mu.Unlock()
+	aem.showPlayers, aem.showNPCs, aem.showMates = f.Players, f.NPCs, f.Mates
+	aem.showWest, aem.showEast, aem.showPirate = f.West, f.East, f.Pirate
+}
+
+// SetClassifier troca as regras de classificação de raça/facção
+func (aem *AllEntitiesManager) SetClassifier(c *Classifier) {
+	aem.mu.Lock()
+	defer aem.mu.Unlock()
+	aem.classifier = c
+}
+
+// getClassifier returns the current classifier
+func (aem *AllEntitiesManager) getClassifier() *Classifier {
+	aem.mu.Lock()
+	defer aem.mu.Unlock()
+	return aem.classifier
+}
+
+// classifyEntity reads the race string and applies the classification rules
+func (aem *AllEntitiesManager) classifyEntity(entityPtr, actorModel uint32, isPlayer bool) Classification {
+	classifier := aem.getClassifier()
+	rules := classifier.Rules()
+
+	// Read race string (format: "foley_<race>")
 	raceData := make([]byte, 32)
-	aem.mainManager.readBytes(uintptr(entityPtr+0x370), raceData)
+	aem.mainManager.readBytes(uintptr(entityPtr+uint32(rules.RaceOffset)), raceData)
 
 	// Parse string until null terminator
 	raceStr := ""
@@ -258,27 +305,25 @@ func (aem *AllEntitiesManager) getRaceAndFaction(entityPtr uint32) (race, factio
 		raceStr += string(b)
 	}
 
-	// Extract race from "foley_<race>" format
-	if len(raceStr) > 6 && raceStr[:6] == "foley_" {
-		race = raceStr[6:]
-	} else {
-		race = raceStr
+	in := ClassifyInput{
+		RaceString: raceStr,
+		IsPlayer:   isPlayer,
+		ReadEntity: func(off uint32) uint32 {
+			return aem.mainManager.readU32(uintptr(entityPtr + off))
+		},
 	}
-
-	// Determine faction based on race
-	switch race {
-	case "nuian", "elf", "dwarf":
-		faction = "west"
-	case "hariharan", "firran", "ferre", "returned", "warborn":
-		faction = "east"
-	case "player":
-		// "foley_player" = humanoid NPC, not a real player
-		faction = "npc"
-	default:
-		faction = "unknown"
+	if actorModel != 0 {
+		in.ReadActorModel = func(off uint32) uint32 {
+			return aem.mainManager.readU32(uintptr(actorModel + off))
+		}
 	}
+	return classifier.Classify(in)
+}
 
-	return race, faction
+// getRaceAndFaction reads race string and determines faction
+func (aem *AllEntitiesManager) getRaceAndFaction(entityPtr, actorModel uint32) (race, faction string) {
+	c := aem.classifyEntity(entityPtr, actorModel, true)
+	return c.Race, c.Faction
 }
 
 // updateLoop is the dedicated goroutine that continuously updates the cache
@@ -447,13 +492,12 @@ func (aem *AllEntitiesManager) processCollectedEntities(collected map[uint32]boo
 			isNPC = false
 		}
 
-		// Read race string from E+0x370 (format: "foley_<race>")
-		race := ""
-		faction := ""
+		// Classify race/faction (classification_rules.json)
+		var class Classification
 		if isPlayer {
-			race, faction = aem.getRaceAndFaction(entityPtr)
+			class = aem.classifyEntity(entityPtr, actorModel, isPlayer)
 			// "foley_player" means humanoid NPC, not a real player
-			if faction == "npc" {
+			if class.Faction == "npc" {
 				isPlayer = false
 				isNPC = true
 			}
@@ -474,8 +518,10 @@ func (aem *AllEntitiesManager) processCollectedEntities(collected map[uint32]boo
 			IsPlayer:       isPlayer,
 			IsNPC:          isNPC,
 			IsMate:         isMate,
-			Race:           race,
-			Faction:        faction,
+			Race:           class.Race,
+			Faction:        class.Faction,
+			FactionConf:    class.Confidence,
+			FactionReason:  class.Reason,
 		})
 	}
 
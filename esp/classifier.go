@@ -0,0 +1,314 @@
+package esp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Race / faction classification (data-driven)
+// ============================================================================
+
+// Hex aceita offsets/valores no JSON tanto como número quanto como string "0x370".
+type Hex uint32
+
+func (h *Hex) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseUint(strings.TrimSpace(s), 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid hex value %q: %v", s, err)
+		}
+		*h = Hex(v)
+		return nil
+	}
+
+	var n uint32
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid hex value %s", string(data))
+	}
+	*h = Hex(n)
+	return nil
+}
+
+func (h Hex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("0x%X", uint32(h)))
+}
+
+// RaceRule mapeia uma race string (ex: "foley_elf") para raça/facção.
+type RaceRule struct {
+	Pattern    string  `json:"pattern"`              // texto comparado com a race string (sem prefixo)
+	Match      string  `json:"match,omitempty"`      // "exact" (padrão), "prefix" ou "contains"
+	Race       string  `json:"race,omitempty"`       // nome da raça (padrão: o próprio pattern)
+	Faction    string  `json:"faction"`              // "west", "east", "npc", ...
+	Confidence float32 `json:"confidence,omitempty"` // 0..1 (padrão 0.9)
+}
+
+// OffsetRule classifica pela leitura de um uint32 na Entity ou no ActorModel.
+// Cobre tanto flags (Mask != 0) quanto IDs de facção (Value exato).
+type OffsetRule struct {
+	Name       string  `json:"name"`
+	Base       string  `json:"base"`           // "entity" ou "actor_model"
+	Offset     Hex     `json:"offset"`         // offset a partir da base
+	Mask       Hex     `json:"mask,omitempty"` // 0 = compara o valor inteiro
+	Value      Hex     `json:"value"`          // valor esperado após a máscara
+	Faction    string  `json:"faction"`
+	Confidence float32 `json:"confidence,omitempty"` // 0..1 (padrão 0.95)
+	PlayerOnly bool    `json:"player_only,omitempty"`
+}
+
+// ClassificationRules é o conteúdo de classification_rules.json.
+type ClassificationRules struct {
+	RaceOffset     Hex          `json:"race_offset"` // E+0x370 (string "foley_<race>")
+	RacePrefix     string       `json:"race_prefix"` // "foley_"
+	Races          []RaceRule   `json:"races"`
+	Flags          []OffsetRule `json:"flags"`
+	FactionIDs     []OffsetRule `json:"faction_ids"`
+	DefaultFaction string       `json:"default_faction"`
+	Note           string       `json:"note,omitempty"`
+}
+
+// Classification é o resultado do classificador.
+type Classification struct {
+	Race       string
+	Faction    string
+	Confidence float32 // 0..1
+	Reason     string  // qual regra decidiu (para debug)
+}
+
+// ClassifyInput são os dados que o classificador precisa de uma entidade.
+// As funções de leitura recebem o offset relativo à base correspondente.
+type ClassifyInput struct {
+	RaceString     string
+	IsPlayer       bool
+	ReadEntity     func(offset uint32) uint32
+	ReadActorModel func(offset uint32) uint32
+}
+
+const (
+	defaultRaceConfidence   float32 = 0.9
+	defaultOffsetConfidence float32 = 0.95
+)
+
+// DefaultClassificationRules reproduz a classificação hardcoded original.
+func DefaultClassificationRules() ClassificationRules {
+	return ClassificationRules{
+		RaceOffset: 0x370,
+		RacePrefix: "foley_",
+		Races: []RaceRule{
+			{Pattern: "nuian", Faction: "west"},
+			{Pattern: "elf", Faction: "west"},
+			{Pattern: "dwarf", Faction: "west"},
+			{Pattern: "hariharan", Faction: "east"},
+			{Pattern: "firran", Faction: "east"},
+			{Pattern: "ferre", Faction: "east"},
+			{Pattern: "returned", Faction: "east"},
+			{Pattern: "warborn", Faction: "east"},
+			// "foley_player" = humanoid NPC, não é player real
+			{Pattern: "player", Faction: "npc", Confidence: 1.0},
+		},
+		Flags:          []OffsetRule{},
+		FactionIDs:     []OffsetRule{},
+		DefaultFaction: "unknown",
+	}
+}
+
+// LoadClassificationRules carrega e valida as regras de um arquivo JSON
+func LoadClassificationRules(filename string) (*ClassificationRules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := DefaultClassificationRules()
+	// Listas vêm inteiras do arquivo (não mescla com os defaults); sem a
+	// chave "races" ficam as raças embutidas
+	rules.Races = nil
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filename, err)
+	}
+	if rules.Races == nil {
+		rules.Races = DefaultClassificationRules().Races
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules in %s: %v", filename, err)
+	}
+	return &rules, nil
+}
+
+// SaveClassificationRules salva as regras em JSON
+func SaveClassificationRules(filename string, rules *ClassificationRules) error {
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Validate verifica se as regras são consistentes.
+func (r *ClassificationRules) Validate() error {
+	if r.RaceOffset == 0 || r.RaceOffset > 0x10000 {
+		return fmt.Errorf("race_offset 0x%X out of range", uint32(r.RaceOffset))
+	}
+
+	// Sem raças toda entidade cai no default_faction
+	if len(r.Races) == 0 {
+		return fmt.Errorf("races: empty")
+	}
+
+	seen := make(map[string]bool)
+	for i, rr := range r.Races {
+		if rr.Pattern == "" {
+			return fmt.Errorf("races[%d]: empty pattern", i)
+		}
+		if rr.Faction == "" {
+			return fmt.Errorf("races[%d] (%s): empty faction", i, rr.Pattern)
+		}
+		switch rr.Match {
+		case "", "exact", "prefix", "contains":
+		default:
+			return fmt.Errorf("races[%d] (%s): unknown match %q", i, rr.Pattern, rr.Match)
+		}
+		if rr.Confidence < 0 || rr.Confidence > 1 {
+			return fmt.Errorf("races[%d] (%s): confidence must be 0..1", i, rr.Pattern)
+		}
+		key := rr.Match + ":" + strings.ToLower(rr.Pattern)
+		if seen[key] {
+			return fmt.Errorf("races[%d]: duplicate pattern %q", i, rr.Pattern)
+		}
+		seen[key] = true
+	}
+
+	for _, group := range []struct {
+		name  string
+		rules []OffsetRule
+	}{{"flags", r.Flags}, {"faction_ids", r.FactionIDs}} {
+		for i, or := range group.rules {
+			if or.Base != "entity" && or.Base != "actor_model" {
+				return fmt.Errorf("%s[%d] (%s): base must be \"entity\" or \"actor_model\"", group.name, i, or.Name)
+			}
+			if or.Offset > 0x10000 {
+				return fmt.Errorf("%s[%d] (%s): offset 0x%X out of range", group.name, i, or.Name, uint32(or.Offset))
+			}
+			if or.Faction == "" {
+				return fmt.Errorf("%s[%d] (%s): empty faction", group.name, i, or.Name)
+			}
+			if or.Confidence < 0 || or.Confidence > 1 {
+				return fmt.Errorf("%s[%d] (%s): confidence must be 0..1", group.name, i, or.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Classifier aplica ClassificationRules a uma entidade.
+type Classifier struct {
+	rules ClassificationRules
+}
+
+// NewClassifier cria um classificador (regras devem estar validadas)
+func NewClassifier(rules ClassificationRules) *Classifier {
+	return &Classifier{rules: rules}
+}
+
+// Rules retorna uma cópia das regras em uso
+func (c *Classifier) Rules() ClassificationRules {
+	return c.rules
+}
+
+// ParseRace remove o prefixo ("foley_") da race string.
+func (c *Classifier) ParseRace(raceStr string) string {
+	if c.rules.RacePrefix != "" && strings.HasPrefix(raceStr, c.rules.RacePrefix) {
+		return raceStr[len(c.rules.RacePrefix):]
+	}
+	return raceStr
+}
+
+// Classify decide raça e facção. Regras de offset (flags / faction IDs) com
+// confiança maior ou igual sobrescrevem a facção inferida pela raça.
+func (c *Classifier) Classify(in ClassifyInput) Classification {
+	race := c.ParseRace(in.RaceString)
+	result := Classification{
+		Race:    race,
+		Faction: c.rules.DefaultFaction,
+		Reason:  fmt.Sprintf("no rule for race %q", race),
+	}
+
+	for _, rr := range c.rules.Races {
+		if !matchRace(race, rr) {
+			continue
+		}
+		conf := rr.Confidence
+		if conf == 0 {
+			conf = defaultRaceConfidence
+		}
+		if rr.Race != "" {
+			result.Race = rr.Race
+		}
+		result.Faction = rr.Faction
+		result.Confidence = conf
+		result.Reason = fmt.Sprintf("race %q matches %s %q", race, matchMode(rr.Match), rr.Pattern)
+		break
+	}
+
+	for _, group := range [][]OffsetRule{c.rules.FactionIDs, c.rules.Flags} {
+		for _, or := range group {
+			if or.PlayerOnly && !in.IsPlayer {
+				continue
+			}
+			read := in.ReadEntity
+			if or.Base == "actor_model" {
+				read = in.ReadActorModel
+			}
+			if read == nil {
+				continue
+			}
+
+			val := read(uint32(or.Offset))
+			if or.Mask != 0 {
+				val &= uint32(or.Mask)
+			}
+			if val != uint32(or.Value) {
+				continue
+			}
+
+			conf := or.Confidence
+			if conf == 0 {
+				conf = defaultOffsetConfidence
+			}
+			if conf < result.Confidence {
+				continue
+			}
+			result.Faction = or.Faction
+			result.Confidence = conf
+			result.Reason = fmt.Sprintf("%s: %s+0x%X == 0x%X", or.Name, or.Base, uint32(or.Offset), uint32(or.Value))
+		}
+	}
+
+	return result
+}
+
+func matchRace(race string, rr RaceRule) bool {
+	r := strings.ToLower(race)
+	p := strings.ToLower(rr.Pattern)
+	switch rr.Match {
+	case "prefix":
+		return strings.HasPrefix(r, p)
+	case "contains":
+		return strings.Contains(r, p)
+	default:
+		return r == p
+	}
+}
+
+func matchMode(m string) string {
+	if m == "" {
+		return "exact"
+	}
+	return m
+}
@@ -0,0 +1,115 @@
+package esp_test
+
+import (
+	"archefriend/esp"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShippedClassificationRules(t *testing.T) {
+	// O classification_rules.json do repo classifica igual aos defaults
+	rules, err := esp.LoadClassificationRules(filepath.Join("..", "classification_rules.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := esp.NewClassifier(*rules)
+	def := esp.NewClassifier(esp.DefaultClassificationRules())
+	for _, race := range []string{"foley_nuian", "foley_elf", "foley_firran", "foley_warborn", "foley_player", "foley_pirate", "dragon"} {
+		in := esp.ClassifyInput{RaceString: race, IsPlayer: true}
+		if got, want := file.Classify(in), def.Classify(in); got.Faction != want.Faction || got.Race != want.Race {
+			t.Fatalf("%s: file %+v, defaults %+v", race, got, want)
+		}
+	}
+}
+
+func TestClassifyOffsetRules(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.json")
+	if err := os.WriteFile(file, []byte(`{
+		"race_offset": "0x370",
+		"race_prefix": "foley_",
+		"races": [{"pattern": "elf", "faction": "west"}, {"pattern": "war", "match": "prefix", "faction": "east"}],
+		"flags": [{"name": "hostile", "base": "entity", "offset": "0x40", "mask": "0x4", "value": "0x4", "faction": "hostile", "confidence": 0.95, "player_only": true}],
+		"faction_ids": [{"name": "low", "base": "actor_model", "offset": 16, "value": 7, "faction": "low", "confidence": 0.5}],
+		"default_faction": "unknown"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := esp.LoadClassificationRules(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := esp.NewClassifier(*rules)
+	read := func(values map[uint32]uint32) func(uint32) uint32 {
+		return func(offset uint32) uint32 { return values[offset] }
+	}
+
+	for _, tc := range []struct {
+		name    string
+		in      esp.ClassifyInput
+		faction string
+	}{
+		{"race", esp.ClassifyInput{RaceString: "foley_elf", IsPlayer: true}, "west"},
+		{"prefix", esp.ClassifyInput{RaceString: "foley_warborn", IsPlayer: true}, "east"},
+		{"unknown race", esp.ClassifyInput{RaceString: "foley_orc"}, "unknown"},
+		{"flag mask", esp.ClassifyInput{RaceString: "foley_elf", IsPlayer: true, ReadEntity: read(map[uint32]uint32{0x40: 0x6})}, "hostile"},
+		{"flag player only", esp.ClassifyInput{RaceString: "foley_elf", ReadEntity: read(map[uint32]uint32{0x40: 0x4})}, "west"},
+		{"low confidence id", esp.ClassifyInput{RaceString: "foley_elf", IsPlayer: true, ReadActorModel: read(map[uint32]uint32{16: 7})}, "west"},
+		{"id without race", esp.ClassifyInput{RaceString: "foley_orc", ReadActorModel: read(map[uint32]uint32{16: 7})}, "low"},
+	} {
+		if got := c.Classify(tc.in); got.Faction != tc.faction {
+			t.Fatalf("%s: faction %q (%s), want %q", tc.name, got.Faction, got.Reason, tc.faction)
+		}
+	}
+}
+
+func TestLoadWithoutRaces(t *testing.T) {
+	// Sem a chave "races" ficam as raças embutidas; lista vazia é erro
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.json")
+	if err := os.WriteFile(file, []byte(`{"race_offset": "0x370", "race_prefix": "foley_"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := esp.LoadClassificationRules(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := esp.NewClassifier(*rules).Classify(esp.ClassifyInput{RaceString: "foley_firran"}); got.Faction != "east" {
+		t.Fatalf("built-in races not kept: %+v", got)
+	}
+
+	if err := os.WriteFile(file, []byte(`{"race_offset": "0x370", "races": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := esp.LoadClassificationRules(file); err == nil {
+		t.Fatalf("empty races accepted")
+	}
+}
+
+func TestValidateClassificationRules(t *testing.T) {
+	for name, mutate := range map[string]func(*esp.ClassificationRules){
+		"race offset": func(r *esp.ClassificationRules) { r.RaceOffset = 0 },
+		"empty faction": func(r *esp.ClassificationRules) {
+			r.Races = append(r.Races, esp.RaceRule{Pattern: "orc"})
+		},
+		"duplicate": func(r *esp.ClassificationRules) {
+			r.Races = append(r.Races, esp.RaceRule{Pattern: "Elf", Faction: "east"})
+		},
+		"match": func(r *esp.ClassificationRules) {
+			r.Races = append(r.Races, esp.RaceRule{Pattern: "orc", Match: "regex", Faction: "east"})
+		},
+		"base": func(r *esp.ClassificationRules) {
+			r.Flags = append(r.Flags, esp.OffsetRule{Name: "x", Base: "player", Faction: "east"})
+		},
+	} {
+		rules := esp.DefaultClassificationRules()
+		mutate(&rules)
+		if err := rules.Validate(); err == nil {
+			t.Fatalf("%s: invalid rules accepted", name)
+		}
+	}
+	def := esp.DefaultClassificationRules()
+	if err := def.Validate(); err != nil {
+		t.Fatalf("defaults rejected: %v", err)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build windows
+
 package esp
 
 import (
@@ -349,7 +351,7 @@ func (aem *AllEntitiesManager) DumpFactionData(maxEntities int) []FactionDump {
 
 		// Read name and race
 		name := aem.mainManager.getEntityName(entityPtr)
-		race, faction := aem.getRaceAndFaction(entityPtr)
+		race, faction := aem.getRaceAndFaction(entityPtr, actorModel)
 
 		// Detect entity type
 		actorModelType := aem.mainManager.readU32(uintptr(actorModel + 0x14))
@@ -1,3 +1,5 @@
+//go:build windows
+
 package esp
 
 import (
@@ -242,6 +244,10 @@ type Manager struct {
 	lastTargetX     int32
 	lastTargetY     int32
 
+	// Leash do bot (anchor + raio), desenhado quando configurado
+	leashMu       sync.Mutex
+	leashProvider LeashProvider
+
 	// Checkbox UI
 	checkboxPlayerX int32
 	checkboxPlayerY int32
@@ -285,6 +291,8 @@ type EntityInfo struct {
 	Distance       float32
 	Race           string // e.g. "elf", "nuian", "hariharan", "firran"
 	Faction        string // "west", "east", "pirate"
+	FactionConf    float32 // classifier confidence (0..1)
+	FactionReason  string  // rule that decided the faction
 }
 
 func wndProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
@@ -927,6 +935,9 @@ func (m *Manager) renderLoop() {
 			m.lastTargetY = 0
 		}
 
+		// Leash do bot (anchor + círculo)
+		m.drawLeash(playerX, playerY, playerZ)
+
 		// All Entities ESP (additional, when enabled)
 		// Don't render All Entities if overlay is hidden
 		// to avoid race condition in WorldToScreen
@@ -1374,6 +1385,29 @@ func (m *Manager) GetFactionFilters() (west, east, pirate bool) {
 	return m.allEntitiesManager.GetFactionFilters()
 }
 
+// GetFilters returns all filter states
+func (m *Manager) GetFilters() Filters {
+	return m.allEntitiesManager.GetFilters()
+}
+
+// SetFilters replaces all filter states
+func (m *Manager) SetFilters(f Filters) {
+	m.allEntitiesManager.SetFilters(f)
+}
+
+// LoadClassificationRules loads race/faction rules from JSON.
+// On error the current rules are kept.
+func (m *Manager) LoadClassificationRules(filename string) error {
+	rules, err := LoadClassificationRules(filename)
+	if err != nil {
+		return err
+	}
+	m.allEntitiesManager.SetClassifier(NewClassifier(*rules))
+	fmt.Printf("[ESP] Classification rules loaded: %d races, %d flags, %d faction IDs\n",
+		len(rules.Races), len(rules.Flags), len(rules.FactionIDs))
+	return nil
+}
+
 // InstallPersistentHook instala o hook permanente
 // ============================================================================
 // Aimbot Functions
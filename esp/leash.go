@@ -0,0 +1,76 @@
+//go:build windows
+
+package esp
+
+import (
+	"fmt"
+	"math"
+)
+
+// leashSegments é o número de segmentos do círculo do leash (cada ponto
+// custa um WorldToScreen no processo do jogo).
+const leashSegments = 24
+
+// COLOR_LEASH - ciano (BGR)
+const COLOR_LEASH = 0x00FFFF00
+
+// LeashProvider retorna o anchor e o raio do leash do bot (ok = false se
+// desativado).
+type LeashProvider func() (x, y, z, radius float32, ok bool)
+
+// SetLeashProvider liga o desenho do anchor/leash no overlay (nil desliga).
+func (m *Manager) SetLeashProvider(fn LeashProvider) {
+	m.leashMu.Lock()
+	defer m.leashMu.Unlock()
+	m.leashProvider = fn
+}
+
+// drawLeash desenha o anchor e o círculo do leash no chão (altura do anchor).
+// Segmentos com ponta atrás da câmera ou fora da tela são pulados.
+func (m *Manager) drawLeash(playerX, playerY, playerZ float32) {
+	m.leashMu.Lock()
+	provider := m.leashProvider
+	m.leashMu.Unlock()
+	if provider == nil {
+		return
+	}
+	ax, ay, az, radius, ok := provider()
+	if !ok || radius <= 0 {
+		return
+	}
+
+	// Anchor
+	if px, py, visible := m.projectGround(ax, ay, az); visible {
+		dist := CalculateDistance(playerX, playerY, playerZ, ax, ay, az)
+		m.drawCircle(px, py, 6, COLOR_LEASH)
+		m.drawText(px-30, py+10, fmt.Sprintf("ANCHOR %.0fm", dist), COLOR_LEASH)
+	}
+
+	// Círculo
+	var prevX, prevY int32
+	prevVisible := false
+	for i := 0; i <= leashSegments; i++ {
+		angle := float64(i) / leashSegments * 2 * math.Pi
+		x := ax + radius*float32(math.Cos(angle))
+		y := ay + radius*float32(math.Sin(angle))
+		px, py, visible := m.projectGround(x, y, az)
+		if visible && prevVisible {
+			m.drawOutlinedLine(prevX, prevY, px, py, COLOR_LEASH, 1)
+		}
+		prevX, prevY, prevVisible = px, py, visible
+	}
+}
+
+// projectGround converte um ponto do mundo em pixels (false se atrás da
+// câmera ou fora da tela).
+func (m *Manager) projectGround(x, y, z float32) (int32, int32, bool) {
+	// WorldToScreen (order: X, Z, Y)
+	screenX, screenY, screenZ := m.WorldToScreen(x, z, y)
+	if math.IsNaN(float64(screenZ)) || math.IsInf(float64(screenZ), 0) || screenZ >= 1.0 {
+		return 0, 0, false
+	}
+	if screenX < 0 || screenX > 100 || screenY < 0 || screenY > 100 {
+		return 0, 0, false
+	}
+	return int32(screenX * float32(m.screenW) / 100.0), int32(screenY * float32(m.screenH) / 100.0), true
+}
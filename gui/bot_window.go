@@ -34,6 +34,12 @@ const (
 	IDC_BOT_EDIT_MP_POT_KEY     = 2017
 	IDC_BOT_EDIT_MP_POT_THRESH  = 2018
 	IDC_BOT_CHECK_MP_POT        = 2019
+	// Blacklist controls
+	IDC_BOT_LIST_BLACKLIST      = 2020
+	IDC_BOT_EDIT_BLACKLIST      = 2021
+	IDC_BOT_BUTTON_BL_ADD       = 2022
+	IDC_BOT_BUTTON_BL_REMOVE    = 2023
+	IDC_BOT_BUTTON_BL_TARGET    = 2024
 )
 
 type BotConfigWindow struct {
@@ -62,6 +68,10 @@ type BotConfigWindow struct {
 	editMPPotThresh windows.Handle
 	checkMPPot      windows.Handle
 
+	// Blacklist controls
+	listBlacklist windows.Handle
+	editBlacklist windows.Handle
+
 	visible bool
 	ready   chan bool
 
@@ -562,6 +572,68 @@ func (bw *BotConfigWindow) createControls() {
 	)
 	bw.btnLoad = windows.Handle(hwnd)
 
+	// Blacklist section (right side, below presets)
+	label, _ = syscall.UTF16PtrFromString("═══ BLACKLIST ═══")
+	procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(staticClass)),
+		uintptr(unsafe.Pointer(label)),
+		WS_CHILD|WS_VISIBLE,
+		330, 295, 150, 20,
+		uintptr(bw.hwnd), 0, hInstance, 0,
+	)
+
+	hwnd, _, _ = procCreateWindowExW.Call(
+		0x00000200,
+		uintptr(unsafe.Pointer(listboxClass)),
+		0,
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|0x00200000|0x00100000, // LBS_NOTIFY | WS_VSCROLL
+		330, 320, 140, 140,
+		uintptr(bw.hwnd), IDC_BOT_LIST_BLACKLIST, hInstance, 0,
+	)
+	bw.listBlacklist = windows.Handle(hwnd)
+
+	// Nome do mob ou #ID
+	hwnd, _, _ = procCreateWindowExW.Call(
+		0x00000200,
+		uintptr(unsafe.Pointer(editClass)),
+		0,
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|ES_LEFT|ES_AUTOHSCROLL,
+		330, 465, 140, 22,
+		uintptr(bw.hwnd), IDC_BOT_EDIT_BLACKLIST, hInstance, 0,
+	)
+	bw.editBlacklist = windows.Handle(hwnd)
+
+	btnText, _ = syscall.UTF16PtrFromString("Add")
+	procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(buttonClass)),
+		uintptr(unsafe.Pointer(btnText)),
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|BS_PUSHBUTTON,
+		330, 492, 67, 26,
+		uintptr(bw.hwnd), IDC_BOT_BUTTON_BL_ADD, hInstance, 0,
+	)
+
+	btnText, _ = syscall.UTF16PtrFromString("Remove")
+	procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(buttonClass)),
+		uintptr(unsafe.Pointer(btnText)),
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|BS_PUSHBUTTON,
+		403, 492, 67, 26,
+		uintptr(bw.hwnd), IDC_BOT_BUTTON_BL_REMOVE, hInstance, 0,
+	)
+
+	btnText, _ = syscall.UTF16PtrFromString("Ban Target")
+	procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(buttonClass)),
+		uintptr(unsafe.Pointer(btnText)),
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|BS_PUSHBUTTON,
+		330, 523, 140, 26,
+		uintptr(bw.hwnd), IDC_BOT_BUTTON_BL_TARGET, hInstance, 0,
+	)
+
 	// Load current values
 	bw.loadValues()
 }
@@ -631,11 +703,44 @@ func (bw *BotConfigWindow) loadValues() {
 
 	// Presets
 	procSendMessage.Call(uintptr(bw.listPresets), 0x0184, 0, 0) // LB_RESETCONTENT
-	for name, mobs := range bw.botConfig.Presets {
-		text := fmt.Sprintf("%s (%d mobs)", name, len(mobs))
+	for name, preset := range bw.botConfig.Presets {
+		text := fmt.Sprintf("%s (%d mobs)", name, len(preset.MobNames))
+		if preset.Strategy != "" {
+			text = fmt.Sprintf("%s (%d mobs, %s)", name, len(preset.MobNames), preset.Strategy)
+		}
 		textPtr, _ := syscall.UTF16PtrFromString(text)
 		procSendMessage.Call(uintptr(bw.listPresets), 0x0180, 0, uintptr(unsafe.Pointer(textPtr)))
 	}
+
+	bw.loadBlacklist()
+}
+
+// loadBlacklist preenche a lista: nomes e depois IDs (#123).
+func (bw *BotConfigWindow) loadBlacklist() {
+	procSendMessage.Call(uintptr(bw.listBlacklist), 0x0184, 0, 0) // LB_RESETCONTENT
+	for _, item := range blacklistItems(bw.botConfig.Blacklist) {
+		textPtr, _ := syscall.UTF16PtrFromString(item)
+		procSendMessage.Call(uintptr(bw.listBlacklist), 0x0180, 0, uintptr(unsafe.Pointer(textPtr)))
+	}
+}
+
+func blacklistItems(bl bot.MobBlacklist) []string {
+	items := append([]string(nil), bl.Names...)
+	for _, id := range bl.IDs {
+		items = append(items, fmt.Sprintf("#%d", id))
+	}
+	return items
+}
+
+// parseBlacklistItem converte "#123" em ID e qualquer outro texto em nome.
+func parseBlacklistItem(text string) (string, uint32) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "#") {
+		if id, err := strconv.ParseUint(text[1:], 10, 32); err == nil {
+			return "", uint32(id)
+		}
+	}
+	return text, 0
 }
 
 func (bw *BotConfigWindow) wndProc(hwnd windows.Handle, msg uint32, wParam, lParam uintptr) uintptr {
@@ -652,6 +757,14 @@ func (bw *BotConfigWindow) wndProc(hwnd windows.Handle, msg uint32, wParam, lPar
 				bw.onToggleBot()
 			case IDC_BOT_BUTTON_LOAD:
 				bw.onLoadPreset()
+			case IDC_BOT_BUTTON_BL_ADD:
+				name, id := parseBlacklistItem(bw.getEditText(bw.editBlacklist))
+				bw.onBlacklistAdd(name, id)
+				bw.setEditText(bw.editBlacklist, "")
+			case IDC_BOT_BUTTON_BL_REMOVE:
+				bw.onBlacklistRemove()
+			case IDC_BOT_BUTTON_BL_TARGET:
+				bw.onBlacklistTarget()
 			}
 		}
 
@@ -774,13 +887,18 @@ func (bw *BotConfigWindow) onLoadPreset() {
 
 	// Get preset name by index
 	i := 0
-	for name, mobs := range bw.botConfig.Presets {
+	for name, preset := range bw.botConfig.Presets {
 		if i == int(idx) {
+			mobs := preset.MobNames
 			bw.botConfig.MobNames = mobs
 			bw.setEditText(bw.editMobs, strings.Join(mobs, "\r\n"))
 
 			if bw.botInstance != nil {
-				bw.botInstance.SetMobNames(mobs)
+				if err := bw.botInstance.ApplyPreset(bw.botConfig, preset); err != nil {
+					bw.showMessage("Erro", err.Error())
+					return
+				}
+				bw.botInstance.SetPresetName(name)
 			}
 
 			fmt.Printf("[BOT] Preset '%s' carregado: %v\n", name, mobs)
@@ -790,6 +908,65 @@ func (bw *BotConfigWindow) onLoadPreset() {
 	}
 }
 
+// onBlacklistAdd adiciona à blacklist permanente, aplica no bot e salva.
+func (bw *BotConfigWindow) onBlacklistAdd(name string, id uint32) {
+	if bw.botConfig == nil || (name == "" && id == 0) {
+		return
+	}
+	if !bw.botConfig.Blacklist.Add(name, id) {
+		return
+	}
+	bw.saveBlacklist()
+	fmt.Printf("[BOT] Blacklist +%s\n", formatBlacklistItem(name, id))
+}
+
+func (bw *BotConfigWindow) onBlacklistRemove() {
+	if bw.botConfig == nil {
+		return
+	}
+	idx, _, _ := procSendMessage.Call(uintptr(bw.listBlacklist), 0x0188, 0, 0) // LB_GETCURSEL
+	items := blacklistItems(bw.botConfig.Blacklist)
+	if idx == 0xFFFFFFFF || int(idx) >= len(items) {
+		bw.showMessage("Erro", "Selecione um item da blacklist!")
+		return
+	}
+	name, id := parseBlacklistItem(items[idx])
+	bw.botConfig.Blacklist.Remove(name, id)
+	bw.saveBlacklist()
+	fmt.Printf("[BOT] Blacklist -%s\n", formatBlacklistItem(name, id))
+}
+
+// onBlacklistTarget bane o nome do target atual do bot.
+func (bw *BotConfigWindow) onBlacklistTarget() {
+	if bw.botInstance == nil {
+		return
+	}
+	target := bw.botInstance.GetCurrentTarget()
+	if target == nil {
+		bw.showMessage("Erro", "Bot sem target!")
+		return
+	}
+	bw.onBlacklistAdd(target.Name, 0)
+}
+
+// saveBlacklist aplica a blacklist no bot e persiste no arquivo de config.
+func (bw *BotConfigWindow) saveBlacklist() {
+	if bw.botInstance != nil {
+		bw.botInstance.SetMobBlacklist(bw.botConfig.Blacklist)
+	}
+	if err := bot.SaveFileConfig(bw.configFile, bw.botConfig); err != nil {
+		bw.showMessage("Erro", fmt.Sprintf("Falha ao salvar: %v", err))
+	}
+	bw.loadBlacklist()
+}
+
+func formatBlacklistItem(name string, id uint32) string {
+	if id != 0 {
+		return fmt.Sprintf("#%d", id)
+	}
+	return name
+}
+
 func (bw *BotConfigWindow) getEditText(hwnd windows.Handle) string {
 	length, _, _ := procGetWindowTextLength.Call(uintptr(hwnd))
 	if length == 0 {
@@ -844,3 +1021,8 @@ func (bw *BotConfigWindow) SetBotConfig(cfg *bot.FileConfig) {
 	bw.botConfig = cfg
 	bw.loadValues()
 }
+
+// SetConfigFile troca o arquivo onde a config é salva (perfil do personagem)
+func (bw *BotConfigWindow) SetConfigFile(filename string) {
+	bw.configFile = filename
+}
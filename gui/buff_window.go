@@ -36,6 +36,7 @@ type BuffWindow struct {
 	hwnd            windows.Handle
 	injector        *buff.Injector
 	presetManager   *buff.PresetManager
+	presetsFile     string
 
 	// Controls - Injection
 	editID          windows.Handle
@@ -66,6 +67,7 @@ func NewBuffWindow(injector *buff.Injector, presetManager *buff.PresetManager) (
 	bw := &BuffWindow{
 		injector:      injector,
 		presetManager: presetManager,
+		presetsFile:   "buff_presets.json",
 		ready:         make(chan bool),
 	}
 
@@ -636,7 +638,7 @@ func (bw *BuffWindow) onSavePreset() {
 		return
 	}
 
-	bw.presetManager.SaveToJSON("buff_presets.json")
+	bw.presetManager.SaveToJSON(bw.presetsFile)
 	bw.showMessage("Sucesso", fmt.Sprintf("Preset '%s' salvo!", name))
 	bw.refreshLists()
 }
@@ -660,7 +662,7 @@ func (bw *BuffWindow) onDeletePreset() {
 
 	preset := presets[idx]
 	bw.presetManager.RemovePreset(preset.Name)
-	bw.presetManager.SaveToJSON("buff_presets.json")
+	bw.presetManager.SaveToJSON(bw.presetsFile)
 
 	bw.showMessage("Sucesso", fmt.Sprintf("Preset '%s' deletado!", preset.Name))
 	bw.refreshLists()
@@ -816,3 +818,8 @@ func (bw *BuffWindow) Toggle() {
 		bw.Show()
 	}
 }
+
+// SetPresetsFile troca o arquivo onde os presets são salvos (perfil do personagem)
+func (bw *BuffWindow) SetPresetsFile(filename string) {
+	bw.presetsFile = filename
+}
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"archefriend/reaction"
+	"archefriend/rules"
 	"fmt"
 	"runtime"
 	"strconv"
@@ -26,8 +27,26 @@ const (
 	IDC_BUTTON_EDIT = 1011
 	IDC_BUTTON_CLEAR = 1012
 	IDC_BUTTON_TEST = 1013
+	IDC_CHECK_INCOMBAT = 1014
+	IDC_COMBO_TARGETTYPE = 1015
+	IDC_EDIT_HPBELOW = 1016
+	IDC_EDIT_MINSTACKS = 1017
+	IDC_EDIT_MINDURATION = 1018
+	IDC_EDIT_NOTBUFF = 1019
+	IDC_EDIT_COOLDOWNEND = 1020
+	IDC_EDIT_DEBOUNCE = 1021
 )
 
+// Opções do combo de tipo de target (índice = CB_GETCURSEL)
+var targetTypeOptions = []struct {
+	label string
+	value string
+}{
+	{"Qualquer", ""},
+	{"Player", rules.TargetPlayer},
+	{"NPC", rules.TargetNPC},
+}
+
 type ConfigWindow struct {
 	hwnd            windows.Handle
 	reactionManager *reaction.Manager
@@ -38,8 +57,19 @@ type ConfigWindow struct {
 	editOnStart   windows.Handle
 	editOnEnd     windows.Handle
 	editCooldown  windows.Handle
+	editCooldownEnd windows.Handle
+	editDebounce    windows.Handle
 	checkIsDebuff windows.Handle
 	listReactions windows.Handle
+
+	// Condições
+	checkInCombat   windows.Handle
+	comboTargetType windows.Handle
+	editHPBelow     windows.Handle
+	editMinStacks   windows.Handle
+	editMinDuration windows.Handle
+	editNotBuff     windows.Handle
+
 	btnAdd        windows.Handle
 	btnEdit       windows.Handle
 	btnRemove     windows.Handle
@@ -51,6 +81,9 @@ type ConfigWindow struct {
 	// Passes the reaction ID to main to trigger via TriggerForTest
 	TestReaction func(id uint32)
 
+	// Stats do rules engine (disparos e triggers suprimidos) mostradas na lista
+	RuleStats func() []rules.RuleStats
+
 	visible bool
 	ready   chan bool
 }
@@ -106,7 +139,7 @@ func (cw *ConfigWindow) runWindow() {
 		uintptr(unsafe.Pointer(windowName)),
 		WS_OVERLAPPEDWINDOW,
 		100, 100, // x, y
-		800, 600, // width, height
+		800, 760, // width, height
 		0, 0,
 		hInstance,
 		0,
@@ -292,6 +325,48 @@ func (cw *ConfigWindow) createControls() {
 	procSetWindowText.Call(uintptr(cw.editCooldown), uintptr(unsafe.Pointer(defaultCooldown)))
 	y += 35
 
+	// Cooldown do OnEnd + debounce (vazio = padrão)
+	label, _ = syscall.UTF16PtrFromString("Cooldown OnEnd:")
+	procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(staticClass)),
+		uintptr(unsafe.Pointer(label)),
+		WS_CHILD|WS_VISIBLE,
+		10, uintptr(y), 150, 20,
+		uintptr(cw.hwnd), 0, hInstance, 0,
+	)
+
+	hwnd, _, _ = procCreateWindowExW.Call(
+		0x00000200, // WS_EX_CLIENTEDGE - 3D sunken border
+		uintptr(unsafe.Pointer(editClass)),
+		0,
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|ES_LEFT|ES_AUTOHSCROLL,
+		170, uintptr(y), 70, 25,
+		uintptr(cw.hwnd), IDC_EDIT_COOLDOWNEND, hInstance, 0,
+	)
+	cw.editCooldownEnd = windows.Handle(hwnd)
+
+	label, _ = syscall.UTF16PtrFromString("Debounce (0=off):")
+	procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(staticClass)),
+		uintptr(unsafe.Pointer(label)),
+		WS_CHILD|WS_VISIBLE,
+		250, uintptr(y), 100, 20,
+		uintptr(cw.hwnd), 0, hInstance, 0,
+	)
+
+	hwnd, _, _ = procCreateWindowExW.Call(
+		0x00000200, // WS_EX_CLIENTEDGE - 3D sunken border
+		uintptr(unsafe.Pointer(editClass)),
+		0,
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|ES_LEFT|ES_AUTOHSCROLL,
+		350, uintptr(y), 70, 25,
+		uintptr(cw.hwnd), IDC_EDIT_DEBOUNCE, hInstance, 0,
+	)
+	cw.editDebounce = windows.Handle(hwnd)
+	y += 35
+
 	// IsDebuff Checkbox
 	checkText, _ := syscall.UTF16PtrFromString("É Debuff")
 	hwnd, _, _ = procCreateWindowExW.Call(
@@ -305,6 +380,8 @@ func (cw *ConfigWindow) createControls() {
 	cw.checkIsDebuff = windows.Handle(hwnd)
 	y += 35
 
+	y = cw.createConditionControls(y)
+
 	// Buttons
 	btnText, _ := syscall.UTF16PtrFromString("Adicionar/Atualizar")
 	hwnd, _, _ = procCreateWindowExW.Call(
@@ -398,6 +475,147 @@ func (cw *ConfigWindow) createControls() {
 	cw.refreshList()
 }
 
+// createConditionControls cria os campos das condições opcionais a partir
+// de y e retorna o próximo y. Campo vazio/0 = sem essa condição.
+func (cw *ConfigWindow) createConditionControls(y int) int {
+	hInstance, _, _ := procGetModuleHandle.Call(0)
+
+	buttonClass, _ := syscall.UTF16PtrFromString("BUTTON")
+	editClass, _ := syscall.UTF16PtrFromString("EDIT")
+	staticClass, _ := syscall.UTF16PtrFromString("STATIC")
+	comboClass, _ := syscall.UTF16PtrFromString("COMBOBOX")
+
+	static := func(text string, x, y, w int) {
+		label, _ := syscall.UTF16PtrFromString(text)
+		procCreateWindowExW.Call(
+			0,
+			uintptr(unsafe.Pointer(staticClass)),
+			uintptr(unsafe.Pointer(label)),
+			WS_CHILD|WS_VISIBLE,
+			uintptr(x), uintptr(y), uintptr(w), 20,
+			uintptr(cw.hwnd), 0, hInstance, 0,
+		)
+	}
+	edit := func(id, x, y, w int) windows.Handle {
+		hwnd, _, _ := procCreateWindowExW.Call(
+			0x00000200, // WS_EX_CLIENTEDGE - 3D sunken border
+			uintptr(unsafe.Pointer(editClass)),
+			0,
+			WS_CHILD|WS_VISIBLE|WS_TABSTOP|ES_LEFT|ES_AUTOHSCROLL,
+			uintptr(x), uintptr(y), uintptr(w), 25,
+			uintptr(cw.hwnd), uintptr(id), hInstance, 0,
+		)
+		return windows.Handle(hwnd)
+	}
+
+	static("Condições (vazio = sempre):", 10, y, 250)
+	y += 25
+
+	// Só em combate + tipo de target
+	checkText, _ := syscall.UTF16PtrFromString("Só em combate")
+	hwnd, _, _ := procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(buttonClass)),
+		uintptr(unsafe.Pointer(checkText)),
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|0x00000003, // BS_AUTOCHECKBOX
+		10, uintptr(y), 130, 25,
+		uintptr(cw.hwnd), IDC_CHECK_INCOMBAT, hInstance, 0,
+	)
+	cw.checkInCombat = windows.Handle(hwnd)
+
+	static("Target:", 170, y+3, 50)
+	hwnd, _, _ = procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(comboClass)),
+		0,
+		WS_CHILD|WS_VISIBLE|WS_TABSTOP|0x0003|0x00200000, // CBS_DROPDOWNLIST | WS_VSCROLL
+		230, uintptr(y), 120, 120,
+		uintptr(cw.hwnd), IDC_COMBO_TARGETTYPE, hInstance, 0,
+	)
+	cw.comboTargetType = windows.Handle(hwnd)
+	for _, opt := range targetTypeOptions {
+		text, _ := syscall.UTF16PtrFromString(opt.label)
+		procSendMessage.Call(uintptr(cw.comboTargetType), 0x0143, 0, uintptr(unsafe.Pointer(text))) // CB_ADDSTRING
+	}
+	procSendMessage.Call(uintptr(cw.comboTargetType), 0x014E, 0, 0) // CB_SETCURSEL
+	y += 32
+
+	// HP, stacks e duração
+	static("HP < %:", 10, y+3, 60)
+	cw.editHPBelow = edit(IDC_EDIT_HPBELOW, 70, y, 50)
+	static("Stacks >=", 140, y+3, 70)
+	cw.editMinStacks = edit(IDC_EDIT_MINSTACKS, 210, y, 50)
+	static("Duração >= ms:", 275, y+3, 95)
+	cw.editMinDuration = edit(IDC_EDIT_MINDURATION, 370, y, 50)
+	y += 32
+
+	// Buff que bloqueia a reação
+	static("Não se tiver buff ID:", 10, y+3, 150)
+	cw.editNotBuff = edit(IDC_EDIT_NOTBUFF, 170, y, 150)
+	y += 35
+
+	return y
+}
+
+// getConditions lê os campos de condição.
+func (cw *ConfigWindow) getConditions() rules.ReactionConditions {
+	var c rules.ReactionConditions
+
+	ret, _, _ := procSendMessage.Call(uintptr(cw.checkInCombat), 0x00F0, 0, 0) // BM_GETCHECK
+	c.InCombat = ret == 0x0001 // BST_CHECKED
+
+	idx, _, _ := procSendMessage.Call(uintptr(cw.comboTargetType), 0x0147, 0, 0) // CB_GETCURSEL
+	if int(idx) >= 0 && int(idx) < len(targetTypeOptions) {
+		c.TargetType = targetTypeOptions[idx].value
+	}
+
+	if v, err := strconv.ParseFloat(cw.getEditText(cw.editHPBelow), 32); err == nil && v > 0 {
+		c.HPBelow = float32(v)
+	}
+	if v, err := strconv.ParseUint(cw.getEditText(cw.editMinStacks), 10, 32); err == nil {
+		c.MinStacks = uint32(v)
+	}
+	if v, err := strconv.ParseUint(cw.getEditText(cw.editMinDuration), 10, 32); err == nil {
+		c.MinDurationMs = uint32(v)
+	}
+	if v, err := strconv.ParseUint(cw.getEditText(cw.editNotBuff), 10, 32); err == nil {
+		c.NotBuff = uint32(v)
+	}
+	return c
+}
+
+// setConditions preenche os campos de condição (zero = vazio).
+func (cw *ConfigWindow) setConditions(c rules.ReactionConditions) {
+	check := uintptr(0)
+	if c.InCombat {
+		check = 1
+	}
+	procSendMessage.Call(uintptr(cw.checkInCombat), 0x00F1, check, 0) // BM_SETCHECK
+
+	sel := 0
+	for i, opt := range targetTypeOptions {
+		if opt.value == c.TargetType {
+			sel = i
+		}
+	}
+	procSendMessage.Call(uintptr(cw.comboTargetType), 0x014E, uintptr(sel), 0) // CB_SETCURSEL
+
+	text := func(v uint64, format string) string {
+		if v == 0 {
+			return ""
+		}
+		return fmt.Sprintf(format, v)
+	}
+	hp := ""
+	if c.HPBelow > 0 {
+		hp = strconv.FormatFloat(float64(c.HPBelow), 'f', -1, 32)
+	}
+	cw.setEditText(cw.editHPBelow, hp)
+	cw.setEditText(cw.editMinStacks, text(uint64(c.MinStacks), "%d"))
+	cw.setEditText(cw.editMinDuration, text(uint64(c.MinDurationMs), "%d"))
+	cw.setEditText(cw.editNotBuff, text(uint64(c.NotBuff), "%d"))
+}
+
 func (cw *ConfigWindow) refreshList() {
 	// Clear list
 	procSendMessage.Call(
@@ -408,6 +626,13 @@ func (cw *ConfigWindow) refreshList() {
 
 	reactions := cw.reactionManager.GetAllReactions()
 
+	stats := make(map[string]rules.RuleStats)
+	if cw.RuleStats != nil {
+		for _, s := range cw.RuleStats() {
+			stats[s.ID] = s
+		}
+	}
+
 	// Debug: mostrar ordem das reactions
 	fmt.Printf("[CONFIG] RefreshList: %d reactions\n", len(reactions))
 	for i, r := range reactions {
@@ -419,6 +644,17 @@ func (cw *ConfigWindow) refreshList() {
 		fmt.Printf("[CONFIG]   [%d] ID:%d %s\n", i, r.ID, r.Name)
 
 		text := fmt.Sprintf("[%s] ID:%d %s -> %s", typeStr, r.ID, r.Name, r.UseString)
+		if !r.Conditions.Empty() {
+			text += fmt.Sprintf(" [%s]", r.Conditions)
+		}
+
+		// Disparos e suprimidos (cooldown/debounce/condição) de OnStart+OnEnd
+		start := stats[rules.ReactionRuleID(r.IsDebuff, r.ID, "start")]
+		end := stats[rules.ReactionRuleID(r.IsDebuff, r.ID, "end")]
+		if fired, suppressed := start.Fired+end.Fired, start.Suppressed()+end.Suppressed(); fired+suppressed > 0 {
+			text += fmt.Sprintf(" (x%d, sup cd:%d db:%d cond:%d)", fired,
+				start.Cooldown+end.Cooldown, start.Debounce+end.Debounce, start.Conditions+end.Conditions)
+		}
 		textPtr, _ := syscall.UTF16PtrFromString(text)
 
 		procSendMessage.Call(
@@ -483,6 +719,8 @@ func (cw *ConfigWindow) onAddReaction() {
 	onStart := cw.getEditText(cw.editOnStart)
 	onEnd := cw.getEditText(cw.editOnEnd)
 	cooldownStr := cw.getEditText(cw.editCooldown)
+	cooldownEnd, _ := strconv.Atoi(cw.getEditText(cw.editCooldownEnd)) // vazio = cooldown
+	debounce, _ := strconv.Atoi(cw.getEditText(cw.editDebounce))       // vazio = padrão, -1 = desligado
 
 	// Parse ID
 	buffID, err := strconv.Atoi(id)
@@ -509,7 +747,7 @@ func (cw *ConfigWindow) onAddReaction() {
 	}
 
 	// Check if already exists
-	_, exists := cw.reactionManager.GetReaction(uint32(buffID))
+	old, exists := cw.reactionManager.GetReaction(uint32(buffID))
 
 	// Create reaction
 	r := &reaction.Reaction{
@@ -519,6 +757,13 @@ func (cw *ConfigWindow) onAddReaction() {
 		OnEndString:  onEnd,
 		IsDebuff:     isDebuff,
 		CooldownMS:   cooldown,
+		CooldownEndMS: cooldownEnd,
+		DebounceMS:   debounce,
+		Conditions:   cw.getConditions(),
+	}
+	// Confirmação só é editada no JSON: mantém a da reação existente
+	if exists {
+		r.Confirm, r.ConfirmEnd = old.Confirm, old.ConfirmEnd
 	}
 
 	// Add to manager
@@ -589,6 +834,14 @@ func (cw *ConfigWindow) onEditReaction() {
 	cw.setEditText(cw.editOnStart, r.UseString)
 	cw.setEditText(cw.editOnEnd, r.OnEndString)
 	cw.setEditText(cw.editCooldown, fmt.Sprintf("%d", r.CooldownMS))
+	cw.setEditText(cw.editCooldownEnd, "")
+	if r.CooldownEndMS != 0 {
+		cw.setEditText(cw.editCooldownEnd, fmt.Sprintf("%d", r.CooldownEndMS))
+	}
+	cw.setEditText(cw.editDebounce, "")
+	if r.DebounceMS != 0 {
+		cw.setEditText(cw.editDebounce, fmt.Sprintf("%d", r.DebounceMS))
+	}
 
 	// Set checkbox
 	checkValue := uintptr(0)
@@ -601,6 +854,8 @@ func (cw *ConfigWindow) onEditReaction() {
 		checkValue,
 		0,
 	)
+
+	cw.setConditions(r.Conditions)
 }
 
 func (cw *ConfigWindow) onRemoveReaction() {
@@ -724,6 +979,8 @@ func (cw *ConfigWindow) clearFields() {
 	cw.setEditText(cw.editOnStart, "")
 	cw.setEditText(cw.editOnEnd, "")
 	cw.setEditText(cw.editCooldown, "1000")
+	cw.setEditText(cw.editCooldownEnd, "")
+	cw.setEditText(cw.editDebounce, "")
 
 	// Uncheck isDebuff
 	procSendMessage.Call(
@@ -732,6 +989,8 @@ func (cw *ConfigWindow) clearFields() {
 		0,
 		0,
 	)
+
+	cw.setConditions(rules.ReactionConditions{})
 }
 
 func (cw *ConfigWindow) showMessage(title, message string) {
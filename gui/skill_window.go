@@ -573,6 +573,10 @@ func (sw *SkillConfigWindow) onAddReaction() {
 		UseAimbot:   useAimbot,
 		AimbotOnTry: aimbotOnTry,
 	}
+	// Confirmação só é editada no JSON: mantém a da reação existente
+	if existing != nil {
+		r.Confirm = existing.Confirm
+	}
 
 	// Add to manager
 	sw.reactionManager.AddReaction(r)
@@ -882,3 +886,8 @@ func (sw *SkillConfigWindow) Toggle() {
 		sw.Show()
 	}
 }
+
+// SetConfigPath troca o arquivo onde as reações são salvas (perfil do personagem)
+func (sw *SkillConfigWindow) SetConfigPath(configPath string) {
+	sw.configPath = configPath
+}
@@ -442,6 +442,7 @@ type Manager struct {
 	autoSpamming  bool
 	gameHwnd      uintptr         // Handle da janela do jogo
 	sendToWindow  bool            // Se true, usa PostMessage; se false, usa SendInput
+	submit        func(keys [][]uint16) error // scheduler de input (nil = envia direto)
 }
 
 // NewManager cria um novo input manager
@@ -466,6 +467,12 @@ func (m *Manager) SetGameWindow(hwnd uintptr) {
 	m.sendToWindow = (hwnd != 0)
 }
 
+// SetSubmitter faz o autospam entregar os combos ao scheduler de input em vez
+// de enviar direto, para não intercalar com as outras teclas
+func (m *Manager) SetSubmitter(fn func(keys [][]uint16) error) {
+	m.submit = fn
+}
+
 // SetInterval define o intervalo entre envios
 func (m *Manager) SetInterval(interval time.Duration) {
 	m.interval = interval
@@ -478,6 +485,9 @@ func (m *Manager) GetKeys() [][]uint16 {
 
 // SendSingle envia todas as teclas configuradas uma vez
 func (m *Manager) SendSingle() error {
+	if m.submit != nil {
+		return m.submit(m.keys)
+	}
 	for _, combo := range m.keys {
 		if m.sendToWindow {
 			m.sendComboToWindow(combo)
@@ -556,6 +566,10 @@ func (m *Manager) StartAutoSpam() {
 			case <-m.stopChan:
 				return
 			case <-ticker.C:
+				if m.submit != nil {
+					m.submit(m.keys)
+					continue
+				}
 				for _, combo := range m.keys {
 					if m.sendToWindow {
 						m.sendComboToWindow(combo)
@@ -764,6 +778,30 @@ func SendKeyStringToWindow(hwnd uintptr, keyStr string) error {
 	return SendKeyComboToWindow(hwnd, keys)
 }
 
+// SetKeyComboStateToWindow segura ou solta um combo já parseado na janela.
+func SetKeyComboStateToWindow(hwnd uintptr, keys []uint16, down bool) error {
+	if hwnd == 0 {
+		return fmt.Errorf("hwnd inválido")
+	}
+
+	const (
+		WM_KEYDOWN = 0x0100
+		WM_KEYUP   = 0x0101
+	)
+	procPostMessage := user32.NewProc("PostMessageW")
+
+	if down {
+		for _, vk := range keys {
+			procPostMessage.Call(hwnd, WM_KEYDOWN, uintptr(vk), makeLParam(vk, false))
+		}
+		return nil
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		procPostMessage.Call(hwnd, WM_KEYUP, uintptr(keys[i]), makeLParam(keys[i], true))
+	}
+	return nil
+}
+
 // SendKeySequenceToWindow sends a sequence of combos to the window via PostMessage
 // Each combo is sent with a 50ms delay between them
 func SendKeySequenceToWindow(hwnd uintptr, sequence [][]uint16) error {
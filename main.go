@@ -3,6 +3,7 @@
 package main
 
 import (
+	"archefriend/action"
 	"archefriend/afk"
 	"archefriend/bot"
 	"archefriend/buff"
@@ -15,10 +16,16 @@ import (
 	"archefriend/monitor"
 	"archefriend/patch"
 	"archefriend/process"
+	"archefriend/profile"
 	"archefriend/reaction"
+	"archefriend/rules"
+	"archefriend/sched"
 	"archefriend/skill"
 	"archefriend/target"
+	"archefriend/zone"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
@@ -44,7 +51,11 @@ type App struct {
 
 	lootBypass      *loot.Bypass
 	inputManager    *input.Manager
+	keys            *sched.Scheduler // única saída de teclas para o jogo (lanes por prioridade)
 	reactionManager *reaction.Manager
+	actions         *action.Executor // reações/guardian com confirm (o bot tem o seu)
+	rules           *rules.Engine    // dispara reações de buff/debuff/skill e rules.json
+	lastTargetID    uint32           // target_change do rules engine
 	afkMonitor      *afk.Monitor
 	buffMonitor     *monitor.BuffMonitor
 	debuffMonitor   *monitor.DebuffMonitor
@@ -57,9 +68,21 @@ type App struct {
 	keybinds             *config.KeybindsConfig
 	targetScanner        *esp.TargetScanner
 
+	// Perfil do personagem (profiles/<nome>/, NUMPAD/ troca)
+	profile       profile.Profile
+	characterName string // último nome lido do player local
+
 	// Bot
 	botInstance  *bot.Bot
 	botConfig    *bot.FileConfig
+	botRecorder  *bot.Recorder
+	guardian     *bot.Guardian // só potions/thresholds, sem targeting (NUMPAD6)
+
+	// Zonas (zones.json): preset/reações/filtros do ESP pela posição
+	zoneConfig   *zone.Config
+	zoneTracker  *zone.Tracker
+	zoneFile     string         // zones.json do perfil (ou compartilhado)
+	zoneRecorder *zone.Recorder // gravando contorno (NUMPAD*)
 
 	window            *gui.OverlayWindow
 	configWindow      *gui.ConfigWindow
@@ -112,6 +135,10 @@ func NewApp() (*App, error) {
 	app.pid = pid
 	app.connected = true
 
+	// Perfil do personagem antes de carregar as configs (sem player no
+	// mundo ainda = compartilhado até o monitorLoop ler o nome)
+	app.detectCharacter(false)
+
 	// Aplicar patches de mount + GCD
 	app.patchManager = patch.NewManager(handle, x2game)
 	app.patchManager.ApplyAll()
@@ -120,10 +147,14 @@ func NewApp() (*App, error) {
 	app.gameHwnd = findWindowByPID(pid)
 
 	app.lootBypass = loot.NewBypass(handle, x2game)
+	app.initInputScheduler()
 	app.inputManager = input.NewManager()
 
 	// Configurar inputManager para enviar para a janela do ArcheAge
 	app.inputManager.SetGameWindow(app.gameHwnd)
+	app.inputManager.SetSubmitter(func(keys [][]uint16) error {
+		return app.keys.Post(sched.LaneAutospam, "autospam", keys)
+	})
 	// Configurar teclas padrão: V e SHIFT+F
 	app.inputManager.SetKeys([][]uint16{
 		{input.VK_V},
@@ -139,8 +170,19 @@ func NewApp() (*App, error) {
 		}
 	}
 	app.afkMonitor.Start()
+	// Ações com confirm: repetem até o cast/buff/target/recurso confirmar
+	app.actions = action.NewExecutor(action.DefaultPolicy(), nil, nil)
+	app.actions.HasBuff = func(id uint32) bool {
+		return app.buffMonitor != nil && app.buffMonitor.HasBuff(id)
+	}
+	app.actions.TargetID = func() uint32 {
+		id, _ := target.GetCurrentTargetId(app.handle, app.x2game)
+		return id
+	}
+	app.actions.Resource = func(name string) (float32, bool) {
+		return app.resources().Read(name)
+	}
 	app.reactionManager = reaction.NewManager()
-	app.reactionManager.SetAFKChecker(app.afkMonitor)
 	app.buffMonitor = monitor.NewBuffMonitor(handle, x2game)
 	app.debuffMonitor = monitor.NewDebuffMonitor(handle, x2game)
 	app.targetMonitor = target.NewMonitor(handle, x2game)
@@ -163,6 +205,11 @@ func NewApp() (*App, error) {
 			espMgr.SetAimbotKeys([]int{0x05, 0x06})
 		}
 
+		// Regras de raça/facção (sem arquivo = regras padrão)
+		if err := espMgr.LoadClassificationRules("classification_rules.json"); err != nil {
+			fmt.Printf("[ESP] Classification rules: %v (usando padrão)\n", err)
+		}
+
 		// Iniciar ambos ESPs por padrão
 		espMgr.Enable()
 		espMgr.ToggleAllEntities()
@@ -182,31 +229,28 @@ func NewApp() (*App, error) {
 
 	// Create Skill reaction manager
 	app.skillReactionManager = skill.NewReactionManager()
-	if err := app.skillReactionManager.LoadFromJSON("skill_reactions.json"); err != nil {
+	skillReactionsFile := app.configPath("skill_reactions.json")
+	if err := app.skillReactionManager.LoadFromJSON(skillReactionsFile); err != nil {
 		fmt.Printf("[SKILL-REACT] Config não encontrada, criando padrão\n")
-		skill.SaveDefaultReactions("skill_reactions.json")
-		app.skillReactionManager.LoadFromJSON("skill_reactions.json")
+		skill.SaveDefaultReactions(skillReactionsFile)
+		app.skillReactionManager.LoadFromJSON(skillReactionsFile)
 	}
 
-	// Configurar parser e executor de teclas
-	app.skillReactionManager.SetKeyParser(input.ParseKeySequence)
-	app.skillReactionManager.ExecuteKeys = input.SendKeySequence
-
-	// Configurar aimbot callback
-	app.skillReactionManager.AimAtTarget = func() bool {
-		if app.espManager != nil {
-			return app.espManager.AimAtTarget()
-		}
-		return false
-	}
+	app.initRules()
 
 	// Callback para printar skill usada E executar reações
 	app.skillMonitor.OnSkillCast = func(skillID uint32) {
 		name := app.skillMonitor.GetSkillName(skillID)
 		fmt.Printf("[SKILL] >>> %s (ID:%d) usado! <<<\n", name, skillID)
 
-		// Executar reação se configurada
-		app.skillReactionManager.OnSkillCast(skillID)
+		// Confirma ações esperando esse cast, depois dispara as regras
+		app.actions.OnSkillCast(skillID)
+		app.rules.OnSkillCast(skillID)
+
+		// Confirma cast para cooldown/GCD da rotação do bot
+		if app.botInstance != nil {
+			app.botInstance.OnSkillCast(skillID)
+		}
 	}
 
 	// Callback para tentativa de uso de skill (antes do cast)
@@ -214,27 +258,30 @@ func NewApp() (*App, error) {
 		name := app.skillMonitor.GetSkillName(skillID)
 		fmt.Printf("[SKILL-TRY] Tentando usar %s (ID:%d)\n", name, skillID)
 
-		// Executar aimbot se configurado para OnTry
-		app.skillReactionManager.OnSkillTry(skillID)
+		// Regras de skill_try (ex: aimbot antes do cast)
+		app.rules.OnSkillTry(skillID)
 	}
 
-	if err := app.presetManager.LoadFromJSON("buff_presets.json"); err != nil {
+	buffPresetsFile := app.configPath("buff_presets.json")
+	if err := app.presetManager.LoadFromJSON(buffPresetsFile); err != nil {
 		app.presetManager.CreateDefaultPresets()
-		app.presetManager.SaveToJSON("buff_presets.json")
+		app.presetManager.SaveToJSON(buffPresetsFile)
 	}
 
-	app.reactionManager.LoadFromJSON("reactions.json")
-	app.buffMonitor.SetReactionHandler(app.reactionManager)
-	app.debuffMonitor.SetReactionHandler(app.reactionManager)
+	app.reactionManager.LoadProfile(app.configPath("reactions.json"))
+
+	// Zonas: depois do bot e das reações, que são trocados ao entrar
+	app.initZones()
 
 	configWindow, err := gui.NewConfigWindow(app.reactionManager)
 	if err == nil {
 		app.configWindow = configWindow
+		app.configWindow.RuleStats = app.rules.Stats
 		// Callback to test reactions via GUI (F7) - emulates buff/debuff detection
 		app.configWindow.TestReaction = func(id uint32) {
 			// Uses TriggerForTest with key executor that sends directly to game window
 			keyExecutor := func(keys [][]uint16) error {
-				return input.SendKeySequenceToWindow(app.gameHwnd, keys)
+				return app.keys.Do(sched.LaneRotation, "reaction-test", keys)
 			}
 			if err := app.reactionManager.TriggerForTest(id, keyExecutor); err != nil {
 				fmt.Printf("[REACTION-TEST] Error: %v\n", err)
@@ -245,9 +292,10 @@ func NewApp() (*App, error) {
 	buffWindow, err := gui.NewBuffWindow(app.buffInjector, app.presetManager)
 	if err == nil {
 		app.buffWindow = buffWindow
+		app.buffWindow.SetPresetsFile(buffPresetsFile)
 	}
 
-	skillConfigWindow, err := gui.NewSkillConfigWindow(app.skillReactionManager, "skill_reactions.json")
+	skillConfigWindow, err := gui.NewSkillConfigWindow(app.skillReactionManager, skillReactionsFile)
 	if err == nil {
 		app.skillConfigWindow = skillConfigWindow
 		// Callback to test reactions via GUI - sends directly to game window
@@ -257,7 +305,7 @@ func NewApp() (*App, error) {
 				fmt.Printf("[SKILL-TEST] Error parsing '%s': %v\n", onCast, err)
 				return
 			}
-			if err := input.SendKeySequenceToWindow(app.gameHwnd, keys); err != nil {
+			if err := app.keys.Do(sched.LaneRotation, "skill-test", keys); err != nil {
 				fmt.Printf("[SKILL-TEST] Error sending '%s': %v\n", onCast, err)
 			} else {
 				fmt.Printf("[SKILL-TEST] Sent to game window: %s\n", onCast)
@@ -271,7 +319,7 @@ func NewApp() (*App, error) {
 	}
 
 	// Bot config window
-	botConfigWindow, err := gui.NewBotConfigWindow(app.botInstance, app.botConfig, "bot_config.json")
+	botConfigWindow, err := gui.NewBotConfigWindow(app.botInstance, app.botConfig, app.configPath("bot_config.json"))
 	if err == nil {
 		app.botConfigWindow = botConfigWindow
 		app.botConfigWindow.OnToggleBot = func() {
@@ -279,19 +327,19 @@ func NewApp() (*App, error) {
 		}
 	}
 
-	// Setup reaction callbacks
+	// Eventos dos monitores -> rules engine
 	app.buffMonitor.OnBuffGained = func(buff monitor.BuffInfo) {
-		app.reactionManager.OnBuffGained(buff.ID)
+		app.rules.Dispatch(rules.Event{Type: rules.BuffGained, ID: buff.ID, Stack: buff.Stack, LeftMs: buff.TimeLeft})
 	}
 	app.buffMonitor.OnBuffLost = func(buffID uint32) {
-		app.reactionManager.OnBuffLost(buffID)
+		app.rules.OnBuffLost(buffID)
 	}
 	app.debuffMonitor.OnDebuffGained = func(debuff monitor.DebuffInfo) {
 		fmt.Printf("[MAIN] Debuff detectado: TypeID:%d (instance ID:%d)\n", debuff.TypeID, debuff.ID)
-		app.reactionManager.OnDebuffGained(debuff.TypeID)
+		app.rules.Dispatch(rules.Event{Type: rules.DebuffGained, ID: debuff.TypeID, LeftMs: debuff.DurLeft})
 	}
 	app.debuffMonitor.OnDebuffLost = func(debuffTypeID uint32) {
-		app.reactionManager.OnDebuffLost(debuffTypeID)
+		app.rules.OnDebuffLost(debuffTypeID)
 	}
 
 	app.startBackgroundTasks()
@@ -310,10 +358,11 @@ func (app *App) initBot() {
 	}
 
 	// Carregar config do arquivo (mob names, range, presets)
-	fc, err := bot.LoadFileConfig("bot_config.json")
+	configFile := app.configPath("bot_config.json")
+	fc, err := bot.LoadFileConfig(configFile)
 	if err != nil {
 		fmt.Printf("[BOT] Config não encontrada, criando padrão\n")
-		bot.SaveDefaultConfig("bot_config.json")
+		bot.SaveDefaultConfig(configFile)
 		fc2 := bot.DefaultFileConfig()
 		fc = &fc2
 	}
@@ -353,6 +402,9 @@ func (app *App) initBot() {
 	cfg.MobNames = fc.MobNames
 	cfg.MaxRange = fc.MaxRange
 	cfg.PartialMatch = fc.PartialMatch
+	cfg.Strategy = fc.Strategy
+	cfg.Priorities = fc.Priorities
+	cfg.ClusterRadius = fc.ClusterRadius
 
 	if fc.ScanIntervalMs > 0 {
 		cfg.ScanInterval = time.Duration(fc.ScanIntervalMs) * time.Millisecond
@@ -361,23 +413,33 @@ func (app *App) initBot() {
 		cfg.TargetDelay = time.Duration(fc.TargetDelayMs) * time.Millisecond
 	}
 
-	cfg.OnTargetDead = func(t bot.EntityInfo) {
-		fmt.Printf("[BOT] Killed: %s → scanning next...\n", t.Name)
-	}
-
-	cfg.OnTargetAcquired = func(t bot.EntityInfo) {
-		fmt.Printf("[BOT] Attacking: %s (HP:%d Dist:%.0fm)\n", t.Name, t.HP, t.Distance)
-	}
-
-	cfg.OnCombatTick = func(t bot.EntityInfo) {
-		// Auto-attack handled by bot internally
-	}
 
 	// Configurar keys de ataque/loot
 	cfg.AttackKey = fc.AttackKey
 	cfg.LootKey = fc.LootKey
 	cfg.AutoAttack = fc.AutoAttack
 	cfg.AutoLoot = fc.AutoLoot
+	cfg.Rotation = fc.Rotation
+	cfg.Route = fc.ActiveRoute("")
+	cfg.Movement = fc.Movement
+	cfg.EngageDistance = fc.EngageDistance
+	if fc.ApproachTimeoutMs > 0 {
+		cfg.ApproachTimeout = time.Duration(fc.ApproachTimeoutMs) * time.Millisecond
+	}
+	cfg.MobRules = fc.MobRules
+	cfg.Blacklist = fc.Blacklist
+	cfg.NoProgressTimeout = time.Duration(fc.NoProgressTimeoutMs) * time.Millisecond
+	cfg.MaxTargetMismatches = fc.MaxTargetMismatches
+	if fc.BlacklistTimeMs > 0 {
+		cfg.BlacklistTime = time.Duration(fc.BlacklistTimeMs) * time.Millisecond
+	}
+	cfg.LeashRadius = fc.LeashRadius
+	cfg.Anchor = fc.Anchor
+	cfg.Rest = fc.Rest
+	cfg.Death = fc.Death
+	cfg.Combat = fc.Combat
+	cfg.Actions = fc.Actions
+	cfg.SessionDir = fc.SessionDir
 	if fc.AttackDelay > 0 {
 		cfg.AttackDelay = time.Duration(fc.AttackDelay) * time.Millisecond
 	}
@@ -385,24 +447,14 @@ func (app *App) initBot() {
 		cfg.LootDelay = time.Duration(fc.LootDelay) * time.Millisecond
 	}
 
-	// Key sender function - usa PostMessage para enviar direto pro jogo (como o keyspam)
+	// Key senders - passam pelo scheduler de input (rotação e potions)
 	cfg.SendKey = func(keyStr string) {
-		if app.gameHwnd == 0 {
-			// Fallback para SendInput se não tiver janela do jogo
-			keys, err := input.ParseKeyString(keyStr)
-			if err != nil {
-				fmt.Printf("[BOT] Invalid key: %s - %v\n", keyStr, err)
-				return
-			}
-			if err := input.SendKeyCombo(keys); err != nil {
-				fmt.Printf("[BOT] SendKey failed: %v\n", err)
-			}
-			return
-		}
-		// Envia direto pro jogo via PostMessage (mesmo método do keyspam)
-		if err := input.SendKeyStringToWindow(app.gameHwnd, keyStr); err != nil {
-			fmt.Printf("[BOT] SendKey failed: %v\n", err)
-		}
+		app.sendKey(sched.LaneRotation, keyStr)
+	}
+	// Potion sem esperar a lane: o min_gap_ms limita a taxa sem travar o
+	// tick (a confirmação repete o press se ele não chegar)
+	cfg.SendPotionKey = func(keyStr string) {
+		app.postKey(sched.LanePotion, keyStr)
 	}
 
 	// Potion settings
@@ -415,6 +467,10 @@ func (app *App) initBot() {
 	if fc.PotionCooldownMs > 0 {
 		cfg.PotionCooldown = time.Duration(fc.PotionCooldownMs) * time.Millisecond
 	}
+	cfg.Thresholds = fc.Thresholds
+	cfg.Goals = fc.Goals
+	cfg.GoalStateFile = fc.GoalStateFile
+	cfg.ConfigFile = configFile
 
 	// Player HP/MP providers - closure over app to read player stats
 	cfg.GetPlayerHP = func() (uint32, uint32) {
@@ -426,8 +482,82 @@ func (app *App) initBot() {
 		return player.MP, player.MaxMP
 	}
 
+	// Flag de morte do player local
+	cfg.IsPlayerDead = func() bool {
+		player := entity.GetLocalPlayer(app.handle, app.x2game)
+		return player.Address != 0 && player.IsDead
+	}
+
+	// Flag de combate do player local
+	cfg.IsPlayerInCombat = func() bool {
+		player := entity.GetLocalPlayer(app.handle, app.x2game)
+		return player.Address != 0 && player.InCombat
+	}
+
+	// Posição e teclas seguradas para a patrulha
+	cfg.GetPlayerPos = func() (float32, float32, float32, bool) {
+		return app.espManager.GetPlayerPosition()
+	}
+	cfg.KeyDown = func(keyStr string) {
+		app.holdKey(keyStr, true)
+	}
+	cfg.KeyUp = func(keyStr string) {
+		app.holdKey(keyStr, false)
+	}
+
+	// Providers para as condições da rotação (buffs/debuffs/cooldowns)
+	cfg.HasPlayerBuff = func(id uint32) bool {
+		return app.buffMonitor != nil && app.buffMonitor.HasBuff(id)
+	}
+	cfg.HasPlayerDebuff = func(id uint32) bool {
+		return app.debuffMonitor != nil && app.debuffMonitor.HasDebuff(id)
+	}
+	cfg.HasTargetDebuff = func(id uint32) bool {
+		return app.targetMonitor != nil && app.targetMonitor.HasDebuff(id)
+	}
+	cfg.IsSkillReady = func(skillID uint32) bool {
+		return app.skillMonitor == nil || app.skillMonitor.IsSkillReady(skillID)
+	}
+
 	app.botInstance = bot.New(app.handle, app.x2game, adapter, cfg)
 
+	// Eventos do bot: log e alerta sonoro
+	events := app.botInstance.Events()
+	events.Subscribe(func(e bot.Event) {
+		switch e.Type {
+		case bot.EventTargetAcquired:
+			fmt.Printf("[BOT] Attacking: %s (HP:%d Dist:%.0fm)\n", e.Target.Name, e.Target.HP, e.Target.Distance)
+		case bot.EventTargetKilled:
+			fmt.Printf("[BOT] Killed: %s → scanning next...\n", e.Target.Name)
+		case bot.EventTargetLost:
+			fmt.Printf("[BOT] Target dropped: %s - %s\n", e.Target.Name, e.Reason)
+		case bot.EventCombatEnter:
+			fmt.Printf("[BOT] Combat: ON\n")
+		case bot.EventCombatLeave:
+			fmt.Printf("[BOT] Combat: OFF\n")
+		}
+	}, bot.EventTargetAcquired, bot.EventTargetKilled, bot.EventTargetLost, bot.EventCombatEnter, bot.EventCombatLeave)
+	events.Subscribe(func(e bot.Event) {
+		fmt.Printf("[BOT] ALERT: %s\n", e.Reason)
+		go func() {
+			for i := 0; i < 3; i++ {
+				input.Beep(1200, 200)
+				time.Sleep(100 * time.Millisecond)
+			}
+		}()
+	}, bot.EventAlert)
+
+	// Desenha anchor + círculo do leash no overlay do ESP
+	app.espManager.SetLeashProvider(func() (float32, float32, float32, float32, bool) {
+		anchor, radius, ok := app.botInstance.GetLeash()
+		return anchor.X, anchor.Y, anchor.Z, radius, ok
+	})
+	if fc.BehaviorTree != "" {
+		if err := app.botInstance.LoadBehaviorTreeFile(fc.BehaviorTree); err != nil {
+			fmt.Printf("[BOT] Behavior tree: %v - usando padrão\n", err)
+		}
+	}
+
 	// Log potion config if enabled
 	potionInfo := ""
 	if fc.HPPotionEnabled {
@@ -448,6 +578,10 @@ func (app *App) toggleBot() {
 	if app.botInstance.IsRunning() {
 		app.botInstance.Stop()
 	} else {
+		// O bot já roda as mesmas regras do guardian
+		if app.guardian != nil && app.guardian.IsRunning() {
+			app.guardian.Stop()
+		}
 		// Garante que AllEntities ESP tá rodando
 		if app.espManager != nil && !app.espManager.IsAllEntitiesEnabled() {
 			app.espManager.ToggleAllEntities()
@@ -457,23 +591,518 @@ func (app *App) toggleBot() {
 	}
 }
 
+// toggleGuardian liga/desliga o modo guardian: as regras de threshold
+// (potions) rodando sem o bot, para jogar manualmente.
+func (app *App) toggleGuardian() {
+	if app.botInstance == nil || app.botConfig == nil {
+		return
+	}
+	if app.guardian != nil && app.guardian.IsRunning() {
+		app.guardian.Stop()
+		return
+	}
+	if app.botInstance.IsRunning() {
+		fmt.Println("[GUARDIAN] Bot rodando - as regras já estão ativas")
+		return
+	}
+
+	cfg := app.botInstance.GetConfig()
+	g, err := bot.NewGuardian(bot.GuardianConfig{
+		Rules:    app.botConfig.ThresholdRules(),
+		Interval: time.Duration(app.botConfig.GuardianIntervalMs) * time.Millisecond,
+		SendKey:  cfg.SendPotionKey,
+		Actions:  app.actions,
+		Resources: app.resources(),
+	})
+	if err != nil {
+		fmt.Printf("[GUARDIAN] %v\n", err)
+		return
+	}
+	app.guardian = g
+	g.Start()
+}
+
+// ============================
+// Input scheduler
+// ============================
+
+// initInputScheduler cria o scheduler por onde passam todas as teclas (bot,
+// regras, autospam, guardian): PostMessage na janela do jogo ou SendInput
+// sem janela. scheduler.json ajusta os rate limits das lanes.
+func (app *App) initInputScheduler() {
+	cfg, err := sched.LoadConfig("scheduler.json")
+	if err != nil {
+		fmt.Printf("[SCHED] %v (usando padrão)\n", err)
+	}
+	app.keys = sched.New(cfg, sched.Backend{
+		Press: func(combo []uint16) error {
+			if app.gameHwnd == 0 {
+				return input.SendKeyCombo(combo)
+			}
+			return input.SendKeyComboToWindow(app.gameHwnd, combo)
+		},
+		Hold: func(keys []uint16, down bool) error {
+			return input.SetKeyComboStateToWindow(app.gameHwnd, keys, down)
+		},
+	}, nil, nil)
+	app.keys.Start()
+}
+
+// sendKey parseia keyStr e envia pelo scheduler na lane, esperando o envio.
+func (app *App) sendKey(lane sched.Lane, keyStr string) {
+	keys, err := input.ParseKeyString(keyStr)
+	if err != nil {
+		fmt.Printf("[BOT] Invalid key: %s - %v\n", keyStr, err)
+		return
+	}
+	if err := app.keys.Do(lane, keyStr, [][]uint16{keys}); err != nil {
+		fmt.Printf("[BOT] SendKey %s failed: %v\n", keyStr, err)
+	}
+}
+
+// postKey parseia keyStr e enfileira na lane sem esperar o envio.
+func (app *App) postKey(lane sched.Lane, keyStr string) {
+	keys, err := input.ParseKeyString(keyStr)
+	if err != nil {
+		fmt.Printf("[BOT] Invalid key: %s - %v\n", keyStr, err)
+		return
+	}
+	if err := app.keys.Post(lane, keyStr, [][]uint16{keys}); err != nil {
+		fmt.Printf("[BOT] PostKey %s failed: %v\n", keyStr, err)
+	}
+}
+
+// holdKey segura/solta uma tecla de movimento pelo scheduler.
+func (app *App) holdKey(keyStr string, down bool) {
+	keys, err := input.ParseKeyString(keyStr)
+	if err != nil {
+		fmt.Printf("[BOT] Invalid key: %s - %v\n", keyStr, err)
+		return
+	}
+	if err := app.keys.Hold(sched.LaneRotation, keyStr, keys, down); err != nil {
+		fmt.Printf("[BOT] Hold %s failed: %v\n", keyStr, err)
+	}
+}
+
+// ============================
+// Rules engine
+// ============================
+
+// initRules cria o engine que dispara as reações de buff/debuff, de skill e
+// as regras do rules.json.
+func (app *App) initRules() {
+	e := rules.NewEngine(nil, nil)
+	e.ParseKeys = input.ParseKeySequence
+	e.ExecuteKeys = func(lane sched.Lane, keys [][]uint16) error {
+		return app.keys.Do(lane, "rules", keys)
+	}
+	e.Actions = app.actions
+	e.AimAtTarget = func() bool {
+		return app.espManager != nil && app.espManager.AimAtTarget()
+	}
+	e.IsAFK = func() bool {
+		return app.afkMonitor != nil && app.afkMonitor.IsEnabled() && app.afkMonitor.IsAFK()
+	}
+	e.HasBuff = func(id uint32) bool {
+		return app.buffMonitor != nil && app.buffMonitor.HasBuff(id)
+	}
+	e.HasDebuff = func(id uint32) bool {
+		return app.debuffMonitor != nil && app.debuffMonitor.HasDebuff(id)
+	}
+	e.InCombat = func() bool {
+		player := entity.GetLocalPlayer(app.handle, app.x2game)
+		return player.Address != 0 && player.InCombat
+	}
+	e.TargetID = func() uint32 {
+		id, _ := target.GetCurrentTargetId(app.handle, app.x2game)
+		return id
+	}
+	e.TargetType = app.currentTargetType
+	e.Resource = app.resources().Read
+	app.rules = e
+	app.loadRules()
+}
+
+// loadRules (re)carrega o rules.json do perfil ativo. Com import_legacy as
+// reações das janelas de config entram como fontes ao vivo.
+func (app *App) loadRules() {
+	if app.rules == nil {
+		return
+	}
+	file := app.configPath("rules.json")
+	f, err := rules.LoadFile(file)
+	if err != nil {
+		fmt.Printf("[RULES] %v\n", err)
+	}
+	app.rules.SetSource("rules", rules.Static(f.Rules))
+	if f.ImportLegacy {
+		app.rules.SetSource("reactions", app.reactionManager.Rules)
+		app.rules.SetSource("skill_reactions", app.skillReactionManager.Rules)
+	} else {
+		app.rules.SetSource("reactions", nil)
+		app.rules.SetSource("skill_reactions", nil)
+	}
+	fmt.Printf("[RULES] %s: %d regras (import_legacy: %v)\n", file, len(f.Rules), f.ImportLegacy)
+}
+
+// resources lê HP/MP do player, HP do target e HP do mate (thresholds,
+// rules e confirmação das potions).
+func (app *App) resources() bot.Resources {
+	return bot.Resources{
+		PlayerHP: func() (float32, bool) {
+			player := entity.GetLocalPlayer(app.handle, app.x2game)
+			return percentOf(player.HP, player.MaxHP)
+		},
+		PlayerMP: func() (float32, bool) {
+			player := entity.GetLocalPlayer(app.handle, app.x2game)
+			return percentOf(player.MP, player.MaxMP)
+		},
+		TargetHP: func() (float32, bool) {
+			if app.targetMonitor == nil {
+				return 0, false
+			}
+			hp, max := app.targetMonitor.GetTargetHP()
+			if hp < 0 || max <= 0 {
+				return 0, false
+			}
+			return percentOf(uint32(hp), uint32(max))
+		},
+		MateHP: func() (float32, bool) {
+			if app.botInstance == nil {
+				return 0, false
+			}
+			return bot.MateHPFromEntities(app.botInstance.GetEntityProvider().GetEntities())
+		},
+	}
+}
+
+// currentTargetType classifica o target atual pelo cache do ESP (player,
+// npc ou "" sem target/fora do cache).
+func (app *App) currentTargetType() string {
+	id, _ := target.GetCurrentTargetId(app.handle, app.x2game)
+	if id == 0 || app.espManager == nil {
+		return ""
+	}
+	for _, e := range app.espManager.GetAllEntitiesCached() {
+		if e.EntityID != id {
+			continue
+		}
+		switch {
+		case e.IsPlayer:
+			return rules.TargetPlayer
+		case e.IsNPC:
+			return rules.TargetNPC
+		}
+		return ""
+	}
+	return ""
+}
+
+// tickRules gera target_change e avalia os thresholds (monitorLoop).
+func (app *App) tickRules() {
+	if app.rules == nil {
+		return
+	}
+	id, _ := target.GetCurrentTargetId(app.handle, app.x2game)
+	if id != app.lastTargetID {
+		app.lastTargetID = id
+		app.rules.OnTargetChanged(id)
+	}
+	app.rules.Tick()
+}
+
+// initZones (re)carrega o zones.json do perfil ativo. Só cria o padrão se o
+// arquivo não existir; com erro de parse as zonas ficam desativadas e o
+// arquivo não é tocado.
+func (app *App) initZones() {
+	file := app.configPath("zones.json")
+	cfg, err := zone.LoadConfig(file)
+	if os.IsNotExist(err) {
+		fmt.Printf("[ZONE] %s não encontrado, criando padrão\n", file)
+		def := zone.DefaultConfig()
+		cfg = &def
+		zone.SaveConfig(file, cfg)
+	} else if err != nil {
+		fmt.Printf("[ZONE] %s: %v - zonas desativadas (arquivo mantido)\n", file, err)
+		app.mu.Lock()
+		app.zoneConfig, app.zoneTracker, app.zoneFile = nil, nil, file
+		app.mu.Unlock()
+		return
+	}
+	tracker, err := zone.NewTracker(cfg.Zones)
+	if err != nil {
+		fmt.Printf("[ZONE] %v - zonas desativadas\n", err)
+		tracker, _ = zone.NewTracker(nil)
+	}
+	app.mu.Lock()
+	app.zoneConfig = cfg
+	app.zoneTracker = tracker
+	app.zoneFile = file
+	app.mu.Unlock()
+	fmt.Printf("[ZONE] %s: %d zones (auto: %v)\n", file, len(cfg.Zones), cfg.Enabled)
+}
+
+// updateZones grava o contorno em andamento e aplica a zona em que o
+// player acabou de entrar. Chamado pelo monitorLoop.
+func (app *App) updateZones() {
+	if app.espManager == nil {
+		return
+	}
+	app.mu.RLock()
+	cfg, tracker, rec := app.zoneConfig, app.zoneTracker, app.zoneRecorder
+	app.mu.RUnlock()
+	if cfg == nil || tracker == nil {
+		return
+	}
+	x, y, _, ok := app.espManager.GetPlayerPosition()
+	if !ok {
+		return
+	}
+	if rec != nil && rec.Add(x, y) {
+		fmt.Printf("[ZONE] Point %d: (%.0f, %.0f)\n", rec.Points(), x, y)
+	}
+	if z, entered := tracker.Update(x, y); entered && cfg.Enabled {
+		app.applyZone(z)
+	}
+}
+
+// applyZone troca o preset do bot, o perfil de reações e os filtros do ESP
+// ligados à zona. O que a zona não define fica como está.
+func (app *App) applyZone(z zone.Zone) {
+	fmt.Printf("[ZONE] Entered %s\n", z.Name)
+	if z.Preset != "" && app.botInstance != nil && app.botInstance.GetPresetName() != z.Preset {
+		app.botLoadPreset(z.Preset)
+	}
+	// Arquivo de reações da zona também pode ser sobrescrito pelo perfil
+	if reactions := app.configPath(z.Reactions); z.Reactions != "" && app.reactionManager != nil && app.reactionManager.GetProfile() != reactions {
+		if err := app.reactionManager.LoadProfile(reactions); err != nil {
+			fmt.Printf("[ZONE] Reactions '%s': %v\n", reactions, err)
+		} else {
+			fmt.Printf("[ZONE] Reactions: %s (%d)\n", reactions, app.reactionManager.GetActiveCount())
+		}
+	}
+	if z.ESP != nil && app.espManager != nil {
+		f := app.espManager.GetFilters()
+		for _, o := range []struct {
+			dst *bool
+			v   *bool
+		}{
+			{&f.Players, z.ESP.Players}, {&f.NPCs, z.ESP.NPCs}, {&f.Mates, z.ESP.Mates},
+			{&f.West, z.ESP.West}, {&f.East, z.ESP.East}, {&f.Pirate, z.ESP.Pirate},
+		} {
+			if o.v != nil {
+				*o.dst = *o.v
+			}
+		}
+		app.espManager.SetFilters(f)
+	}
+}
+
+// toggleZoneRecording começa/termina a gravação de uma zona andando pela
+// borda. A zona nova vai para o zones.json ligada ao preset ativo.
+func (app *App) toggleZoneRecording() {
+	app.mu.Lock()
+	cfg, rec := app.zoneConfig, app.zoneRecorder
+	if cfg == nil {
+		app.mu.Unlock()
+		return
+	}
+	if rec == nil {
+		name := ""
+		for i := len(cfg.Zones) + 1; name == ""; i++ {
+			name = fmt.Sprintf("zone%d", i)
+			for _, z := range cfg.Zones {
+				if z.Name == name {
+					name = ""
+					break
+				}
+			}
+		}
+		app.zoneRecorder = zone.NewRecorder(name, cfg.RecordSpacing)
+		app.mu.Unlock()
+		fmt.Printf("[ZONE] Recording %s - walk the boundary and press NUMPAD* again\n", name)
+		return
+	}
+	app.zoneRecorder = nil
+	app.mu.Unlock()
+
+	z, err := rec.Finish()
+	if err != nil {
+		fmt.Printf("[ZONE] Recording discarded: %v\n", err)
+		return
+	}
+	if app.botInstance != nil {
+		z.Preset = app.botInstance.GetPresetName()
+	}
+	zones := append(append([]zone.Zone(nil), cfg.Zones...), z)
+	tracker, err := zone.NewTracker(zones)
+	if err != nil {
+		fmt.Printf("[ZONE] %v\n", err)
+		return
+	}
+	app.mu.Lock()
+	cfg.Zones = zones
+	app.zoneTracker = tracker
+	file := app.zoneFile
+	app.mu.Unlock()
+	if err := zone.SaveConfig(file, cfg); err != nil {
+		fmt.Printf("[ZONE] Erro ao salvar: %v\n", err)
+		return
+	}
+	fmt.Printf("[ZONE] Saved %s (%d points, preset '%s') in %s\n", z.Name, len(z.Polygon), z.Preset, file)
+}
+
+// zoneDisplay é o trecho da zona no overlay do bot
+func (app *App) zoneDisplay() string {
+	app.mu.RLock()
+	tracker, rec := app.zoneTracker, app.zoneRecorder
+	app.mu.RUnlock()
+	if rec != nil {
+		return fmt.Sprintf(" | ZONE REC:%d", rec.Points())
+	}
+	if tracker != nil {
+		if z, ok := tracker.Current(); ok {
+			return " | Zone:" + z.Name
+		}
+	}
+	return ""
+}
+
+// configPath resolve uma config pelo perfil ativo: o arquivo do perfil se
+// existir, senão o compartilhado.
+func (app *App) configPath(file string) string {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.profile.Path(file)
+}
+
+// detectCharacter lê o nome do player local e ativa o perfil quando o
+// personagem muda (attach, relog com outro personagem). reload=false no
+// attach, antes das configs serem carregadas.
+func (app *App) detectCharacter(reload bool) {
+	addr := entity.GetPlayerEntityAddr(app.handle, app.x2game)
+	if addr == 0 {
+		return
+	}
+	name := entity.GetEntityName(app.handle, addr)
+	app.mu.Lock()
+	if name == "" || name == app.characterName {
+		app.mu.Unlock()
+		return
+	}
+	app.characterName = name
+	app.mu.Unlock()
+
+	fmt.Printf("[PROFILE] Character: %s\n", name)
+	app.setProfile(profile.New(profile.Root, name), reload)
+}
+
+// cycleProfile troca para o próximo perfil em profiles/ (switcher do overlay).
+func (app *App) cycleProfile() {
+	names, err := profile.List(profile.Root)
+	if err != nil {
+		fmt.Printf("[PROFILE] %v\n", err)
+		return
+	}
+	app.mu.RLock()
+	current := app.profile.Key()
+	app.mu.RUnlock()
+
+	next := profile.Profile{}
+	if key := profile.Next(names, current); key != "" {
+		next = profile.New(profile.Root, key)
+	}
+	app.setProfile(next, true)
+}
+
+func (app *App) setProfile(p profile.Profile, reload bool) {
+	if err := p.Ensure(); err != nil {
+		fmt.Printf("[PROFILE] %v\n", err)
+	}
+	app.mu.Lock()
+	app.profile = p
+	app.mu.Unlock()
+	fmt.Printf("[PROFILE] %s (overrides: %v)\n", p, p.Overrides())
+	if reload {
+		app.reloadProfileConfigs()
+	}
+}
+
+// reloadProfileConfigs recarrega as configs de profile.Files pelo perfil
+// ativo e aponta as janelas de config para os mesmos arquivos.
+func (app *App) reloadProfileConfigs() {
+	if app.reactionManager != nil {
+		if err := app.reactionManager.LoadProfile(app.configPath("reactions.json")); err != nil {
+			fmt.Printf("[PROFILE] reactions: %v\n", err)
+		}
+	}
+	app.loadRules()
+	if app.skillReactionManager != nil {
+		file := app.configPath("skill_reactions.json")
+		if err := app.skillReactionManager.LoadFromJSON(file); err != nil {
+			fmt.Printf("[PROFILE] skill reactions: %v\n", err)
+		}
+		if app.skillConfigWindow != nil {
+			app.skillConfigWindow.SetConfigPath(file)
+		}
+	}
+	if app.presetManager != nil {
+		file := app.configPath("buff_presets.json")
+		if err := app.presetManager.LoadFromJSON(file); err != nil {
+			fmt.Printf("[PROFILE] buff presets: %v\n", err)
+		}
+		if app.buffWindow != nil {
+			app.buffWindow.SetPresetsFile(file)
+		}
+	}
+	if app.botInstance != nil {
+		file := app.configPath("bot_config.json")
+		if fc, err := bot.LoadFileConfig(file); err != nil {
+			fmt.Printf("[PROFILE] bot config: %v\n", err)
+		} else {
+			app.botConfig = fc
+			app.botInstance.SetConfigFile(file)
+			app.botInstance.ApplyFileConfig(fc)
+			if app.botConfigWindow != nil {
+				app.botConfigWindow.SetConfigFile(file)
+				app.botConfigWindow.SetBotConfig(fc)
+			}
+		}
+	}
+	// Zonas por último: o tracker novo reentra na zona atual e reaplica o
+	// preset/reações dela por cima das configs do perfil
+	app.initZones()
+	app.updateZones()
+}
+
+func percentOf(cur, max uint32) (float32, bool) {
+	if max == 0 {
+		return 0, false
+	}
+	return float32(cur) / float32(max) * 100, true
+}
+
 func (app *App) botLoadPreset(presetName string) {
 	if app.botInstance == nil || app.botConfig == nil {
 		return
 	}
 
-	names, ok := app.botConfig.Presets[presetName]
+	preset, ok := app.botConfig.Presets[presetName]
 	if !ok {
 		fmt.Printf("[BOT] Preset '%s' não encontrado\n", presetName)
 		return
 	}
 
-	app.botInstance.SetMobNames(names)
-	fmt.Printf("[BOT] Preset '%s': %v\n", presetName, names)
+	if err := app.botInstance.ApplyPreset(app.botConfig, preset); err != nil {
+		fmt.Printf("[BOT] Preset '%s': %v\n", presetName, err)
+		return
+	}
+	app.botInstance.SetPresetName(presetName)
+	fmt.Printf("[BOT] Preset '%s': %v (%s)\n", presetName, preset.MobNames, app.botInstance.GetStrategy())
 }
 
 func (app *App) botReloadConfig() {
-	fc, err := bot.LoadFileConfig("bot_config.json")
+	fc, err := bot.LoadFileConfig(app.configPath("bot_config.json"))
 	if err != nil {
 		fmt.Printf("[BOT] Erro ao recarregar config: %v\n", err)
 		return
@@ -486,6 +1115,80 @@ func (app *App) botReloadConfig() {
 	}
 }
 
+// botRecordWaypoint adiciona a posição atual do player na rota ativa e salva
+// no bot_config.json.
+func (app *App) botRecordWaypoint() {
+	if app.botInstance == nil || app.botConfig == nil || app.espManager == nil {
+		return
+	}
+
+	x, y, z, ok := app.espManager.GetPlayerPosition()
+	if !ok {
+		fmt.Println("[BOT] Posição do player indisponível")
+		return
+	}
+
+	name, count := app.botConfig.AddWaypoint(bot.Waypoint{X: x, Y: y, Z: z})
+	if err := bot.SaveFileConfig(app.configPath("bot_config.json"), app.botConfig); err != nil {
+		fmt.Printf("[BOT] Erro ao salvar rota: %v\n", err)
+	}
+	app.botInstance.SetRoute(app.botConfig.ActiveRoute(""), app.botConfig.Movement)
+	fmt.Printf("[BOT] Waypoint %d gravado na rota '%s' (%.0f, %.0f, %.0f)\n", count, name, x, y, z)
+}
+
+// toggleBotRecording liga/desliga a gravação das entidades vistas pelo bot.
+// O arquivo pode ser reproduzido offline com cmd/debug/bot_replay.
+func (app *App) toggleBotRecording() {
+	if app.botInstance == nil {
+		return
+	}
+
+	if app.botRecorder != nil {
+		rec := app.botRecorder
+		app.botRecorder = nil
+		app.botInstance.SetEntityProvider(rec.Inner())
+		if err := rec.Close(); err != nil {
+			fmt.Printf("[BOT] Erro ao fechar gravação: %v\n", err)
+		}
+		fmt.Printf("[BOT] Gravação salva: %s (%d frames)\n", rec.Filename(), rec.Frames())
+		return
+	}
+
+	if err := os.MkdirAll("recordings", 0755); err != nil {
+		fmt.Printf("[BOT] Erro ao criar pasta recordings: %v\n", err)
+		return
+	}
+	filename := filepath.Join("recordings", "bot_"+time.Now().Format("20060102_150405")+".jsonl")
+
+	rec, err := bot.NewRecorder(app.botInstance.GetEntityProvider(), filename)
+	if err != nil {
+		fmt.Printf("[BOT] Erro ao iniciar gravação: %v\n", err)
+		return
+	}
+	rec.GetPlayerPos = func() (float32, float32, float32, bool) {
+		if app.espManager == nil {
+			return 0, 0, 0, false
+		}
+		return app.espManager.GetPlayerPosition()
+	}
+	rec.GetTargetID = func() uint32 {
+		id, _ := target.GetCurrentTargetId(app.handle, app.x2game)
+		return id
+	}
+	rec.GetPlayerHP = func() (uint32, uint32) {
+		player := entity.GetLocalPlayer(app.handle, app.x2game)
+		return player.HP, player.MaxHP
+	}
+	rec.GetPlayerMP = func() (uint32, uint32) {
+		player := entity.GetLocalPlayer(app.handle, app.x2game)
+		return player.MP, player.MaxMP
+	}
+
+	app.botRecorder = rec
+	app.botInstance.SetEntityProvider(rec)
+	fmt.Printf("[BOT] Gravando entidades em %s (NUMPAD7 para parar)\n", filename)
+}
+
 // ============================================================================
 // Background tasks
 // ============================================================================
@@ -578,6 +1281,10 @@ func (app *App) monitorLoop() {
 					buffListAddr := app.buffMonitor.GetBuffListAddr(playerAddr)
 					app.buffInjector.SetBuffListAddr(buffListAddr)
 				}
+
+				app.tickRules()
+				app.updateZones()
+				app.detectCharacter(true)
 			}()
 		}
 	}
@@ -641,6 +1348,7 @@ func (app *App) pollHotkeys() {
 			if app.reactionManager != nil {
 				app.reactionManager.ReloadFromJSON()
 			}
+			app.loadRules()
 		},
 		0x23: func() { // END
 			app.visible = !app.visible
@@ -784,11 +1492,26 @@ func (app *App) pollHotkeys() {
 				app.botInstance.SetPartialMatch(!cfg.PartialMatch)
 			}
 		},
+		0x66: func() { // NUMPAD6 - Toggle guardian (só potions/thresholds)
+			app.toggleGuardian()
+		},
+		0x67: func() { // NUMPAD7 - Toggle entity recording (replay com cmd/debug/bot_replay)
+			app.toggleBotRecording()
+		},
+		0x68: func() { // NUMPAD8 - Grava waypoint na rota ativa
+			app.botRecordWaypoint()
+		},
 		0x69: func() { // NUMPAD9 - Print bot stats
 			if app.botInstance != nil {
 				app.botInstance.PrintStats()
 			}
 		},
+		0x6A: func() { // NUMPAD* - Grava zona andando pela borda (zones.json)
+			app.toggleZoneRecording()
+		},
+		0x6F: func() { // NUMPAD/ - Próximo perfil (compartilhado + profiles/*)
+			app.cycleProfile()
+		},
 	}
 
 	for vk, callback := range keys {
@@ -835,7 +1558,11 @@ func (app *App) getDisplayLines() []string {
 		activeReactions = app.reactionManager.GetActiveCount()
 	}
 
-	lines = append(lines, fmt.Sprintf("ARCHEFRIEND [%s] | Reactions: %d", status, activeReactions))
+	app.mu.RLock()
+	prof := app.profile
+	app.mu.RUnlock()
+
+	lines = append(lines, fmt.Sprintf("ARCHEFRIEND [%s] | Reactions: %d | [NUM/] Profile:%s", status, activeReactions, prof))
 	lines = append(lines, "────────────────────────────────────────────────────────")
 
 	lootStatus := "OFF"
@@ -931,7 +1658,17 @@ func (app *App) getBotDisplayLine() string {
 				mobList += n
 			}
 		}
-		return fmt.Sprintf("[DEL] Bot:OFF | Mobs:[%s] | Range:%.0fm", mobList, cfg.MaxRange)
+		line := fmt.Sprintf("[DEL] Bot:OFF | Mobs:[%s] | Range:%.0fm | %s", mobList, cfg.MaxRange, app.botInstance.GetStrategy())
+		if app.botRecorder != nil {
+			line += " | REC"
+		}
+		if app.guardian != nil && app.guardian.IsRunning() {
+			line += " | GUARDIAN"
+		}
+		if goal, ok := app.botInstance.GoalProgress(); ok {
+			line += " | " + goal
+		}
+		return line + app.zoneDisplay()
 	}
 
 	// Bot rodando - mostra estado + target atual
@@ -939,14 +1676,26 @@ func (app *App) getBotDisplayLine() string {
 	stats := app.botInstance.GetStats()
 	cfg := app.botInstance.GetConfig()
 
-	line := fmt.Sprintf("[DEL] Bot:%s | Kills:%d | R:%.0fm",
-		state, stats.MobsKilled, cfg.MaxRange)
+	line := fmt.Sprintf("[DEL] Bot:%s | Kills:%d | R:%.0fm | %s",
+		state, stats.MobsKilled, cfg.MaxRange, app.botInstance.GetStrategy())
 
 	if target := app.botInstance.GetCurrentTarget(); target != nil {
 		line += fmt.Sprintf(" | %s HP:%d D:%.0fm", target.Name, target.HP, target.Distance)
 	}
+	if ability := app.botInstance.GetLastAbility(); ability != "" {
+		line += " | " + ability
+	}
+	if inCombat, _, ok := app.botInstance.InCombat(); ok && inCombat {
+		line += " | COMBAT"
+	}
+	if app.botRecorder != nil {
+		line += fmt.Sprintf(" | REC:%d", app.botRecorder.Frames())
+	}
+	if goal, ok := app.botInstance.GoalProgress(); ok {
+		line += " | " + goal
+	}
 
-	return line
+	return line + app.zoneDisplay()
 }
 
 func (app *App) Update() {
@@ -1060,6 +1809,24 @@ func (app *App) printDiagnostics() {
 		}
 	}
 
+	if app.rules != nil {
+		fmt.Printf("\n[RULES]\n")
+		fmt.Printf("  Enabled: %v\n", app.rules.IsEnabled())
+		fmt.Printf("  Rules: %d\n", len(app.rules.Rules()))
+		for _, s := range app.rules.Stats() {
+			fmt.Printf("    - %s: fired %d, suppressed cooldown:%d debounce:%d conditions:%d\n",
+				s.ID, s.Fired, s.Cooldown, s.Debounce, s.Conditions)
+		}
+	}
+
+	if app.keys != nil {
+		fmt.Printf("\n[SCHED]\n")
+		for _, s := range app.keys.Stats() {
+			fmt.Printf("    - %s: sent %d (combos %d), queued %d, preempted %d, dropped %d, wait %dms\n",
+				s.Lane, s.Sent, s.Combos, s.Queued, s.Preempted, s.Dropped, s.WaitMs)
+		}
+	}
+
 	// Bot diagnostics
 	if app.botInstance != nil {
 		fmt.Printf("\n[BOT]\n")
@@ -1069,16 +1836,35 @@ func (app *App) printDiagnostics() {
 		fmt.Printf("  MobNames: %v\n", cfg.MobNames)
 		fmt.Printf("  MaxRange: %.0fm\n", cfg.MaxRange)
 		fmt.Printf("  PartialMatch: %v\n", cfg.PartialMatch)
+		fmt.Printf("  Strategy: %s\n", app.botInstance.GetStrategy())
 		stats := app.botInstance.GetStats()
-		fmt.Printf("  Kills: %d | Targets: %d\n", stats.MobsKilled, stats.TargetsSet)
+		fmt.Printf("  Kills: %d | Targets: %d | Rest: %s (%dx) | Deaths: %d\n",
+			stats.MobsKilled, stats.TargetsSet, stats.RestTime.Round(time.Second), stats.Rests, stats.Deaths)
+		if bl := app.botInstance.GetMobBlacklist(); len(bl.Names)+len(bl.IDs) > 0 {
+			fmt.Printf("  Blacklist: names %v | ids %v\n", bl.Names, bl.IDs)
+		}
+		for _, s := range app.botInstance.GetSkipped() {
+			fmt.Printf("    Skipped: %s (ID:%d %.0fm) - %s\n", s.Name, s.EntityID, s.Distance, s.Reason)
+		}
+		for _, e := range app.botInstance.GetBlacklist() {
+			fmt.Printf("    Blacklist: %s (ID:%d) - %s, %s left\n",
+				e.Name, e.EntityID, e.Reason, time.Until(e.Until).Round(time.Second))
+		}
+		for _, d := range app.botInstance.GetDeaths() {
+			fmt.Printf("    Death %s: %s\n", d.At.Format("15:04:05"), d)
+		}
 		if target := app.botInstance.GetCurrentTarget(); target != nil {
 			fmt.Printf("  Current: %s (ID:%d HP:%d Dist:%.0fm)\n",
 				target.Name, target.EntityID, target.HP, target.Distance)
 		}
 		if app.botConfig != nil && len(app.botConfig.Presets) > 0 {
 			fmt.Printf("  Presets:\n")
-			for name, mobs := range app.botConfig.Presets {
-				fmt.Printf("    %s: %v\n", name, mobs)
+			for name, preset := range app.botConfig.Presets {
+				strategy := preset.Strategy
+				if strategy == "" {
+					strategy = "global"
+				}
+				fmt.Printf("    %s: %v (%s)\n", name, preset.MobNames, strategy)
 			}
 		}
 	}
@@ -1136,10 +1922,25 @@ func (app *App) Close() {
 	if app.botInstance != nil && app.botInstance.IsRunning() {
 		app.botInstance.Stop()
 	}
+	if app.guardian != nil {
+		app.guardian.Stop()
+	}
+	if app.actions != nil {
+		for _, s := range app.actions.Stats() {
+			fmt.Printf("[ACTION] %s\n", s)
+		}
+	}
+	if app.botRecorder != nil {
+		app.botRecorder.Close()
+		app.botRecorder = nil
+	}
 
 	if app.inputManager != nil && app.inputManager.IsAutoSpamming() {
 		app.inputManager.StopAutoSpam()
 	}
+	if app.keys != nil {
+		app.keys.Stop()
+	}
 	if app.afkMonitor != nil {
 		app.afkMonitor.Stop()
 	}
@@ -1181,6 +1982,7 @@ func main() {
 	fmt.Println("║  DEL: Bot ON/OFF                     ║")
 	fmt.Println("║  NUM1-3: Mob Presets | NUM4: Reload  ║")
 	fmt.Println("║  NUM+/-: Range | NUM5: Match Mode    ║")
+	fmt.Println("║  NUM7: Record | NUM8: Waypoint       ║")
 	fmt.Println("╚═══════════════════════════════════════╝")
 	fmt.Println()
 
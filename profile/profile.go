@@ -0,0 +1,126 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ====================
+// Character profiles
+// ====================
+
+// Root é a pasta dos perfis: profiles/<personagem>/bot_config.json etc.
+const Root = "profiles"
+
+// Files são as configs que um perfil pode sobrescrever. Arquivo ausente na
+// pasta do perfil = usa o da pasta de trabalho (padrão compartilhado).
+// Para sobrescrever, copie o arquivo para a pasta do perfil.
+var Files = []string{"bot_config.json", "reactions.json", "skill_reactions.json", "buff_presets.json", "rules.json", "zones.json"}
+
+// Profile é o perfil de um personagem. O zero value é o perfil
+// compartilhado (só as configs da pasta de trabalho).
+type Profile struct {
+	Name string // nome do personagem ("" = compartilhado)
+	Dir  string
+}
+
+// New retorna o perfil do personagem em root (a pasta não precisa existir).
+func New(root, name string) Profile {
+	return Profile{Name: name, Dir: filepath.Join(root, DirName(name))}
+}
+
+// DirName converte o nome do personagem num nome de pasta válido no Windows.
+func DirName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.Trim(name, " .")
+}
+
+// Key identifica o perfil na lista de List ("" = compartilhado).
+func (p Profile) Key() string {
+	if p.Dir == "" {
+		return ""
+	}
+	return filepath.Base(p.Dir)
+}
+
+func (p Profile) String() string {
+	if p.Name == "" {
+		return "shared"
+	}
+	return p.Name
+}
+
+// Path retorna o arquivo do perfil se ele existir, senão o compartilhado.
+// Salvar no caminho retornado mantém a mesma regra.
+func (p Profile) Path(file string) string {
+	if p.Dir != "" {
+		candidate := filepath.Join(p.Dir, file)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return file
+}
+
+// Overrides lista os arquivos de Files que o perfil sobrescreve.
+func (p Profile) Overrides() []string {
+	var files []string
+	for _, f := range Files {
+		if p.Path(f) != f {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// Ensure cria a pasta do perfil (vazia = tudo compartilhado).
+func (p Profile) Ensure() error {
+	if p.Dir == "" {
+		return nil
+	}
+	return os.MkdirAll(p.Dir, 0755)
+}
+
+// List retorna os perfis em root, em ordem alfabética (root inexistente =
+// nenhum perfil).
+func List(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Next retorna o perfil seguinte a current na ordem: compartilhado ("")
+// e depois cada um de names, voltando ao compartilhado.
+func Next(names []string, current string) string {
+	if current == "" {
+		if len(names) == 0 {
+			return ""
+		}
+		return names[0]
+	}
+	for i, n := range names {
+		if n == current && i+1 < len(names) {
+			return names[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package profile_test
+
+import (
+	"archefriend/profile"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	root := t.TempDir()
+
+	if got := profile.DirName(" Kai:Ren?. "); got != "Kai_Ren_" {
+		t.Fatalf("dir name %q", got)
+	}
+
+	// Perfil sem o arquivo usa o compartilhado; com o arquivo, o dele
+	p := profile.New(root, "Kairen")
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Path("bot_config.json"); got != "bot_config.json" {
+		t.Fatalf("missing override resolved to %q", got)
+	}
+	override := filepath.Join(p.Dir, "reactions.json")
+	if err := os.WriteFile(override, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Path("reactions.json"); got != override {
+		t.Fatalf("override resolved to %q, want %q", got, override)
+	}
+	if o := p.Overrides(); len(o) != 1 || o[0] != "reactions.json" {
+		t.Fatalf("unexpected overrides %v", o)
+	}
+	if got := (profile.Profile{}).Path("reactions.json"); got != "reactions.json" {
+		t.Fatalf("shared profile resolved to %q", got)
+	}
+
+	// Switcher: compartilhado -> perfis em ordem -> compartilhado
+	if err := profile.New(root, "Aria").Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	names, err := profile.List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for key, i := "", 0; i < 3; i++ {
+		key = profile.Next(names, key)
+		order = append(order, key)
+	}
+	if strings.Join(order, ",") != "Aria,Kairen," {
+		t.Fatalf("unexpected switch order %v", order)
+	}
+	if names, err := profile.List(filepath.Join(root, "missing")); err != nil || len(names) != 0 {
+		t.Fatalf("missing root: %v %v", names, err)
+	}
+}
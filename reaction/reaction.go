@@ -1,7 +1,9 @@
 package reaction
 
 import (
+	"archefriend/action"
 	"archefriend/input"
+	"archefriend/rules"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -15,39 +17,52 @@ type ReactionConfig struct {
 	OnEnd      string `json:"onEnd"`
 	IsDebuff   bool   `json:"isDebuff"`
 	CooldownMS int    `json:"cooldownMs"`
-}
 
-type Reaction struct {
-	ID          uint32
-	Name        string
-	OnGain      [][]uint16
-	OnLost      [][]uint16
-	Enabled     bool
-	IsDebuff    bool
-	UseString   string
-	OnEndString string
-	CooldownMS  int
-	lastTrigger int64
-}
+	// Confirmação de onStart/onEnd (ver action.Confirm). Vazio = envia uma vez
+	Confirm    action.Confirm `json:"confirm,omitempty"`
+	ConfirmEnd action.Confirm `json:"confirmEnd,omitempty"`
 
-type AFKChecker interface {
-	IsAFK() bool
-	IsEnabled() bool
+	// Cooldown do onEnd (0 = cooldownMs) e debounce do lost/regain
+	// (0 = sem debounce)
+	CooldownEndMS int `json:"cooldownEndMs,omitempty"`
+	DebounceMS    int `json:"debounceMs,omitempty"`
+
+	// Condições opcionais (combate, tipo de target, HP, stacks, duração,
+	// buff ausente). Ver rules.ReactionConditions
+	Conditions *rules.ReactionConditions `json:"conditions,omitempty"`
 }
 
+type Reaction struct {
+	ID            uint32
+	Name          string
+	OnGain        [][]uint16
+	OnLost        [][]uint16
+	Enabled       bool
+	IsDebuff      bool
+	UseString     string
+	OnEndString   string
+	CooldownMS    int            // OnGain
+	CooldownEndMS int            // OnLost (0 = CooldownMS)
+	DebounceMS    int            // 0 = sem debounce
+	Confirm       action.Confirm // OnGain
+	ConfirmEnd    action.Confirm // OnLost
+	Conditions    rules.ReactionConditions
+}
+
+// Manager guarda as reações de buff/debuff (reactions.json e a janela de
+// config); quem dispara é o rules.Engine via Rules.
 type Manager struct {
-	reactions  map[uint32]*Reaction
-	mu         sync.RWMutex
-	cooldown   int64
-	enabled    bool
-	afkChecker AFKChecker
+	reactions map[uint32]*Reaction
+	mu        sync.RWMutex
+	enabled   bool
+	profile   string // arquivo usado por SaveToJSON/ReloadFromJSON
 }
 
 func NewManager() *Manager {
 	return &Manager{
 		reactions: make(map[uint32]*Reaction),
-		cooldown:  500,
 		enabled:   true,
+		profile:   "reactions.json",
 	}
 }
 
@@ -119,154 +134,6 @@ func (m *Manager) IsEnabled() bool {
 	return m.enabled
 }
 
-func (m *Manager) SetAFKChecker(checker AFKChecker) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.afkChecker = checker
-}
-
-func (m *Manager) isAFK() bool {
-	if m.afkChecker == nil {
-		return false
-	}
-	if !m.afkChecker.IsEnabled() {
-		return false
-	}
-	return m.afkChecker.IsAFK()
-}
-
-func (m *Manager) OnBuffGained(buffID uint32) {
-	m.mu.RLock()
-	enabled := m.enabled
-	reaction, exists := m.reactions[buffID]
-	afkChecker := m.afkChecker
-	m.mu.RUnlock()
-
-	if !enabled || !exists || !reaction.Enabled || reaction.IsDebuff {
-		return
-	}
-
-	if afkChecker != nil && afkChecker.IsEnabled() && afkChecker.IsAFK() {
-		return
-	}
-
-	if len(reaction.OnGain) == 0 {
-		return
-	}
-
-	go func() {
-		input.SendKeySequence(reaction.OnGain)
-	}()
-}
-
-func (m *Manager) OnBuffLost(buffID uint32) {
-	m.mu.RLock()
-	enabled := m.enabled
-	reaction, exists := m.reactions[buffID]
-	afkChecker := m.afkChecker
-	m.mu.RUnlock()
-
-	if !enabled || !exists || !reaction.Enabled || reaction.IsDebuff {
-		return
-	}
-
-	if afkChecker != nil && afkChecker.IsEnabled() && afkChecker.IsAFK() {
-		return
-	}
-
-	if len(reaction.OnLost) == 0 {
-		return
-	}
-
-	go func() {
-		input.SendKeySequence(reaction.OnLost)
-	}()
-}
-
-// Debug flag
-var DebugReaction = false
-
-func ToggleDebugReaction() bool {
-	DebugReaction = !DebugReaction
-	if DebugReaction {
-		fmt.Println("[DEBUG] Reaction debug ENABLED")
-	} else {
-		fmt.Println("[DEBUG] Reaction debug DISABLED")
-	}
-	return DebugReaction
-}
-
-func (m *Manager) OnDebuffGained(debuffID uint32) {
-	m.mu.RLock()
-	enabled := m.enabled
-	reaction, exists := m.reactions[debuffID]
-	afkChecker := m.afkChecker
-	m.mu.RUnlock()
-
-	if DebugReaction {
-		if !exists {
-			fmt.Printf("[REACT-DBG] debuffID=%d NOT FOUND in reactions map\n", debuffID)
-		} else {
-			fmt.Printf("[REACT-DBG] debuffID=%d FOUND: name=%s enabled=%v isDebuff=%v hasOnGain=%v\n",
-				debuffID, reaction.Name, reaction.Enabled, reaction.IsDebuff, len(reaction.OnGain) > 0)
-		}
-	}
-
-	if !enabled || !exists || !reaction.Enabled || !reaction.IsDebuff {
-		if DebugReaction && exists {
-			fmt.Printf("[REACT-DBG] debuffID=%d SKIPPED: managerEnabled=%v reactionEnabled=%v isDebuff=%v\n",
-				debuffID, enabled, reaction.Enabled, reaction.IsDebuff)
-		}
-		return
-	}
-
-	if afkChecker != nil && afkChecker.IsEnabled() && afkChecker.IsAFK() {
-		if DebugReaction {
-			fmt.Printf("[REACT-DBG] debuffID=%d SKIPPED: AFK\n", debuffID)
-		}
-		return
-	}
-
-	if len(reaction.OnGain) == 0 {
-		if DebugReaction {
-			fmt.Printf("[REACT-DBG] debuffID=%d SKIPPED: no OnGain keys\n", debuffID)
-		}
-		return
-	}
-
-	if DebugReaction {
-		fmt.Printf("[REACT-DBG] debuffID=%d EXECUTING: %s -> %s\n", debuffID, reaction.Name, reaction.UseString)
-	}
-
-	go func() {
-		input.SendKeySequence(reaction.OnGain)
-	}()
-}
-
-func (m *Manager) OnDebuffLost(debuffID uint32) {
-	m.mu.RLock()
-	enabled := m.enabled
-	reaction, exists := m.reactions[debuffID]
-	afkChecker := m.afkChecker
-	m.mu.RUnlock()
-
-	if !enabled || !exists || !reaction.Enabled || !reaction.IsDebuff {
-		return
-	}
-
-	if afkChecker != nil && afkChecker.IsEnabled() && afkChecker.IsAFK() {
-		return
-	}
-
-	if len(reaction.OnLost) == 0 {
-		return
-	}
-
-	go func() {
-		input.SendKeySequence(reaction.OnLost)
-	}()
-}
-
 func (m *Manager) GetAllReactions() []*Reaction {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -287,6 +154,40 @@ func (m *Manager) GetAllReactions() []*Reaction {
 	return reactions
 }
 
+// Rules converte as reações em regras do rules.Engine (nil com o manager
+// desligado). Chamado a cada evento: edições da janela valem na hora.
+func (m *Manager) Rules() []rules.Rule {
+	if !m.IsEnabled() {
+		return nil
+	}
+	var out []rules.Rule
+	for _, r := range m.GetAllReactions() {
+		m.mu.RLock()
+		conditions := r.Conditions
+		legacy := rules.Reaction{
+			Type:       int(r.ID),
+			Name:       r.Name,
+			OnStart:    r.UseString,
+			OnEnd:      r.OnEndString,
+			IsDebuff:   r.IsDebuff,
+			CooldownMS: r.CooldownMS,
+			Confirm:    r.Confirm,
+			ConfirmEnd: r.ConfirmEnd,
+			Conditions: &conditions,
+
+			CooldownEndMS: r.CooldownEndMS,
+			DebounceMS:    r.DebounceMS,
+		}
+		enabled := r.Enabled
+		m.mu.RUnlock()
+		for _, rule := range rules.FromReaction(legacy) {
+			rule.Disabled = !enabled
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
 func (m *Manager) GetActiveCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -375,6 +276,14 @@ func (m *Manager) LoadFromJSON(filename string) error {
 			UseString:   cfg.OnStart,
 			OnEndString: cfg.OnEnd,
 			CooldownMS:  cfg.CooldownMS,
+			Confirm:     cfg.Confirm,
+			ConfirmEnd:  cfg.ConfirmEnd,
+
+			CooldownEndMS: cfg.CooldownEndMS,
+			DebounceMS:    cfg.DebounceMS,
+		}
+		if cfg.Conditions != nil {
+			reaction.Conditions = *cfg.Conditions
 		}
 
 		if cfg.OnStart != "" {
@@ -398,8 +307,8 @@ func (m *Manager) LoadFromJSON(filename string) error {
 }
 
 func (m *Manager) SaveToJSON() error {
-	filename := "reactions.json"
 	m.mu.RLock()
+	filename := m.profile
 	defer m.mu.RUnlock()
 
 	configs := []ReactionConfig{}
@@ -419,14 +328,24 @@ func (m *Manager) SaveToJSON() error {
 
 	for _, r := range reactions {
 		if r.Enabled {
-			configs = append(configs, ReactionConfig{
+			cfg := ReactionConfig{
 				Type:       int(r.ID),
 				Name:       r.Name,
 				OnStart:    r.UseString,
 				OnEnd:      r.OnEndString,
 				IsDebuff:   r.IsDebuff,
 				CooldownMS: r.CooldownMS,
-			})
+				Confirm:    r.Confirm,
+				ConfirmEnd: r.ConfirmEnd,
+
+				CooldownEndMS: r.CooldownEndMS,
+				DebounceMS:    r.DebounceMS,
+			}
+			if !r.Conditions.Empty() {
+				conditions := r.Conditions
+				cfg.Conditions = &conditions
+			}
+			configs = append(configs, cfg)
 		}
 	}
 
@@ -445,12 +364,33 @@ func (m *Manager) SaveToJSON() error {
 func (m *Manager) ReloadFromJSON() error {
 	m.mu.Lock()
 	m.reactions = make(map[uint32]*Reaction)
+	profile := m.profile
 	m.mu.Unlock()
 
-	m.LoadFromJSON("reactions.json")
+	m.LoadFromJSON(profile)
 	return nil
 }
 
+// LoadProfile troca todas as reações pelas do arquivo (perfil por zona).
+// Save/Reload passam a usar esse arquivo.
+func (m *Manager) LoadProfile(filename string) error {
+	if _, err := os.Stat(filename); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.reactions = make(map[uint32]*Reaction)
+	m.profile = filename
+	m.mu.Unlock()
+	return m.LoadFromJSON(filename)
+}
+
+// GetProfile retorna o arquivo de reações ativo.
+func (m *Manager) GetProfile() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.profile
+}
+
 func (m *Manager) AddBuffReaction(r *Reaction) error {
 	if r.UseString != "" {
 		sequences, err := input.ParseKeySequence(r.UseString)
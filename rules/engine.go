@@ -0,0 +1,470 @@
+package rules
+
+import (
+	"archefriend/action"
+	"archefriend/sched"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ====================
+// Engine
+// ====================
+
+// Source fornece regras ao engine. É chamada a cada evento, então edições
+// feitas nas janelas de config valem na hora.
+type Source func() []Rule
+
+// Static é uma Source com uma lista fixa (ex: rules.json).
+func Static(list []Rule) Source {
+	return func() []Rule { return list }
+}
+
+// Event é um evento dos monitores (buff, debuff, skill, target). Stack e
+// LeftMs vêm do BuffInfo/DebuffInfo nos eventos de gained.
+type Event struct {
+	Type   string
+	ID     uint32
+	Stack  uint32
+	LeftMs uint32
+}
+
+// RuleStats conta os disparos de uma regra e os triggers suprimidos (por
+// cooldown, debounce ou condição que não bateu).
+type RuleStats struct {
+	ID         string
+	Name       string
+	Fired      int
+	Cooldown   int
+	Debounce   int
+	Conditions int
+}
+
+// Suppressed é o total de triggers suprimidos.
+func (s RuleStats) Suppressed() int {
+	return s.Cooldown + s.Debounce + s.Conditions
+}
+
+type ruleState struct {
+	name      string
+	lastFired time.Time
+	inBand    bool // threshold: valor já estava na faixa no último Tick
+	stats     RuleStats
+}
+
+// pendingLoss é um lost esperando o debounce (cancelado se o ID voltar).
+type pendingLoss struct {
+	rule Rule
+	ev   Event
+	key  string
+	due  time.Time
+}
+
+// Engine dispara as regras de todas as Sources. Enable, cooldown, condições
+// e parse das teclas são os mesmos para qualquer trigger.
+type Engine struct {
+	mu      sync.Mutex
+	enabled bool
+	sources map[string]Source
+	state   map[string]*ruleState // por Rule.ID
+	keys    map[string][][]uint16 // cache do parse
+	lostAt  map[string]time.Time  // último lost por "buff:ID"/"debuff:ID"
+	pending []pendingLoss
+	now     func() time.Time
+	sleep   func(time.Duration)
+
+	// Execução
+	ParseKeys   func(string) ([][]uint16, error)
+	ExecuteKeys func(lane sched.Lane, keys [][]uint16) error // lane = Rule.InputLane
+	AimAtTarget func() bool
+	Actions     *action.Executor // ações com confirm (nil = envia uma vez)
+	Spawn       func(fn func())  // nil = goroutine
+
+	// Sinais das condições e do threshold (nil = condição não bate)
+	IsAFK      func() bool
+	InCombat   func() bool
+	HasBuff    func(id uint32) bool
+	HasDebuff  func(id uint32) bool
+	TargetID   func() uint32
+	TargetType func() string                     // player, npc ("" = sem target/desconhecido)
+	Resource   func(name string) (float32, bool) // em %
+}
+
+// NewEngine cria o engine. now/sleep nil = relógio do sistema.
+func NewEngine(now func() time.Time, sleep func(time.Duration)) *Engine {
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	return &Engine{
+		enabled: true,
+		sources: make(map[string]Source),
+		state:   make(map[string]*ruleState),
+		keys:    make(map[string][][]uint16),
+		lostAt:  make(map[string]time.Time),
+		now:     now,
+		sleep:   sleep,
+	}
+}
+
+// SetSource adiciona/troca uma fonte de regras (nil remove).
+func (e *Engine) SetSource(name string, src Source) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if src == nil {
+		delete(e.sources, name)
+		return
+	}
+	e.sources[name] = src
+}
+
+// Rules retorna as regras de todas as fontes, em ordem de fonte.
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	names := make([]string, 0, len(e.sources))
+	for name := range e.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sources := make([]Source, len(names))
+	for i, name := range names {
+		sources[i] = e.sources[name]
+	}
+	e.mu.Unlock()
+
+	var out []Rule
+	for _, src := range sources {
+		for _, r := range src() {
+			if r.ID == "" {
+				r.ID = r.Name
+			}
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (e *Engine) Enable() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enabled = true
+}
+
+func (e *Engine) Disable() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enabled = false
+}
+
+func (e *Engine) Toggle() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enabled = !e.enabled
+	return e.enabled
+}
+
+func (e *Engine) IsEnabled() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enabled
+}
+
+// Eventos dos monitores
+func (e *Engine) OnBuffGained(id uint32)   { e.Dispatch(Event{Type: BuffGained, ID: id}) }
+func (e *Engine) OnBuffLost(id uint32)     { e.Dispatch(Event{Type: BuffLost, ID: id}) }
+func (e *Engine) OnDebuffGained(id uint32) { e.Dispatch(Event{Type: DebuffGained, ID: id}) }
+func (e *Engine) OnDebuffLost(id uint32)   { e.Dispatch(Event{Type: DebuffLost, ID: id}) }
+func (e *Engine) OnSkillTry(id uint32)     { e.Dispatch(Event{Type: SkillTry, ID: id}) }
+func (e *Engine) OnSkillCast(id uint32)    { e.Dispatch(Event{Type: SkillCast, ID: id}) }
+func (e *Engine) OnTargetChanged(id uint32) {
+	e.Dispatch(Event{Type: TargetChange, ID: id})
+}
+
+// debounceKey identifica o buff/debuff do evento ("" para os outros).
+func debounceKey(ev Event) (key string, lost bool) {
+	switch ev.Type {
+	case BuffGained:
+		return fmt.Sprintf("buff:%d", ev.ID), false
+	case BuffLost:
+		return fmt.Sprintf("buff:%d", ev.ID), true
+	case DebuffGained:
+		return fmt.Sprintf("debuff:%d", ev.ID), false
+	case DebuffLost:
+		return fmt.Sprintf("debuff:%d", ev.ID), true
+	}
+	return "", false
+}
+
+// Dispatch dispara as regras do evento e retorna os IDs disparados.
+//
+// Debounce (Rule.DebounceMs, só buff/debuff): regra de lost espera o ID
+// ficar ausente pelo debounce antes de disparar, e regra de gained ignora o
+// ID que volta dentro do debounce depois de um lost (leitura falhou).
+func (e *Engine) Dispatch(ev Event) []string {
+	if !e.IsEnabled() {
+		return nil
+	}
+	now := e.now()
+	key, lost := debounceKey(ev)
+	var lostAt time.Time
+	if key != "" {
+		e.mu.Lock()
+		if lost {
+			e.lostAt[key] = now
+		} else {
+			lostAt = e.lostAt[key]
+			delete(e.lostAt, key)
+			e.cancelPendingLocked(key)
+		}
+		e.mu.Unlock()
+	}
+
+	var fired []string
+	for _, r := range e.Rules() {
+		if r.Disabled || r.Trigger.Type != ev.Type {
+			continue
+		}
+		if r.Trigger.ID != 0 && r.Trigger.ID != ev.ID {
+			continue
+		}
+		if key != "" && r.DebounceMs > 0 {
+			debounce := time.Duration(r.DebounceMs) * time.Millisecond
+			if lost {
+				e.mu.Lock()
+				e.pending = append(e.pending, pendingLoss{rule: r, ev: ev, key: key, due: now.Add(debounce)})
+				e.mu.Unlock()
+				continue
+			}
+			if !lostAt.IsZero() && now.Sub(lostAt) < debounce {
+				e.mu.Lock()
+				e.stateLocked(r).stats.Debounce++
+				e.mu.Unlock()
+				continue
+			}
+		}
+		if e.fire(r, ev) {
+			fired = append(fired, r.ID)
+		}
+	}
+	return fired
+}
+
+// cancelPendingLocked descarta os lost pendentes do ID que voltou.
+func (e *Engine) cancelPendingLocked(key string) {
+	kept := e.pending[:0]
+	for _, p := range e.pending {
+		if p.key == key {
+			e.stateLocked(p.rule).stats.Debounce++
+			continue
+		}
+		kept = append(kept, p)
+	}
+	e.pending = kept
+}
+
+// Tick dispara os lost cujo debounce venceu e avalia as regras de
+// threshold: cada uma dispara ao entrar na faixa (de novo só depois de
+// sair). Valor 0 conta como morto/sem leitura.
+func (e *Engine) Tick() []string {
+	if !e.IsEnabled() {
+		return nil
+	}
+	var fired []string
+	now := e.now()
+	e.mu.Lock()
+	var due []pendingLoss
+	kept := e.pending[:0]
+	for _, p := range e.pending {
+		if now.Before(p.due) {
+			kept = append(kept, p)
+			continue
+		}
+		due = append(due, p)
+	}
+	e.pending = kept
+	e.mu.Unlock()
+	for _, p := range due {
+		if e.fire(p.rule, p.ev) {
+			fired = append(fired, p.rule.ID)
+		}
+	}
+
+	if e.Resource == nil {
+		return fired
+	}
+	for _, r := range e.Rules() {
+		if r.Disabled || r.Trigger.Type != Threshold {
+			continue
+		}
+		value, ok := e.Resource(r.Trigger.Resource)
+		in := ok && value > 0 && r.Trigger.matches(value)
+
+		e.mu.Lock()
+		st := e.stateLocked(r)
+		was := st.inBand
+		st.inBand = in
+		e.mu.Unlock()
+
+		if in && !was && e.fire(r, Event{Type: Threshold}) {
+			fired = append(fired, r.ID)
+		}
+	}
+	return fired
+}
+
+func (t Trigger) matches(value float32) bool {
+	if t.Compare == CompareAbove {
+		return value > t.Value
+	}
+	return value < t.Value
+}
+
+// Stats retorna os disparos por regra, em ordem de ID.
+func (e *Engine) Stats() []RuleStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]RuleStats, 0, len(e.state))
+	for id, st := range e.state {
+		s := st.stats
+		s.ID, s.Name = id, st.name
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (e *Engine) stateLocked(r Rule) *ruleState {
+	st, ok := e.state[r.ID]
+	if !ok {
+		st = &ruleState{}
+		e.state[r.ID] = st
+	}
+	st.name = r.Name
+	return st
+}
+
+// fire checa condições e cooldown, marca o disparo e executa as ações.
+func (e *Engine) fire(r Rule, ev Event) bool {
+	if !e.check(r.Conditions, ev) {
+		e.mu.Lock()
+		e.stateLocked(r).stats.Conditions++
+		e.mu.Unlock()
+		return false
+	}
+	now := e.now()
+	e.mu.Lock()
+	st := e.stateLocked(r)
+	if r.CooldownMs > 0 && !st.lastFired.IsZero() && now.Sub(st.lastFired) < time.Duration(r.CooldownMs)*time.Millisecond {
+		st.stats.Cooldown++
+		e.mu.Unlock()
+		return false
+	}
+	st.lastFired = now
+	st.stats.Fired++
+	e.mu.Unlock()
+
+	fmt.Printf("[RULES] %s (%s)\n", r.Name, r.Trigger)
+	run := func() { e.run(r) }
+	if e.Spawn != nil {
+		e.Spawn(run)
+	} else {
+		go run()
+	}
+	return true
+}
+
+func (e *Engine) check(c Conditions, ev Event) bool {
+	if c.NotAFK && e.IsAFK != nil && e.IsAFK() {
+		return false
+	}
+	if c.InCombat && (e.InCombat == nil || !e.InCombat()) {
+		return false
+	}
+	if c.TargetType != "" && (e.TargetType == nil || e.TargetType() != c.TargetType) {
+		return false
+	}
+	if ev.Stack < c.MinStacks || ev.LeftMs < c.MinDurationMs {
+		return false
+	}
+	if c.Buff != 0 && (e.HasBuff == nil || !e.HasBuff(c.Buff)) {
+		return false
+	}
+	if c.NoBuff != 0 && (e.HasBuff == nil || e.HasBuff(c.NoBuff)) {
+		return false
+	}
+	if c.Debuff != 0 && (e.HasDebuff == nil || !e.HasDebuff(c.Debuff)) {
+		return false
+	}
+	if c.NoDebuff != 0 && (e.HasDebuff == nil || e.HasDebuff(c.NoDebuff)) {
+		return false
+	}
+	if c.HPBelow > 0 {
+		if e.Resource == nil {
+			return false
+		}
+		hp, ok := e.Resource(ResourcePlayerHP)
+		if !ok || hp <= 0 || hp >= c.HPBelow {
+			return false
+		}
+	}
+	if c.HasTarget && (e.TargetID == nil || e.TargetID() == 0) {
+		return false
+	}
+	return true
+}
+
+// run executa as ações da regra em ordem.
+func (e *Engine) run(r Rule) {
+	for _, a := range r.Actions {
+		if a.DelayMs > 0 {
+			e.sleep(time.Duration(a.DelayMs) * time.Millisecond)
+		}
+		if a.Aimbot && e.AimAtTarget != nil {
+			if e.AimAtTarget() {
+				fmt.Printf("[RULES] Aimbot para %s\n", r.Name)
+			}
+		}
+		if a.Keys == "" || e.ExecuteKeys == nil {
+			continue
+		}
+		keys, err := e.parse(a.Keys)
+		if err != nil {
+			fmt.Printf("[RULES] %s: erro ao parsear teclas '%s': %v\n", r.Name, a.Keys, err)
+			continue
+		}
+		press := func() {
+			if err := e.ExecuteKeys(r.InputLane(), keys); err != nil {
+				fmt.Printf("[RULES] %s: erro ao executar teclas: %v\n", r.Name, err)
+			}
+		}
+		if e.Actions == nil || a.Confirm.Empty() {
+			press()
+			continue
+		}
+		e.Actions.Do(r.Name, press, a.Confirm)
+	}
+}
+
+func (e *Engine) parse(s string) ([][]uint16, error) {
+	e.mu.Lock()
+	keys, ok := e.keys[s]
+	e.mu.Unlock()
+	if ok {
+		return keys, nil
+	}
+	if e.ParseKeys == nil {
+		return nil, fmt.Errorf("sem parser de teclas")
+	}
+	keys, err := e.ParseKeys(s)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.keys[s] = keys
+	e.mu.Unlock()
+	return keys, nil
+}
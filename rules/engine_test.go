@@ -0,0 +1,335 @@
+package rules_test
+
+import (
+	"archefriend/rules"
+	"archefriend/sched"
+	"archefriend/sim"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRules(t *testing.T) {
+	dir := t.TempDir()
+
+	// Importers dos formatos antigos
+	reactionsFile := filepath.Join(dir, "reactions.json")
+	skillsFile := filepath.Join(dir, "skill_reactions.json")
+	if err := os.WriteFile(reactionsFile, []byte(`[
+		{"type": 8000, "name": "Shield", "onStart": "", "onEnd": "F2", "isDebuff": false, "cooldownMs": 0},
+		{"type": 141, "name": "Tripped", "onStart": "F3", "onEnd": "", "isDebuff": true, "cooldownMs": 1000}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skillsFile, []byte(`{"reactions": [
+		{"skillId": 10005, "name": "Fireball", "onCast": "ALT+Q", "enabled": true, "cooldownMs": 500, "useAimbot": true, "aimbotOnTry": true},
+		{"skillId": 10010, "name": "Charge", "onCast": "F1", "enabled": false, "cooldownMs": 0}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := rules.ImportReactions(reactionsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skills, err := rules.ImportSkillReactions(skillsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy = append(legacy, skills...)
+	var ids []string
+	for _, r := range legacy {
+		ids = append(ids, r.ID)
+	}
+	want := "reactions/debuff/141/start,reactions/buff/8000/end,skill_reactions/10005/cast,skill_reactions/10005/try,skill_reactions/10010/cast"
+	if strings.Join(ids, ",") != want {
+		t.Fatalf("unexpected imported rules %v", ids)
+	}
+
+	// rules.json: threshold e target_change (import_legacy ausente = true)
+	rulesFile := filepath.Join(dir, "rules.json")
+	if err := os.WriteFile(rulesFile, []byte(`{"rules": [
+		{"name": "low_hp", "trigger": {"type": "threshold", "resource": "player_hp", "value": 40},
+		 "conditions": {"no_buff": 9000}, "actions": [{"keys": "F5"}]},
+		{"name": "new_target", "trigger": {"type": "target_change"},
+		 "conditions": {"has_target": true}, "actions": [{"delay_ms": 100, "keys": "F6"}]}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := rules.LoadFile(rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.ImportLegacy || len(f.Rules) != 2 || f.Rules[0].ID != "low_hp" || f.Rules[0].Trigger.Compare != rules.CompareBelow {
+		t.Fatalf("unexpected rules file %+v", f)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"rules": [{"name": "x", "trigger": {"type": "on_fire"}, "actions": [{"keys": "1"}]}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules.LoadFile(bad); err == nil || !strings.Contains(err.Error(), "trigger desconhecido") {
+		t.Fatalf("expected unknown trigger error, got %v", err)
+	}
+
+	// Engine síncrono no relógio virtual
+	clock := sim.NewVirtualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	e := rules.NewEngine(clock.Now, clock.Sleep)
+	var names, sent []string
+	aims := 0
+	afk, hp, target := false, float32(100), uint32(0)
+	buffs := map[uint32]bool{}
+	e.Spawn = func(fn func()) { fn() }
+	e.ParseKeys = func(s string) ([][]uint16, error) {
+		names = append(names, s)
+		return [][]uint16{{uint16(len(names) - 1)}}, nil
+	}
+	e.ExecuteKeys = func(lane sched.Lane, keys [][]uint16) error {
+		sent = append(sent, names[keys[0][0]])
+		return nil
+	}
+	e.AimAtTarget = func() bool { aims++; return true }
+	e.IsAFK = func() bool { return afk }
+	e.HasBuff = func(id uint32) bool { return buffs[id] }
+	e.TargetID = func() uint32 { return target }
+	e.Resource = func(name string) (float32, bool) { return hp, name == rules.ResourcePlayerHP }
+	e.SetSource("legacy", rules.Static(legacy))
+	e.SetSource("rules", rules.Static(f.Rules))
+
+	expectSent := func(what, keys string) {
+		t.Helper()
+		if strings.Join(sent, ",") != keys {
+			t.Fatalf("%s: sent %v, want %q", what, sent, keys)
+		}
+		sent = nil
+	}
+
+	// Debuff com cooldown de 1s e pausa em AFK
+	e.OnDebuffGained(141)
+	e.OnDebuffGained(141)
+	expectSent("debuff cooldown", "F3")
+	clock.Advance(time.Second)
+	afk = true
+	e.OnDebuffGained(141)
+	expectSent("afk", "")
+	afk = false
+	e.OnDebuffGained(141)
+	e.OnBuffGained(8000)
+	e.OnBuffLost(8000)
+	e.OnDebuffLost(141)
+	expectSent("debuff/buff", "F3,F2")
+
+	// Skill: aimbot no try, teclas no cast; reação desligada não dispara
+	e.OnSkillTry(10005)
+	e.OnSkillCast(10005)
+	e.OnSkillCast(10010)
+	expectSent("skill", "ALT+Q")
+	if aims != 1 {
+		t.Fatalf("expected 1 aimbot on try, got %d", aims)
+	}
+
+	// Threshold: uma vez ao entrar na faixa, de novo só depois de sair
+	for _, v := range []float32{80, 35, 30, 25, 60, 30} {
+		hp = v
+		e.Tick()
+	}
+	expectSent("threshold", "F5,F5")
+	buffs[9000] = true
+	hp = 80
+	e.Tick()
+	hp = 30
+	e.Tick()
+	expectSent("threshold no_buff", "")
+
+	// target_change com has_target; delay no relógio virtual
+	before := clock.Now()
+	e.OnTargetChanged(0)
+	target = 7
+	e.OnTargetChanged(7)
+	expectSent("target", "F6")
+	if d := clock.Now().Sub(before); d != 100*time.Millisecond {
+		t.Fatalf("expected 100ms action delay, got %s", d)
+	}
+
+	// Engine desligado não dispara nada
+	e.Disable()
+	clock.Advance(2 * time.Second)
+	e.OnDebuffGained(141)
+	expectSent("disabled", "")
+	stats := e.Stats()
+	fired := map[string]int{}
+	for _, s := range stats {
+		fired[s.ID] = s.Fired
+	}
+	if fired["reactions/debuff/141/start"] != 2 || fired["low_hp"] != 2 || fired["skill_reactions/10005/try"] != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRuleConditions(t *testing.T) {
+	dir := t.TempDir()
+
+	// Condições no reactions.json: só no onStart valem stacks/duração
+	file := filepath.Join(dir, "reactions.json")
+	if err := os.WriteFile(file, []byte(`[
+		{"type": 8100, "name": "Frenzy", "onStart": "F1", "onEnd": "F2", "isDebuff": false, "cooldownMs": 0,
+		 "conditions": {"inCombat": true, "targetType": "player", "hpBelow": 60, "minStacks": 3, "notBuff": 9000}},
+		{"type": 141, "name": "Tripped", "onStart": "F3", "onEnd": "", "isDebuff": true, "cooldownMs": 0,
+		 "conditions": {"minDurationMs": 2000}},
+		{"type": 156, "name": "Fear", "onStart": "F4", "onEnd": "", "isDebuff": true, "cooldownMs": 0}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := rules.ImportReactions(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]rules.Rule{}
+	for _, r := range list {
+		byID[r.ID] = r
+	}
+	start, end := byID["reactions/buff/8100/start"].Conditions, byID["reactions/buff/8100/end"].Conditions
+	if !start.NotAFK || !start.InCombat || start.TargetType != rules.TargetPlayer || start.HPBelow != 60 ||
+		start.MinStacks != 3 || start.NoBuff != 9000 {
+		t.Fatalf("unexpected start conditions %+v", start)
+	}
+	if !end.InCombat || end.MinStacks != 0 || end.MinDurationMs != 0 {
+		t.Fatalf("unexpected end conditions %+v", end)
+	}
+	if c := byID["reactions/debuff/156/start"].Conditions; c != (rules.Conditions{NotAFK: true}) {
+		t.Fatalf("reaction without conditions got %+v", c)
+	}
+
+	clock := sim.NewVirtualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	e := rules.NewEngine(clock.Now, clock.Sleep)
+	var sent []string
+	inCombat, targetType, hp := false, "", float32(100)
+	buffs := map[uint32]bool{}
+	e.Spawn = func(fn func()) { fn() }
+	e.ParseKeys = func(s string) ([][]uint16, error) { return [][]uint16{{uint16(s[1] - '0')}}, nil }
+	e.ExecuteKeys = func(lane sched.Lane, keys [][]uint16) error {
+		sent = append(sent, fmt.Sprintf("F%d", keys[0][0]))
+		return nil
+	}
+	e.InCombat = func() bool { return inCombat }
+	e.TargetType = func() string { return targetType }
+	e.HasBuff = func(id uint32) bool { return buffs[id] }
+	e.Resource = func(name string) (float32, bool) { return hp, name == rules.ResourcePlayerHP }
+	e.SetSource("reactions", rules.Static(list))
+
+	gained := func(stack uint32) {
+		e.Dispatch(rules.Event{Type: rules.BuffGained, ID: 8100, Stack: stack, LeftMs: 10000})
+	}
+	// Cada condição que falta bloqueia; com todas, dispara
+	steps := []struct {
+		what  string
+		setup func()
+		stack uint32
+		want  bool
+	}{
+		{"out of combat", func() {}, 3, false},
+		{"npc target", func() { inCombat, targetType = true, rules.TargetNPC }, 3, false},
+		{"hp high", func() { targetType = rules.TargetPlayer }, 3, false},
+		{"few stacks", func() { hp = 50 }, 2, false},
+		{"all met", func() {}, 3, true},
+		{"blocking buff", func() { buffs[9000] = true }, 5, false},
+	}
+	for _, s := range steps {
+		s.setup()
+		sent = nil
+		gained(s.stack)
+		if fired := len(sent) == 1; fired != s.want {
+			t.Fatalf("%s: fired=%v, want %v", s.what, fired, s.want)
+		}
+	}
+
+	// Lost ignora stacks; duração mínima no debuff
+	buffs[9000] = false
+	sent = nil
+	e.OnBuffLost(8100)
+	e.Dispatch(rules.Event{Type: rules.DebuffGained, ID: 141, LeftMs: 1500})
+	e.Dispatch(rules.Event{Type: rules.DebuffGained, ID: 141, LeftMs: 2500})
+	if strings.Join(sent, ",") != "F2,F3" {
+		t.Fatalf("unexpected lost/duration keys %v", sent)
+	}
+
+	// target_type inválido é rejeitado
+	bad := []rules.Rule{{Name: "x", Trigger: rules.Trigger{Type: rules.BuffGained}, Conditions: rules.Conditions{TargetType: "mob"},
+		Actions: []rules.Action{{Keys: "F1"}}}}
+	if _, err := rules.Validate(bad); err == nil {
+		t.Fatalf("expected target_type error")
+	}
+}
+
+func TestRuleDebounce(t *testing.T) {
+	clock := sim.NewVirtualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	e := rules.NewEngine(clock.Now, clock.Sleep)
+	var sent []string
+	e.Spawn = func(fn func()) { fn() }
+	e.ParseKeys = func(s string) ([][]uint16, error) { return [][]uint16{{uint16(s[1] - '0')}}, nil }
+	e.ExecuteKeys = func(lane sched.Lane, keys [][]uint16) error {
+		sent = append(sent, fmt.Sprintf("F%d", keys[0][0]))
+		return nil
+	}
+	var list []rules.Rule
+	list = append(list, rules.FromReaction(rules.Reaction{Type: 8000, Name: "Shield", OnStart: "F1", OnEnd: "F2",
+		CooldownMS: 1000, CooldownEndMS: 5000, DebounceMS: 300})...)
+	list = append(list, rules.FromReaction(rules.Reaction{Type: 141, Name: "Tripped", OnStart: "F3", OnEnd: "F4",
+		IsDebuff: true})...)
+	e.SetSource("reactions", rules.Static(list))
+
+	expectSent := func(what, keys string) {
+		t.Helper()
+		if strings.Join(sent, ",") != keys {
+			t.Fatalf("%s: sent %v, want %q", what, sent, keys)
+		}
+		sent = nil
+	}
+	advance := func(ms int) {
+		for i := 0; i < ms/100; i++ {
+			clock.Advance(100 * time.Millisecond)
+			e.Tick()
+		}
+	}
+
+	// Leitura falha: lost + gained em 100ms não dispara nada
+	e.OnBuffGained(8000)
+	advance(1000)
+	e.OnBuffLost(8000)
+	advance(100)
+	e.OnBuffGained(8000)
+	advance(1000)
+	expectSent("flap", "F1")
+
+	// Lost de verdade: dispara depois do debounce; o gained seguinte dispara
+	e.OnBuffLost(8000)
+	advance(200)
+	expectSent("loss within debounce", "")
+	advance(100)
+	e.OnBuffGained(8000)
+	expectSent("loss + regain", "F2,F1")
+
+	// Cooldowns separados: gained (1s) e lost (5s)
+	advance(1000)
+	e.OnBuffLost(8000)
+	advance(300)
+	e.OnBuffGained(8000)
+	expectSent("end cooldown", "F1")
+
+	// Sem debounceMs (padrão): lost dispara na hora e um stun encadeado
+	// (gained logo após o lost) dispara de novo
+	e.OnDebuffGained(141)
+	e.OnDebuffLost(141)
+	e.OnDebuffGained(141)
+	expectSent("no debounce", "F3,F4,F3")
+
+	stats := map[string]rules.RuleStats{}
+	for _, s := range e.Stats() {
+		stats[s.ID] = s
+	}
+	start := stats[rules.ReactionRuleID(false, 8000, "start")]
+	end := stats[rules.ReactionRuleID(false, 8000, "end")]
+	if start.Fired != 3 || start.Debounce != 1 || start.Cooldown != 0 {
+		t.Fatalf("unexpected start stats %+v", start)
+	}
+	if end.Fired != 1 || end.Debounce != 1 || end.Cooldown != 1 || end.Suppressed() != 2 {
+		t.Fatalf("unexpected end stats %+v", end)
+	}
+}
@@ -0,0 +1,209 @@
+package rules
+
+import (
+	"archefriend/action"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ====================
+// Importers
+// ====================
+
+// Reaction é uma entrada do reactions.json (reaction.ReactionConfig).
+type Reaction struct {
+	Type       int            `json:"type"`
+	Name       string         `json:"name"`
+	OnStart    string         `json:"onStart"`
+	OnEnd      string         `json:"onEnd"`
+	IsDebuff   bool           `json:"isDebuff"`
+	CooldownMS int            `json:"cooldownMs"`
+	Confirm    action.Confirm `json:"confirm,omitempty"`
+	ConfirmEnd action.Confirm `json:"confirmEnd,omitempty"`
+
+	CooldownEndMS int `json:"cooldownEndMs,omitempty"` // cooldown do onEnd (0 = cooldownMs)
+	DebounceMS    int `json:"debounceMs,omitempty"`    // 0 = sem debounce
+
+	Conditions *ReactionConditions `json:"conditions,omitempty"`
+}
+
+// ReactionRuleID é o ID da regra gerada por uma reação (edge start/end),
+// usado para achar as stats da reação no engine.
+func ReactionRuleID(isDebuff bool, typeID uint32, edge string) string {
+	kind := "buff"
+	if isDebuff {
+		kind = "debuff"
+	}
+	return fmt.Sprintf("reactions/%s/%d/%s", kind, typeID, edge)
+}
+
+// ReactionConditions são as condições opcionais de uma reação do
+// reactions.json (camelCase como o resto do arquivo). Stacks e duração só
+// valem para onStart, o onEnd não tem essas leituras:
+//
+//	"conditions": {"inCombat": true, "targetType": "player", "hpBelow": 60,
+//	               "minStacks": 3, "minDurationMs": 2000, "notBuff": 8000}
+type ReactionConditions struct {
+	InCombat      bool    `json:"inCombat,omitempty"`
+	TargetType    string  `json:"targetType,omitempty"` // player, npc ("" = qualquer)
+	HPBelow       float32 `json:"hpBelow,omitempty"`    // HP do player < X%
+	MinStacks     uint32  `json:"minStacks,omitempty"`
+	MinDurationMs uint32  `json:"minDurationMs,omitempty"`
+	NotBuff       uint32  `json:"notBuff,omitempty"` // não reage com esse buff ativo
+}
+
+// Empty indica que a reação não tem condições (só o AFK).
+func (c ReactionConditions) Empty() bool {
+	return c == ReactionConditions{}
+}
+
+func (c ReactionConditions) String() string {
+	var parts []string
+	if c.InCombat {
+		parts = append(parts, "combat")
+	}
+	if c.TargetType != "" {
+		parts = append(parts, c.TargetType)
+	}
+	if c.HPBelow > 0 {
+		parts = append(parts, fmt.Sprintf("hp<%.0f%%", c.HPBelow))
+	}
+	if c.MinStacks > 0 {
+		parts = append(parts, fmt.Sprintf("stacks>=%d", c.MinStacks))
+	}
+	if c.MinDurationMs > 0 {
+		parts = append(parts, fmt.Sprintf("dur>=%dms", c.MinDurationMs))
+	}
+	if c.NotBuff != 0 {
+		parts = append(parts, fmt.Sprintf("!buff %d", c.NotBuff))
+	}
+	return strings.Join(parts, " ")
+}
+
+// SkillReaction é uma entrada do skill_reactions.json (skill.SkillReaction).
+type SkillReaction struct {
+	SkillID     uint32         `json:"skillId"`
+	Name        string         `json:"name"`
+	OnCast      string         `json:"onCast"`
+	Enabled     bool           `json:"enabled"`
+	CooldownMS  int            `json:"cooldownMs"`
+	UseAimbot   bool           `json:"useAimbot"`
+	AimbotOnTry bool           `json:"aimbotOnTry"`
+	Confirm     action.Confirm `json:"confirm,omitempty"`
+}
+
+// FromReaction converte uma reação de buff/debuff: onStart vira regra de
+// gained e onEnd de lost, ambas pausadas em AFK como no reaction.Manager,
+// cada uma com seu cooldown e com o debounce da reação.
+func FromReaction(r Reaction) []Rule {
+	var cond ReactionConditions
+	if r.Conditions != nil {
+		cond = *r.Conditions
+	}
+	gained, lost := BuffGained, BuffLost
+	if r.IsDebuff {
+		gained, lost = DebuffGained, DebuffLost
+	}
+	var out []Rule
+	add := func(trigger, edge, keys string, confirm action.Confirm, cooldown int) {
+		if keys == "" {
+			return
+		}
+		c := Conditions{
+			NotAFK:     true,
+			InCombat:   cond.InCombat,
+			TargetType: cond.TargetType,
+			HPBelow:    cond.HPBelow,
+			NoBuff:     cond.NotBuff,
+		}
+		if edge == "start" {
+			c.MinStacks, c.MinDurationMs = cond.MinStacks, cond.MinDurationMs
+		}
+		out = append(out, Rule{
+			ID:         ReactionRuleID(r.IsDebuff, uint32(r.Type), edge),
+			Name:       r.Name,
+			Trigger:    Trigger{Type: trigger, ID: uint32(r.Type)},
+			Conditions: c,
+			Actions:    []Action{{Keys: keys, Confirm: confirm}},
+			CooldownMs: cooldown,
+			DebounceMs: max(r.DebounceMS, 0),
+		})
+	}
+	cooldownEnd := r.CooldownEndMS
+	if cooldownEnd <= 0 {
+		cooldownEnd = r.CooldownMS
+	}
+	add(gained, "start", r.OnStart, r.Confirm, r.CooldownMS)
+	add(lost, "end", r.OnEnd, r.ConfirmEnd, cooldownEnd)
+	return out
+}
+
+// FromSkillReaction converte uma reação de skill: onCast vira regra de
+// skill_cast (com aimbot antes, se não for no try) e aimbotOnTry uma regra
+// de skill_try só com o aimbot.
+func FromSkillReaction(r SkillReaction) []Rule {
+	if r.OnCast == "" {
+		return nil
+	}
+	id := fmt.Sprintf("skill_reactions/%d", r.SkillID)
+	out := []Rule{{
+		ID:         id + "/cast",
+		Name:       r.Name,
+		Trigger:    Trigger{Type: SkillCast, ID: r.SkillID},
+		Actions:    []Action{{Aimbot: r.UseAimbot && !r.AimbotOnTry, Keys: r.OnCast, Confirm: r.Confirm}},
+		CooldownMs: r.CooldownMS,
+		Disabled:   !r.Enabled,
+	}}
+	if r.UseAimbot && r.AimbotOnTry {
+		out = append(out, Rule{
+			ID:         id + "/try",
+			Name:       r.Name,
+			Trigger:    Trigger{Type: SkillTry, ID: r.SkillID},
+			Actions:    []Action{{Aimbot: true}},
+			CooldownMs: r.CooldownMS,
+			Disabled:   !r.Enabled,
+		})
+	}
+	return out
+}
+
+// ImportReactions lê um reactions.json e converte em regras.
+func ImportReactions(filename string) ([]Rule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", filename, err)
+	}
+	var list []Reaction
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("erro ao parsear %s: %v", filename, err)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	var out []Rule
+	for _, r := range list {
+		out = append(out, FromReaction(r)...)
+	}
+	return Validate(out)
+}
+
+// ImportSkillReactions lê um skill_reactions.json e converte em regras.
+func ImportSkillReactions(filename string) ([]Rule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", filename, err)
+	}
+	var file struct {
+		Reactions []SkillReaction `json:"reactions"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("erro ao parsear %s: %v", filename, err)
+	}
+	sort.SliceStable(file.Reactions, func(i, j int) bool { return file.Reactions[i].SkillID < file.Reactions[j].SkillID })
+	var out []Rule
+	for _, r := range file.Reactions {
+		out = append(out, FromSkillReaction(r)...)
+	}
+	return Validate(out)
+}
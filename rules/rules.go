@@ -0,0 +1,240 @@
+package rules
+
+import (
+	"archefriend/action"
+	"archefriend/sched"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ====================
+// Rules
+// ====================
+
+// Triggers
+const (
+	BuffGained   = "buff_gained"
+	BuffLost     = "buff_lost"
+	DebuffGained = "debuff_gained"
+	DebuffLost   = "debuff_lost"
+	SkillTry     = "skill_try"
+	SkillCast    = "skill_cast"
+	TargetChange = "target_change"
+	Threshold    = "threshold"
+)
+
+// Recursos do trigger threshold e da condição hp_below (sempre em %),
+// mesmos nomes das threshold rules do bot
+const (
+	ResourcePlayerHP = "player_hp"
+	ResourcePlayerMP = "player_mp"
+	ResourceTargetHP = "target_hp"
+)
+
+// Tipos de target da condição target_type
+const (
+	TargetPlayer = "player"
+	TargetNPC    = "npc"
+)
+
+// Comparações do threshold
+const (
+	CompareBelow = "below" // padrão
+	CompareAbove = "above"
+)
+
+// Trigger é o evento que dispara a regra. ID 0 = qualquer ID (buff, debuff,
+// skill ou novo target). Threshold dispara ao entrar na faixa:
+//
+//	{"type": "debuff_gained", "id": 141}
+//	{"type": "skill_cast", "id": 10005}
+//	{"type": "threshold", "resource": "player_hp", "value": 40}
+type Trigger struct {
+	Type     string  `json:"type"`
+	ID       uint32  `json:"id,omitempty"`
+	Resource string  `json:"resource,omitempty"`
+	Compare  string  `json:"compare,omitempty"` // below (padrão), above
+	Value    float32 `json:"value,omitempty"`
+}
+
+func (t Trigger) String() string {
+	if t.Type == Threshold {
+		return fmt.Sprintf("%s %s %s %.0f%%", t.Type, t.Resource, t.Compare, t.Value)
+	}
+	if t.ID == 0 {
+		return t.Type
+	}
+	return fmt.Sprintf("%s %d", t.Type, t.ID)
+}
+
+// Conditions são checadas no disparo; todas precisam bater. Sinal sem
+// provider no Engine (ex: HasBuff nil) = condição não bate. Stacks e
+// duração vêm do evento (buff/debuff gained; nos outros eventos são 0).
+type Conditions struct {
+	NotAFK        bool    `json:"not_afk,omitempty"`
+	InCombat      bool    `json:"in_combat,omitempty"`
+	TargetType    string  `json:"target_type,omitempty"` // player, npc ("" = qualquer)
+	Buff          uint32  `json:"buff,omitempty"`        // buff presente no player
+	NoBuff        uint32  `json:"no_buff,omitempty"`     // buff ausente
+	Debuff        uint32  `json:"debuff,omitempty"`      // debuff presente
+	NoDebuff      uint32  `json:"no_debuff,omitempty"`   // debuff ausente
+	HPBelow       float32 `json:"hp_below,omitempty"`    // HP do player < X%
+	HasTarget     bool    `json:"has_target,omitempty"`
+	MinStacks     uint32  `json:"min_stacks,omitempty"`      // stacks do buff >= N
+	MinDurationMs uint32  `json:"min_duration_ms,omitempty"` // tempo restante >= N ms
+}
+
+// Action é um passo da regra, executado em ordem: espera, aimbot e teclas.
+// Teclas no formato de input.ParseKeySequence ("ALT+Q, F1"). Com confirm as
+// teclas repetem até confirmar (ver action.Confirm).
+type Action struct {
+	DelayMs int            `json:"delay_ms,omitempty"`
+	Aimbot  bool           `json:"aimbot,omitempty"`
+	Keys    string         `json:"keys,omitempty"`
+	Confirm action.Confirm `json:"confirm,omitempty"`
+}
+
+// Rule é trigger + condições + ações. ID identifica o cooldown da regra e
+// precisa ser único (sem ID = name). Lane é a prioridade das teclas no
+// scheduler de input (sem lane = InputLane).
+//
+//	{"id": "anti-stun", "name": "Anti-Stun", "cooldown_ms": 1000,
+//	 "trigger": {"type": "debuff_gained", "id": 141},
+//	 "conditions": {"not_afk": true},
+//	 "actions": [{"keys": "F3"}]}
+type Rule struct {
+	ID         string     `json:"id,omitempty"`
+	Name       string     `json:"name"`
+	Trigger    Trigger    `json:"trigger"`
+	Conditions Conditions `json:"conditions,omitempty"`
+	Actions    []Action   `json:"actions"`
+	CooldownMs int        `json:"cooldown_ms,omitempty"`
+	DebounceMs int        `json:"debounce_ms,omitempty"` // buff/debuff: ver Engine.Dispatch
+	Disabled   bool       `json:"disabled,omitempty"`
+	Lane       sched.Lane `json:"lane,omitempty"`
+}
+
+// InputLane é a lane das teclas da regra: a configurada ou, sem lane,
+// cc_break para debuff gained, potion para threshold e rotation no resto.
+func (r Rule) InputLane() sched.Lane {
+	if r.Lane != "" {
+		return r.Lane
+	}
+	switch r.Trigger.Type {
+	case DebuffGained:
+		return sched.LaneCCBreak
+	case Threshold:
+		return sched.LanePotion
+	}
+	return sched.LaneRotation
+}
+
+// Validate normaliza a regra (ID e compare padrão) e checa trigger e ações.
+func (r *Rule) Validate() error {
+	if r.ID == "" {
+		r.ID = r.Name
+	}
+	if r.ID == "" {
+		return fmt.Errorf("regra sem id/name")
+	}
+	switch r.Trigger.Type {
+	case BuffGained, BuffLost, DebuffGained, DebuffLost, SkillTry, SkillCast, TargetChange:
+	case Threshold:
+		switch r.Trigger.Resource {
+		case ResourcePlayerHP, ResourcePlayerMP, ResourceTargetHP:
+		default:
+			return fmt.Errorf("regra '%s': resource desconhecido: %q", r.ID, r.Trigger.Resource)
+		}
+		switch r.Trigger.Compare {
+		case "":
+			r.Trigger.Compare = CompareBelow
+		case CompareBelow, CompareAbove:
+		default:
+			return fmt.Errorf("regra '%s': compare desconhecido: %q", r.ID, r.Trigger.Compare)
+		}
+	default:
+		return fmt.Errorf("regra '%s': trigger desconhecido: %q", r.ID, r.Trigger.Type)
+	}
+	switch r.Conditions.TargetType {
+	case "", TargetPlayer, TargetNPC:
+	default:
+		return fmt.Errorf("regra '%s': target_type desconhecido: %q", r.ID, r.Conditions.TargetType)
+	}
+	if r.Lane != "" && !r.Lane.Valid() {
+		return fmt.Errorf("regra '%s': lane desconhecida: %q", r.ID, r.Lane)
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("regra '%s': sem actions", r.ID)
+	}
+	for i, a := range r.Actions {
+		if a.Keys == "" && !a.Aimbot && a.DelayMs == 0 {
+			return fmt.Errorf("regra '%s': action %d vazia", r.ID, i+1)
+		}
+	}
+	return nil
+}
+
+// Validate valida uma lista de regras e checa IDs repetidos.
+func Validate(list []Rule) ([]Rule, error) {
+	out := make([]Rule, 0, len(list))
+	seen := make(map[string]bool)
+	for _, r := range list {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("regra '%s' repetida", r.ID)
+		}
+		seen[r.ID] = true
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// ====================
+// rules.json
+// ====================
+
+// File é o rules.json. Com import_legacy as reações de reactions.json e
+// skill_reactions.json (e das janelas de config) também entram no engine;
+// desligue depois de migrar com rules_import para não disparar duas vezes.
+type File struct {
+	ImportLegacy bool   `json:"import_legacy"`
+	Rules        []Rule `json:"rules"`
+}
+
+// DefaultFile é o padrão sem rules.json: só as reações antigas.
+func DefaultFile() File {
+	return File{ImportLegacy: true}
+}
+
+// LoadFile lê e valida o rules.json. Arquivo ausente = DefaultFile.
+func LoadFile(filename string) (File, error) {
+	f := DefaultFile()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return f, fmt.Errorf("erro ao ler %s: %v", filename, err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return DefaultFile(), fmt.Errorf("erro ao parsear %s: %v", filename, err)
+	}
+	list, err := Validate(f.Rules)
+	if err != nil {
+		return DefaultFile(), fmt.Errorf("%s: %v", filename, err)
+	}
+	f.Rules = list
+	return f, nil
+}
+
+// SaveFile grava o rules.json.
+func SaveFile(filename string, f File) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar: %v", err)
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,525 @@
+package sched
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ====================
+// Lanes
+// ====================
+
+// Lane é a prioridade de uma ação de teclado. Uma ação de lane maior
+// preempta a de lane menor entre dois combos (nunca no meio de um combo).
+type Lane string
+
+const (
+	LaneCCBreak  Lane = "cc_break" // maior prioridade
+	LanePotion   Lane = "potion"
+	LaneRotation Lane = "rotation"
+	LaneAutospam Lane = "autospam"
+)
+
+// Lanes em ordem de prioridade (maior primeiro)
+var Lanes = []Lane{LaneCCBreak, LanePotion, LaneRotation, LaneAutospam}
+
+// Priority é a posição da lane em Lanes (0 = maior). -1 = lane desconhecida.
+func (l Lane) Priority() int {
+	for i, lane := range Lanes {
+		if lane == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid indica se a lane existe.
+func (l Lane) Valid() bool {
+	return l.Priority() >= 0
+}
+
+// Erros de Submit/Do
+var (
+	ErrQueueFull   = errors.New("fila da lane cheia")
+	ErrStopped     = errors.New("scheduler parado")
+	ErrUnknownLane = errors.New("lane desconhecida")
+)
+
+// ====================
+// Config
+// ====================
+
+// Limit é o rate limit de uma lane.
+type Limit struct {
+	MinGapMs int `json:"min_gap_ms"` // intervalo mínimo entre o início de duas ações
+	MaxQueue int `json:"max_queue"`  // ações esperando (cheia = descarta a nova)
+}
+
+// Config é o scheduler.json. Lanes ausentes ficam com o padrão:
+//
+//	{"combo_gap_ms": 50, "lanes": {"autospam": {"min_gap_ms": 120, "max_queue": 1}}}
+type Config struct {
+	ComboGapMs int            `json:"combo_gap_ms"` // entre os combos de uma ação
+	Lanes      map[Lane]Limit `json:"lanes"`
+}
+
+// DefaultConfig: CC break e potion sem espera, autospam sem acumular.
+func DefaultConfig() Config {
+	return Config{
+		ComboGapMs: 50,
+		Lanes: map[Lane]Limit{
+			LaneCCBreak:  {MinGapMs: 0, MaxQueue: 4},
+			LanePotion:   {MinGapMs: 250, MaxQueue: 4},
+			LaneRotation: {MinGapMs: 0, MaxQueue: 8},
+			LaneAutospam: {MinGapMs: 100, MaxQueue: 1},
+		},
+	}
+}
+
+// LoadConfig lê o scheduler.json. Arquivo ausente = DefaultConfig.
+func LoadConfig(filename string) (Config, error) {
+	c := DefaultConfig()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, fmt.Errorf("erro ao ler %s: %v", filename, err)
+	}
+	var file Config
+	if err := json.Unmarshal(data, &file); err != nil {
+		return c, fmt.Errorf("erro ao parsear %s: %v", filename, err)
+	}
+	if file.ComboGapMs > 0 {
+		c.ComboGapMs = file.ComboGapMs
+	}
+	for lane, limit := range file.Lanes {
+		if !lane.Valid() {
+			return DefaultConfig(), fmt.Errorf("%s: lane desconhecida: %q", filename, lane)
+		}
+		if limit.MaxQueue <= 0 {
+			limit.MaxQueue = c.Lanes[lane].MaxQueue
+		}
+		c.Lanes[lane] = limit
+	}
+	return c, nil
+}
+
+// ====================
+// Scheduler
+// ====================
+
+// Backend envia para o jogo. Press manda um combo inteiro (down das teclas e
+// up em ordem reversa); Hold segura ou solta teclas (movimento).
+type Backend struct {
+	Press func(combo []uint16) error
+	Hold  func(keys []uint16, down bool) error
+}
+
+// Kind de uma ação
+type Kind int
+
+const (
+	Tap Kind = iota
+	Down
+	Up
+)
+
+// LaneStats conta as ações de uma lane.
+type LaneStats struct {
+	Lane      Lane
+	Queued    int
+	Sent      int // ações completas
+	Combos    int
+	Preempted int
+	Dropped   int
+	WaitMs    int64 // soma do tempo na fila
+}
+
+type job struct {
+	lane   Lane
+	name   string
+	kind   Kind
+	combos [][]uint16
+	next   int // próximo combo (retomada depois de preemptado)
+	queued time.Time
+	done   chan error // nil = Post
+}
+
+// Scheduler é o único caminho das teclas para o jogo. Uma goroutine executa
+// as ações, sempre a da maior lane pronta, e um combo nunca é intercalado
+// com outro, então modificadores não ficam presos por envios concorrentes.
+type Scheduler struct {
+	mu        sync.Mutex
+	exec      sync.Mutex // serializa a execução (worker ou Drain)
+	config    Config
+	backend   Backend
+	queues    map[Lane][]*job
+	lastStart map[Lane]time.Time
+	held      map[uint16]bool
+	stats     map[Lane]*LaneStats
+	running   bool
+	wake      chan struct{}
+	stop      chan struct{}
+	stopped   chan struct{}
+	now       func() time.Time
+	sleep     func(time.Duration)
+}
+
+// New cria o scheduler. now/sleep nil = relógio do sistema.
+func New(config Config, backend Backend, now func() time.Time, sleep func(time.Duration)) *Scheduler {
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	if config.Lanes == nil {
+		config.Lanes = DefaultConfig().Lanes
+	}
+	s := &Scheduler{
+		config:    config,
+		backend:   backend,
+		queues:    make(map[Lane][]*job),
+		lastStart: make(map[Lane]time.Time),
+		held:      make(map[uint16]bool),
+		stats:     make(map[Lane]*LaneStats),
+		wake:      make(chan struct{}, 1),
+		now:       now,
+		sleep:     sleep,
+	}
+	for _, lane := range Lanes {
+		s.stats[lane] = &LaneStats{Lane: lane}
+	}
+	return s
+}
+
+// Start inicia a goroutine de envio.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	stop, stopped := s.stop, s.stopped
+	s.mu.Unlock()
+
+	go s.loop(stop, stopped)
+}
+
+// Stop para a goroutine, descarta a fila (ErrStopped) e solta teclas seguradas.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	stopped := s.stopped
+	s.mu.Unlock()
+	<-stopped
+
+	s.exec.Lock()
+	defer s.exec.Unlock()
+	s.mu.Lock()
+	var dropped []*job
+	for _, lane := range Lanes {
+		dropped = append(dropped, s.queues[lane]...)
+		s.stats[lane].Dropped += len(s.queues[lane])
+		s.queues[lane] = nil
+	}
+	s.mu.Unlock()
+	for _, j := range dropped {
+		s.finish(j, ErrStopped)
+	}
+	if err := s.releaseAll(); err != nil {
+		fmt.Printf("[SCHED] Erro ao soltar teclas: %v\n", err)
+	}
+}
+
+// IsRunning indica se a goroutine de envio está ativa.
+func (s *Scheduler) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Post enfileira uma ação sem esperar (autospam).
+func (s *Scheduler) Post(lane Lane, name string, combos [][]uint16) error {
+	_, err := s.enqueue(lane, name, Tap, combos, false)
+	return err
+}
+
+// Do enfileira uma ação e espera ela ser enviada. Com o scheduler parado a
+// fila é executada na goroutine de quem chamou.
+func (s *Scheduler) Do(lane Lane, name string, combos [][]uint16) error {
+	return s.wait(s.enqueue(lane, name, Tap, combos, true))
+}
+
+// Hold segura (down=true) ou solta teclas, na ordem das outras ações. Soltar
+// não respeita o MaxQueue da lane: com a fila cheia a tecla ficaria presa.
+func (s *Scheduler) Hold(lane Lane, name string, keys []uint16, down bool) error {
+	kind := Up
+	if down {
+		kind = Down
+	}
+	return s.wait(s.enqueue(lane, name, kind, [][]uint16{keys}, true))
+}
+
+// ReleaseAll solta as teclas seguradas por Hold (ex: ao parar o bot), entre
+// dois combos como qualquer outra ação.
+func (s *Scheduler) ReleaseAll() error {
+	s.exec.Lock()
+	defer s.exec.Unlock()
+	return s.releaseAll()
+}
+
+func (s *Scheduler) releaseAll() error {
+	s.mu.Lock()
+	var keys []uint16
+	for vk := range s.held {
+		keys = append(keys, vk)
+	}
+	s.held = make(map[uint16]bool)
+	s.mu.Unlock()
+	if len(keys) == 0 {
+		return nil
+	}
+	if s.backend.Hold == nil {
+		return fmt.Errorf("sem backend de hold")
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	fmt.Printf("[SCHED] Soltando %d teclas seguradas\n", len(keys))
+	return s.backend.Hold(keys, false)
+}
+
+// Drain executa tudo que está na fila na goroutine de quem chamou,
+// esperando os rate limits. Usado com o scheduler parado (simulação).
+func (s *Scheduler) Drain() {
+	for {
+		ran, wait := s.step()
+		if ran {
+			continue
+		}
+		if wait <= 0 {
+			return
+		}
+		s.sleep(wait)
+	}
+}
+
+// Stats retorna as contagens por lane, em ordem de prioridade.
+func (s *Scheduler) Stats() []LaneStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]LaneStats, 0, len(Lanes))
+	for _, lane := range Lanes {
+		st := *s.stats[lane]
+		st.Queued = len(s.queues[lane])
+		out = append(out, st)
+	}
+	return out
+}
+
+func (s *Scheduler) enqueue(lane Lane, name string, kind Kind, combos [][]uint16, wait bool) (*job, error) {
+	if !lane.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLane, lane)
+	}
+	s.mu.Lock()
+	if kind != Up && len(s.queues[lane]) >= s.limit(lane).MaxQueue {
+		s.stats[lane].Dropped++
+		s.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	j := &job{lane: lane, name: name, kind: kind, combos: combos, queued: s.now()}
+	if wait {
+		j.done = make(chan error, 1)
+	}
+	s.queues[lane] = append(s.queues[lane], j)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return j, nil
+}
+
+func (s *Scheduler) wait(j *job, err error) error {
+	if err != nil {
+		return err
+	}
+	if !s.IsRunning() {
+		s.Drain()
+	}
+	return <-j.done
+}
+
+func (s *Scheduler) limit(lane Lane) Limit {
+	l, ok := s.config.Lanes[lane]
+	if !ok || l.MaxQueue <= 0 {
+		l.MaxQueue = 1
+	}
+	return l
+}
+
+func (s *Scheduler) loop(stop, stopped chan struct{}) {
+	defer close(stopped)
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		ran, wait := s.step()
+		if ran {
+			continue
+		}
+		if wait <= 0 {
+			wait = time.Second
+		}
+		select {
+		case <-stop:
+			return
+		case <-s.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// step executa a próxima ação pronta. Sem ação pronta retorna quanto falta
+// para a próxima sair do rate limit (0 = fila vazia).
+func (s *Scheduler) step() (bool, time.Duration) {
+	s.exec.Lock()
+	defer s.exec.Unlock()
+
+	s.mu.Lock()
+	j, wait := s.pickLocked(s.now())
+	s.mu.Unlock()
+	if j == nil {
+		return false, wait
+	}
+	s.execute(j)
+	return true, 0
+}
+
+// pickLocked tira da fila a ação da maior lane pronta. Uma ação preemptada
+// volta na frente da fila e não espera o rate limit de novo.
+func (s *Scheduler) pickLocked(now time.Time) (*job, time.Duration) {
+	var wait time.Duration
+	for _, lane := range Lanes {
+		q := s.queues[lane]
+		if len(q) == 0 {
+			continue
+		}
+		if d := s.gapLeftLocked(lane, now); d > 0 && q[0].next == 0 {
+			if wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		s.queues[lane] = q[1:]
+		return q[0], 0
+	}
+	return nil, wait
+}
+
+func (s *Scheduler) gapLeftLocked(lane Lane, now time.Time) time.Duration {
+	gap := time.Duration(s.limit(lane).MinGapMs) * time.Millisecond
+	last, ok := s.lastStart[lane]
+	if gap <= 0 || !ok {
+		return 0
+	}
+	return last.Add(gap).Sub(now)
+}
+
+// readyAboveLocked indica se alguma lane acima de lane tem ação pronta.
+func (s *Scheduler) readyAboveLocked(lane Lane, now time.Time) bool {
+	for _, l := range Lanes[:lane.Priority()] {
+		if len(s.queues[l]) > 0 && s.gapLeftLocked(l, now) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) execute(j *job) {
+	if j.next == 0 {
+		s.mu.Lock()
+		now := s.now()
+		s.lastStart[j.lane] = now
+		s.stats[j.lane].WaitMs += now.Sub(j.queued).Milliseconds()
+		s.mu.Unlock()
+	}
+	gap := time.Duration(s.config.ComboGapMs) * time.Millisecond
+	for j.next < len(j.combos) {
+		if j.next > 0 {
+			s.sleep(gap)
+			s.mu.Lock()
+			preempt := s.readyAboveLocked(j.lane, s.now())
+			if preempt {
+				s.queues[j.lane] = append([]*job{j}, s.queues[j.lane]...)
+				s.stats[j.lane].Preempted++
+			}
+			s.mu.Unlock()
+			if preempt {
+				fmt.Printf("[SCHED] %s (%s) preemptado no combo %d/%d\n", j.name, j.lane, j.next+1, len(j.combos))
+				return
+			}
+		}
+		err := s.send(j, j.combos[j.next])
+		j.next++
+		s.mu.Lock()
+		s.stats[j.lane].Combos++
+		s.mu.Unlock()
+		if err != nil {
+			s.finish(j, err)
+			return
+		}
+	}
+	s.mu.Lock()
+	s.stats[j.lane].Sent++
+	s.mu.Unlock()
+	s.finish(j, nil)
+}
+
+func (s *Scheduler) send(j *job, keys []uint16) error {
+	switch j.kind {
+	case Down, Up:
+		if s.backend.Hold == nil {
+			return fmt.Errorf("sem backend de hold")
+		}
+		down := j.kind == Down
+		s.mu.Lock()
+		for _, vk := range keys {
+			if down {
+				s.held[vk] = true
+			} else {
+				delete(s.held, vk)
+			}
+		}
+		s.mu.Unlock()
+		return s.backend.Hold(keys, down)
+	}
+	if s.backend.Press == nil {
+		return fmt.Errorf("sem backend de press")
+	}
+	return s.backend.Press(keys)
+}
+
+func (s *Scheduler) finish(j *job, err error) {
+	if j.done != nil {
+		j.done <- err
+	} else if err != nil && err != ErrStopped {
+		fmt.Printf("[SCHED] %s (%s): %v\n", j.name, j.lane, err)
+	}
+}
@@ -0,0 +1,181 @@
+package sched_test
+
+import (
+	"archefriend/rules"
+	"archefriend/sched"
+	"archefriend/sim"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInputScheduler(t *testing.T) {
+	clock := sim.NewVirtualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	var sent []string
+	var onPress func(combo []uint16)
+	backend := sched.Backend{
+		Press: func(combo []uint16) error {
+			sent = append(sent, fmt.Sprint(combo[0]))
+			if onPress != nil {
+				onPress(combo)
+			}
+			return nil
+		},
+		Hold: func(keys []uint16, down bool) error {
+			sent = append(sent, fmt.Sprintf("%v:%v", keys, down))
+			return nil
+		},
+	}
+	s := sched.New(sched.DefaultConfig(), backend, clock.Now, clock.Sleep)
+	expectSent := func(what, keys string) {
+		t.Helper()
+		if strings.Join(sent, ",") != keys {
+			t.Fatalf("%s: sent %v, want %q", what, sent, keys)
+		}
+		sent = nil
+	}
+
+	// Prioridade: cc_break > potion > rotation > autospam
+	s.Post(sched.LaneAutospam, "spam", [][]uint16{{4}})
+	s.Post(sched.LaneRotation, "skill", [][]uint16{{3}})
+	s.Post(sched.LaneCCBreak, "cc", [][]uint16{{1}})
+	s.Post(sched.LanePotion, "hp", [][]uint16{{2}})
+	s.Drain()
+	expectSent("priority", "1,2,3,4")
+
+	// Preempção entre combos: o CC break entra depois do combo atual e a
+	// rotação continua de onde parou
+	onPress = func(combo []uint16) {
+		if combo[0] == 11 {
+			s.Post(sched.LaneCCBreak, "cc", [][]uint16{{99}})
+		}
+	}
+	s.Post(sched.LaneRotation, "combo", [][]uint16{{11}, {12}, {13}})
+	s.Drain()
+	onPress = nil
+	expectSent("preempt", "11,99,12,13")
+
+	// Rate limit: autospam não acumula e espera min_gap_ms entre envios;
+	// potion espera 250ms e a rotação passa na frente enquanto isso
+	clock.Advance(time.Second)
+	start := clock.Now()
+	if err := s.Post(sched.LaneAutospam, "spam", [][]uint16{{4}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Post(sched.LaneAutospam, "spam", [][]uint16{{4}}); err != sched.ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	s.Drain()
+	s.Post(sched.LaneAutospam, "spam", [][]uint16{{4}})
+	s.Post(sched.LanePotion, "hp", [][]uint16{{2}})
+	s.Post(sched.LanePotion, "mp", [][]uint16{{5}})
+	s.Post(sched.LaneRotation, "skill", [][]uint16{{3}})
+	s.Drain()
+	expectSent("rate limit", "4,2,3,4,5")
+	if d := clock.Now().Sub(start); d < 250*time.Millisecond {
+		t.Fatalf("rate limit waited only %v", d)
+	}
+
+	// Do com o scheduler parado executa na hora; Stop solta teclas seguradas
+	if err := s.Hold(sched.LaneRotation, "W", []uint16{87}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Do(sched.LaneRotation, "skill", [][]uint16{{3}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	s.Stop()
+	expectSent("hold", "[87]:true,3,[87]:false")
+
+	// Soltar passa mesmo com a fila da lane cheia
+	if err := s.Hold(sched.LaneRotation, "W", []uint16{87}, true); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < sched.DefaultConfig().Lanes[sched.LaneRotation].MaxQueue; i++ {
+		s.Post(sched.LaneRotation, "skill", [][]uint16{{3}})
+	}
+	if err := s.Post(sched.LaneRotation, "skill", [][]uint16{{3}}); err != sched.ErrQueueFull {
+		t.Fatalf("expected full rotation lane, got %v", err)
+	}
+	if err := s.Hold(sched.LaneRotation, "W", []uint16{87}, false); err != nil {
+		t.Fatalf("release with full lane: %v", err)
+	}
+	if err := s.ReleaseAll(); err != nil {
+		t.Fatal(err)
+	}
+	expectSent("release full lane", "[87]:true,3,3,3,3,3,3,3,3,[87]:false")
+
+	stats := map[sched.Lane]sched.LaneStats{}
+	for _, st := range s.Stats() {
+		stats[st.Lane] = st
+	}
+	if st := stats[sched.LaneRotation]; st.Preempted != 1 || st.Sent != 15 {
+		t.Fatalf("unexpected rotation stats %+v", st)
+	}
+	if st := stats[sched.LaneAutospam]; st.Dropped != 1 || st.Sent != 3 {
+		t.Fatalf("unexpected autospam stats %+v", st)
+	}
+
+	// Goroutine real: combos de várias goroutines nunca intercalam down/up
+	var mu sync.Mutex
+	var events []string
+	limits := map[sched.Lane]sched.Limit{}
+	for _, lane := range sched.Lanes {
+		limits[lane] = sched.Limit{MaxQueue: 4}
+	}
+	live := sched.New(sched.Config{ComboGapMs: 1, Lanes: limits}, sched.Backend{
+		Press: func(combo []uint16) error {
+			for _, vk := range combo {
+				mu.Lock()
+				events = append(events, fmt.Sprintf("d%d", vk))
+				mu.Unlock()
+			}
+			time.Sleep(time.Millisecond)
+			for i := len(combo) - 1; i >= 0; i-- {
+				mu.Lock()
+				events = append(events, fmt.Sprintf("u%d", combo[i]))
+				mu.Unlock()
+			}
+			return nil
+		},
+	}, nil, nil)
+	live.Start()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			lane := sched.Lanes[g]
+			for i := 0; i < 3; i++ {
+				live.Do(lane, "combo", [][]uint16{{16, uint16(g)}})
+			}
+		}(g)
+	}
+	wg.Wait()
+	live.Stop()
+	if len(events) != 4*3*4 {
+		t.Fatalf("expected 48 key events, got %d", len(events))
+	}
+	for i := 0; i < len(events); i += 4 {
+		if events[i] != "d16" || events[i+3] != "u16" || events[i+1][1:] != events[i+2][1:] {
+			t.Fatalf("interleaved combo at %d: %v", i, events[i:i+4])
+		}
+	}
+
+	// Lanes das regras: debuff = cc_break, threshold = potion
+	list := rules.FromReaction(rules.Reaction{Type: 141, Name: "Tripped", OnStart: "F3", IsDebuff: true})
+	if lane := list[0].InputLane(); lane != sched.LaneCCBreak {
+		t.Fatalf("debuff reaction lane %q", lane)
+	}
+	potion := rules.Rule{Name: "hp", Trigger: rules.Trigger{Type: rules.Threshold, Resource: rules.ResourcePlayerHP, Value: 40},
+		Actions: []rules.Action{{Keys: "5"}}}
+	if lane := potion.InputLane(); lane != sched.LanePotion {
+		t.Fatalf("threshold rule lane %q", lane)
+	}
+	potion.Lane = "panic"
+	if err := potion.Validate(); err == nil {
+		t.Fatalf("expected lane error")
+	}
+}
@@ -0,0 +1,535 @@
+package sim
+
+import (
+	"archefriend/bot"
+	"math"
+	"sync"
+	"time"
+)
+
+// ====================
+// Fake world
+// ====================
+//
+// Helper dos testes (bot, action, rules, sched): o binário não usa o sim.
+
+// Mob é uma entidade simulada.
+type Mob struct {
+	ID    uint32
+	Name  string
+	X     float32
+	Y     float32
+	Z     float32
+	HP    uint32
+	MaxHP uint32
+
+	// RespawnAfter > 0 faz o mob voltar com HP cheio depois de morto
+	RespawnAfter time.Duration
+
+	// Velocidade (m/s) - mob andando enquanto vivo
+	VX float32
+	VY float32
+
+	Level     uint32 // 0 = desconhecido
+	Player    bool   // outro player (IsPlayer em vez de IsNPC)
+	Despawned bool   // some da entity list (não conta como morto)
+	Immune    bool   // não toma dano (evade/imune)
+	Aggro     bool   // ataca o player dentro de World.AggroRange
+	diedAt    time.Time
+	dead      bool
+	looted    bool
+}
+
+// Dead indica se o mob morreu e ainda não respawnou.
+func (m *Mob) Dead() bool {
+	return m.dead
+}
+
+// World simula o client: entity list, target, teclas e HP/MP do player.
+// Implementa bot.EntityProvider e bot.Targeter, e fornece SendKey e
+// GetPlayerHP/GetPlayerMP para o bot.Config.
+type World struct {
+	Clock *VirtualClock
+
+	mu      sync.Mutex
+	mobs    map[uint32]*Mob
+	order   []uint32
+	target  uint32
+	keyLog  []KeyPress
+	keyHits map[string]int
+
+	PlayerX, PlayerY, PlayerZ float32
+	PlayerHP, PlayerMaxHP     uint32
+	PlayerMP, PlayerMaxMP     uint32
+
+	// PlayerDead simula a flag de morte (config.OFF_IS_DEAD)
+	PlayerDead bool
+	// OnKey é chamado (fora do lock) para cada tecla enviada pelo bot
+	OnKey func(key string)
+
+	// Regeneração do player por segundo (descanso)
+	HPRegen, MPRegen float64
+	regenHP, regenMP float64
+
+	// Damage por tecla pressionada no target atual (ex: "1" -> 10)
+	Damage map[string]uint32
+	// Heal / Mana por tecla pressionada (potions: "F1" -> 300)
+	Heal map[string]uint32
+	Mana map[string]uint32
+	// LootKey loota o corpo mais próximo (some da entity list)
+	LootKey string
+	// AttackRange limita o dano por distância (0 = sem limite)
+	AttackRange float32
+	// CorpseTime mantém o corpo (HP 0) na entity list depois da morte
+	CorpseTime time.Duration
+	// RejectTarget faz SetTarget falhar silenciosamente (simula mismatch)
+	RejectTarget func(id uint32) bool
+
+	// Combate: mobs Aggro dentro de AggroRange tiram AggroDPS de HP/s. A
+	// flag de combate (config.OFF_COMBAT_RAW) fica ligada até CombatLinger
+	// depois do último dano dado ou recebido.
+	AggroRange   float32
+	AggroDPS     float64
+	CombatLinger time.Duration
+	aggroAcc     float64
+	lastCombat   time.Time
+
+	// Movimento do player (teclas seguradas via KeyDown/KeyUp)
+	PlayerHeading float64 // radianos, atan2(dy, dx)
+	MoveSpeed     float32 // m/s com forward pressionado
+	TurnRate      float64 // graus/s
+	ForwardKey    string
+	TurnLeftKey   string
+	TurnRightKey  string
+	held          map[string]bool
+	lastUpdate    time.Time
+	traveled      float32
+}
+
+// KeyPress registra uma tecla enviada pelo bot.
+type KeyPress struct {
+	Key string
+	At  time.Time
+}
+
+// NewWorld cria um mundo vazio com o player em (0,0,0) e HP/MP cheios.
+func NewWorld() *World {
+	return &World{
+		Clock:        NewVirtualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+		mobs:         make(map[uint32]*Mob),
+		keyHits:      make(map[string]int),
+		PlayerHP:     1000,
+		PlayerMaxHP:  1000,
+		PlayerMP:     1000,
+		PlayerMaxMP:  1000,
+		Damage:       make(map[string]uint32),
+		Heal:         make(map[string]uint32),
+		Mana:         make(map[string]uint32),
+		CorpseTime:   2 * time.Second,
+		AggroRange:   10,
+		AggroDPS:     20,
+		CombatLinger: 3 * time.Second,
+		MoveSpeed:    5,
+		TurnRate:     180,
+		ForwardKey:   "W",
+		TurnLeftKey:  "A",
+		TurnRightKey: "D",
+		held:         make(map[string]bool),
+	}
+}
+
+// AddMob adiciona um mob (HP = MaxHP se HP for 0).
+func (w *World) AddMob(m Mob) *Mob {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if m.HP == 0 {
+		m.HP = m.MaxHP
+	}
+	mob := m
+	w.mobs[m.ID] = &mob
+	w.order = append(w.order, m.ID)
+	return &mob
+}
+
+// Mob retorna o mob pelo ID (nil se não existir).
+func (w *World) Mob(id uint32) *Mob {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.mobs[id]
+}
+
+// MoveMob muda a posição de um mob.
+func (w *World) MoveMob(id uint32, x, y, z float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if m, ok := w.mobs[id]; ok {
+		m.X, m.Y, m.Z = x, y, z
+	}
+}
+
+// Despawn remove o mob da entity list (ex: saiu do streaming range).
+func (w *World) Despawn(id uint32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if m, ok := w.mobs[id]; ok {
+		m.Despawned = true
+		if w.target == id {
+			w.target = 0
+		}
+	}
+}
+
+// Kill zera o HP do mob (ex: morto por outro player).
+func (w *World) Kill(id uint32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if m, ok := w.mobs[id]; ok && !m.dead {
+		w.killLocked(m)
+	}
+}
+
+func (w *World) killLocked(m *Mob) {
+	m.HP = 0
+	m.dead = true
+	m.diedAt = w.Clock.Now()
+}
+
+// Update aplica movimento, respawn e remoção de corpos. Chamar a cada passo.
+func (w *World) Update() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.Clock.Now()
+
+	if !w.lastUpdate.IsZero() {
+		dt := now.Sub(w.lastUpdate).Seconds()
+		turn := w.TurnRate * math.Pi / 180 * dt
+		if w.held[w.TurnLeftKey] {
+			w.PlayerHeading += turn
+		}
+		if w.held[w.TurnRightKey] {
+			w.PlayerHeading -= turn
+		}
+		if w.held[w.ForwardKey] {
+			d := float64(w.MoveSpeed) * dt
+			w.PlayerX += float32(d * math.Cos(w.PlayerHeading))
+			w.PlayerY += float32(d * math.Sin(w.PlayerHeading))
+			w.traveled += float32(d)
+		}
+		w.regenHP += w.HPRegen * dt
+		w.regenMP += w.MPRegen * dt
+		w.PlayerHP = regen(w.PlayerHP, w.PlayerMaxHP, &w.regenHP)
+		w.PlayerMP = regen(w.PlayerMP, w.PlayerMaxMP, &w.regenMP)
+		for _, m := range w.mobs {
+			if !m.dead && !m.Despawned {
+				m.X += m.VX * float32(dt)
+				m.Y += m.VY * float32(dt)
+			}
+		}
+		for _, m := range w.mobs {
+			if m.Aggro && !m.dead && !m.Despawned && w.distanceLocked(m) <= w.AggroRange {
+				w.aggroAcc += w.AggroDPS * dt
+				w.lastCombat = now
+			}
+		}
+		if hit := uint32(w.aggroAcc); hit > 0 {
+			w.aggroAcc -= float64(hit)
+			if hit >= w.PlayerHP {
+				hit = w.PlayerHP - 1 // morte é controlada pelo cenário (SetPlayerDead)
+			}
+			w.PlayerHP -= hit
+		}
+	}
+	w.lastUpdate = now
+	for _, m := range w.mobs {
+		if !m.dead {
+			continue
+		}
+		if m.RespawnAfter > 0 && now.Sub(m.diedAt) >= m.RespawnAfter {
+			m.dead = false
+			m.looted = false
+			m.HP = m.MaxHP
+			continue
+		}
+		if now.Sub(m.diedAt) >= w.CorpseTime && w.target == m.ID {
+			w.target = 0
+		}
+	}
+}
+
+// regen soma a parte inteira do acumulado, limitado ao máximo.
+func regen(cur, max uint32, acc *float64) uint32 {
+	whole := uint32(*acc)
+	*acc -= float64(whole)
+	if cur+whole > max {
+		return max
+	}
+	return cur + whole
+}
+
+// restore soma n ao recurso, limitado ao máximo (potions).
+func restore(cur, max, n uint32) uint32 {
+	if cur+n > max {
+		return max
+	}
+	return cur + n
+}
+
+func (w *World) visibleLocked(m *Mob) bool {
+	if m.Despawned || m.looted {
+		return false
+	}
+	if m.dead && w.Clock.Now().Sub(m.diedAt) >= w.CorpseTime {
+		return false
+	}
+	return true
+}
+
+func (w *World) distanceLocked(m *Mob) float32 {
+	dx := m.X - w.PlayerX
+	dy := m.Y - w.PlayerY
+	dz := m.Z - w.PlayerZ
+	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+}
+
+// GetEntities implementa bot.EntityProvider.
+func (w *World) GetEntities() []bot.EntityInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entities := make([]bot.EntityInfo, 0, len(w.order))
+	for _, id := range w.order {
+		m := w.mobs[id]
+		if !w.visibleLocked(m) {
+			continue
+		}
+		entities = append(entities, bot.EntityInfo{
+			Address:  0x10000000 + m.ID,
+			EntityID: m.ID,
+			Name:     m.Name,
+			PosX:     m.X,
+			PosY:     m.Y,
+			PosZ:     m.Z,
+			HP:       m.HP,
+			MaxHP:    m.MaxHP,
+			Level:    m.Level,
+			Distance: w.distanceLocked(m),
+			IsNPC:    !m.Player,
+			IsPlayer: m.Player,
+		})
+	}
+	return entities
+}
+
+// SetTarget implementa bot.Targeter.
+func (w *World) SetTarget(unitID uint32) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.RejectTarget != nil && w.RejectTarget(unitID) {
+		return nil
+	}
+	if m, ok := w.mobs[unitID]; ok && w.visibleLocked(m) {
+		w.target = unitID
+	}
+	return nil
+}
+
+// GetCurrentTargetID implementa bot.Targeter.
+func (w *World) GetCurrentTargetID() uint32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.target
+}
+
+// SendKey é o key sender do bot: registra a tecla e aplica dano no target.
+func (w *World) SendKey(key string) {
+	if w.OnKey != nil {
+		defer w.OnKey(key)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.keyLog = append(w.keyLog, KeyPress{Key: key, At: w.Clock.Now()})
+	w.keyHits[key]++
+
+	w.PlayerHP = restore(w.PlayerHP, w.PlayerMaxHP, w.Heal[key])
+	w.PlayerMP = restore(w.PlayerMP, w.PlayerMaxMP, w.Mana[key])
+	if key == w.LootKey && w.LootKey != "" {
+		w.lootLocked()
+	}
+
+	dmg := w.Damage[key]
+	if dmg == 0 || w.target == 0 {
+		return
+	}
+	m, ok := w.mobs[w.target]
+	if !ok || m.dead || m.Immune || !w.visibleLocked(m) {
+		return
+	}
+	if w.AttackRange > 0 && w.distanceLocked(m) > w.AttackRange {
+		return
+	}
+	w.lastCombat = w.Clock.Now()
+	if m.HP <= dmg {
+		w.killLocked(m)
+		return
+	}
+	m.HP -= dmg
+}
+
+// lootLocked remove o corpo visível mais próximo.
+func (w *World) lootLocked() {
+	var corpse *Mob
+	for _, id := range w.order {
+		m := w.mobs[id]
+		if m.dead && w.visibleLocked(m) && (corpse == nil || w.distanceLocked(m) < w.distanceLocked(corpse)) {
+			corpse = m
+		}
+	}
+	if corpse == nil {
+		return
+	}
+	corpse.looted = true
+	if w.target == corpse.ID {
+		w.target = 0
+	}
+}
+
+// KeyDown / KeyUp seguram e soltam teclas (movimento).
+func (w *World) KeyDown(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.held[key] = true
+	w.keyLog = append(w.keyLog, KeyPress{Key: key + ":down", At: w.Clock.Now()})
+}
+
+func (w *World) KeyUp(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.held[key] = false
+	w.keyLog = append(w.keyLog, KeyPress{Key: key + ":up", At: w.Clock.Now()})
+}
+
+// IsHeld indica se a tecla está pressionada.
+func (w *World) IsHeld(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.held[key]
+}
+
+// GetPlayerPos - usar como Config.GetPlayerPos.
+func (w *World) GetPlayerPos() (float32, float32, float32, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PlayerX, w.PlayerY, w.PlayerZ, true
+}
+
+// Traveled retorna a distância total andada pelo player.
+func (w *World) Traveled() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.traveled
+}
+
+// KeyCount retorna quantas vezes a tecla foi pressionada.
+func (w *World) KeyCount(key string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.keyHits[key]
+}
+
+// KeyLog retorna uma cópia de todas as teclas enviadas.
+func (w *World) KeyLog() []KeyPress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]KeyPress(nil), w.keyLog...)
+}
+
+// SetPlayerHP / SetPlayerMP alteram o estado do player.
+func (w *World) SetPlayerHP(hp uint32) {
+	w.mu.Lock()
+	w.PlayerHP = hp
+	w.mu.Unlock()
+}
+
+func (w *World) SetPlayerMP(mp uint32) {
+	w.mu.Lock()
+	w.PlayerMP = mp
+	w.mu.Unlock()
+}
+
+// IsPlayerDead - usar como Config.IsPlayerDead.
+func (w *World) IsPlayerDead() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PlayerDead
+}
+
+// PlayerInCombat - usar como Config.IsPlayerInCombat.
+func (w *World) PlayerInCombat() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.lastCombat.IsZero() && w.Clock.Now().Sub(w.lastCombat) < w.CombatLinger
+}
+
+// SetPlayerDead mata (HP 0) ou revive o player.
+func (w *World) SetPlayerDead(dead bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.PlayerDead = dead
+	if dead {
+		w.PlayerHP = 0
+	}
+}
+
+// GetPlayerHP / GetPlayerMP - usar como Config.GetPlayerHP/GetPlayerMP.
+func (w *World) GetPlayerHP() (uint32, uint32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PlayerHP, w.PlayerMaxHP
+}
+
+func (w *World) GetPlayerMP() (uint32, uint32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PlayerMP, w.PlayerMaxMP
+}
+
+// Config retorna um bot.Config ligado a este mundo (clock, teclas, HP/MP).
+func (w *World) Config(mobNames ...string) bot.Config {
+	cfg := bot.DefaultConfig()
+	cfg.MobNames = mobNames
+	cfg.Clock = w.Clock
+	cfg.SendKey = w.SendKey
+	cfg.GetPlayerHP = w.GetPlayerHP
+	cfg.GetPlayerMP = w.GetPlayerMP
+	cfg.GetPlayerPos = w.GetPlayerPos
+	cfg.IsPlayerDead = w.IsPlayerDead
+	cfg.KeyDown = w.KeyDown
+	cfg.KeyUp = w.KeyUp
+	return cfg
+}
+
+// NewBot cria um bot usando o mundo como provider e targeter.
+func (w *World) NewBot(cfg bot.Config) *bot.Bot {
+	return bot.NewWithTargeter(w, w, cfg)
+}
+
+// Run avança o mundo e o bot por n passos de dt cada.
+func (w *World) Run(b *bot.Bot, n int, dt time.Duration) {
+	for i := 0; i < n; i++ {
+		w.Clock.Advance(dt)
+		w.Update()
+		b.Step()
+	}
+}
+
+// RunUntil avança até cond() ser true ou até max passos. Retorna se cumpriu.
+func (w *World) RunUntil(b *bot.Bot, max int, dt time.Duration, cond func() bool) bool {
+	for i := 0; i < max; i++ {
+		if cond() {
+			return true
+		}
+		w.Clock.Advance(dt)
+		w.Update()
+		b.Step()
+	}
+	return cond()
+}
@@ -1,11 +1,13 @@
 package skill
 
 import (
+	"archefriend/action"
+	"archefriend/rules"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
-	"time"
 )
 
 // SkillReaction representa uma reação a ser executada quando uma skill é usada
@@ -18,9 +20,8 @@ type SkillReaction struct {
 	UseAimbot    bool   `json:"useAimbot"`    // Usar aimbot antes de executar a reação
 	AimbotOnTry  bool   `json:"aimbotOnTry"`  // Executar aimbot na tentativa (antes do cast)
 
-	// Runtime
-	lastTriggered time.Time
-	parsedKeys    [][]uint16
+	// Confirmação das teclas de OnCast (ver action.Confirm). Vazio = envia uma vez
+	Confirm action.Confirm `json:"confirm,omitempty"`
 }
 
 // SkillReactionsConfig representa o arquivo de configuração de reações
@@ -28,17 +29,12 @@ type SkillReactionsConfig struct {
 	Reactions []SkillReaction `json:"reactions"`
 }
 
-// ReactionManager gerencia as reações de skills
+// ReactionManager guarda as reações de skills (skill_reactions.json e a
+// janela de config); quem dispara é o rules.Engine via Rules
 type ReactionManager struct {
 	reactions map[uint32]*SkillReaction
 	enabled   bool
 	mu        sync.RWMutex
-
-	// Callback para executar teclas
-	ExecuteKeys func(keys [][]uint16) error
-
-	// Callback para aimbot
-	AimAtTarget func() bool
 }
 
 // NewReactionManager cria um novo gerenciador de reações
@@ -172,116 +168,35 @@ func (rm *ReactionManager) IsEnabled() bool {
 	return rm.enabled
 }
 
-// OnSkillTry é chamado quando uma skill é tentada (antes de executar)
-func (rm *ReactionManager) OnSkillTry(skillID uint32) {
-	rm.mu.RLock()
-	enabled := rm.enabled
-	reaction, exists := rm.reactions[skillID]
-	rm.mu.RUnlock()
-
-	if !enabled || !exists || !reaction.Enabled {
-		return
-	}
-
-	// Só executa aimbot se AimbotOnTry estiver ativo
-	if !reaction.UseAimbot || !reaction.AimbotOnTry {
-		return
-	}
-
-	// Verificar cooldown
-	if reaction.CooldownMS > 0 && !reaction.lastTriggered.IsZero() {
-		elapsed := time.Since(reaction.lastTriggered)
-		if elapsed < time.Duration(reaction.CooldownMS)*time.Millisecond {
-			return
-		}
-	}
-
-	fmt.Printf("[SKILL-REACT] Try %s (ID:%d) - Executando aimbot\n", reaction.Name, skillID)
-
-	// Executar aimbot ANTES do cast
-	if rm.AimAtTarget != nil {
-		if rm.AimAtTarget() {
-			fmt.Printf("[SKILL-REACT] Aimbot (on try) para %s\n", reaction.Name)
-		}
-	}
-}
-
-// OnSkillCast é chamado quando uma skill é usada com sucesso
-func (rm *ReactionManager) OnSkillCast(skillID uint32) {
+// Rules converte as reações em regras do rules.Engine (nil com o manager
+// desligado), em ordem de skill ID.
+func (rm *ReactionManager) Rules() []rules.Rule {
 	rm.mu.RLock()
-	enabled := rm.enabled
-	reaction, exists := rm.reactions[skillID]
-	rm.mu.RUnlock()
-
-	if !enabled || !exists || !reaction.Enabled {
-		return
-	}
-
-	// Verificar cooldown
-	if reaction.CooldownMS > 0 && !reaction.lastTriggered.IsZero() {
-		elapsed := time.Since(reaction.lastTriggered)
-		if elapsed < time.Duration(reaction.CooldownMS)*time.Millisecond {
-			return
-		}
-	}
-
-	// Verificar se tem teclas configuradas
-	if reaction.OnCast == "" {
-		return
-	}
-
-	fmt.Printf("[SKILL-REACT] Skill %s (ID:%d) detectada! Executando: %s\n",
-		reaction.Name, skillID, reaction.OnCast)
-
-	// Atualizar timestamp
-	rm.mu.Lock()
-	reaction.lastTriggered = time.Now()
-	useAimbot := reaction.UseAimbot
-	aimbotOnTry := reaction.AimbotOnTry
-	rm.mu.Unlock()
-
-	// Executar aimbot se configurado (e não já executou no try)
-	if useAimbot && !aimbotOnTry && rm.AimAtTarget != nil {
-		if rm.AimAtTarget() {
-			fmt.Printf("[SKILL-REACT] Aimbot (on cast) para %s\n", reaction.Name)
-		}
-	}
-
-	// Executar teclas via callback
-	if rm.ExecuteKeys != nil && reaction.parsedKeys != nil {
-		go func() {
-			if err := rm.ExecuteKeys(reaction.parsedKeys); err != nil {
-				fmt.Printf("[SKILL-REACT] Erro ao executar teclas: %v\n", err)
-			}
-		}()
-	}
-}
-
-// SetKeyParser define a função para parsear teclas
-func (rm *ReactionManager) SetKeyParser(parser func(string) ([][]uint16, error)) {
-	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
-	// Parsear todas as reações existentes
-	for _, r := range rm.reactions {
-		if r.OnCast != "" {
-			keys, err := parser(r.OnCast)
-			if err != nil {
-				fmt.Printf("[SKILL-REACT] Erro ao parsear teclas '%s': %v\n", r.OnCast, err)
-				continue
-			}
-			r.parsedKeys = keys
-		}
-	}
-}
-
-// ReloadAndParse recarrega e parseia as reações
-func (rm *ReactionManager) ReloadAndParse(filepath string, parser func(string) ([][]uint16, error)) error {
-	if err := rm.LoadFromJSON(filepath); err != nil {
-		return err
-	}
-	rm.SetKeyParser(parser)
-	return nil
+	defer rm.mu.RUnlock()
+	if !rm.enabled {
+		return nil
+	}
+	ids := make([]uint32, 0, len(rm.reactions))
+	for id := range rm.reactions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var out []rules.Rule
+	for _, id := range ids {
+		r := rm.reactions[id]
+		out = append(out, rules.FromSkillReaction(rules.SkillReaction{
+			SkillID:     r.SkillID,
+			Name:        r.Name,
+			OnCast:      r.OnCast,
+			Enabled:     r.Enabled,
+			CooldownMS:  r.CooldownMS,
+			UseAimbot:   r.UseAimbot,
+			AimbotOnTry: r.AimbotOnTry,
+			Confirm:     r.Confirm,
+		})...)
+	}
+	return out
 }
 
 // CreateDefaultReactions cria reações padrão de exemplo
@@ -0,0 +1,237 @@
+package zone
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+)
+
+// ====================
+// Zones
+// ====================
+
+// Point é uma posição no mundo (X/Y de GetPlayerPosition, Z ignorado).
+type Point struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// ESPFilters são os filtros do ESP aplicados ao entrar na zona. Campos nil
+// mantêm o valor atual.
+type ESPFilters struct {
+	Players *bool `json:"players,omitempty"`
+	NPCs    *bool `json:"npcs,omitempty"`
+	Mates   *bool `json:"mates,omitempty"`
+	West    *bool `json:"west,omitempty"`
+	East    *bool `json:"east,omitempty"`
+	Pirate  *bool `json:"pirate,omitempty"`
+}
+
+// Zone é uma área nomeada (círculo ou polígono) ligada a um preset do bot,
+// um arquivo de reações e filtros do ESP. Campos vazios não trocam nada:
+//
+//	{"name": "Archerfish", "center": {"x": 14210, "y": 9820}, "radius": 80,
+//	 "preset": "preset2", "reactions": "reactions_pve.json", "esp": {"players": true}}
+//	{"name": "Arena", "polygon": [{"x": 0, "y": 0}, {"x": 50, "y": 0}, {"x": 50, "y": 50}]}
+type Zone struct {
+	Name      string      `json:"name"`
+	Center    *Point      `json:"center,omitempty"`
+	Radius    float32     `json:"radius,omitempty"`
+	Polygon   []Point     `json:"polygon,omitempty"`
+	Preset    string      `json:"preset,omitempty"`    // preset do bot_config.json
+	Reactions string      `json:"reactions,omitempty"` // arquivo de reações (formato do reactions.json)
+	ESP       *ESPFilters `json:"esp,omitempty"`
+}
+
+// Contains indica se a posição está dentro da zona.
+func (z Zone) Contains(x, y float32) bool {
+	if z.Center != nil {
+		dx, dy := x-z.Center.X, y-z.Center.Y
+		return dx*dx+dy*dy <= z.Radius*z.Radius
+	}
+	// Ray casting: conta quantas arestas um raio para +X atravessa
+	inside := false
+	n := len(z.Polygon)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Y > y) != (b.Y > y) && x < (b.X-a.X)*(y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (z Zone) validate() error {
+	if z.Name == "" {
+		return fmt.Errorf("zona sem nome")
+	}
+	switch {
+	case z.Center != nil && len(z.Polygon) > 0:
+		return fmt.Errorf("zona '%s': use center/radius ou polygon, não os dois", z.Name)
+	case z.Center != nil:
+		if z.Radius <= 0 {
+			return fmt.Errorf("zona '%s': radius deve ser > 0", z.Name)
+		}
+	case len(z.Polygon) < 3:
+		return fmt.Errorf("zona '%s': polygon precisa de 3+ pontos", z.Name)
+	}
+	return nil
+}
+
+// Config é o zones.json.
+type Config struct {
+	Enabled       bool    `json:"enabled"`        // troca automática ao entrar nas zonas
+	RecordSpacing float32 `json:"record_spacing"` // distância mínima entre pontos gravados
+	Zones         []Zone  `json:"zones"`
+}
+
+// DefaultConfig retorna config padrão (sem zonas)
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       true,
+		RecordSpacing: 5,
+		Zones:         []Zone{},
+	}
+}
+
+// LoadConfig carrega o zones.json
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveConfig salva o zones.json
+func SaveConfig(filename string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// ====================
+// Tracker
+// ====================
+
+// Tracker acompanha em qual zona o player está. Zonas sobrepostas: vale a
+// primeira da lista, mas a zona atual só é trocada quando o player sai
+// dela (não fica alternando na borda).
+type Tracker struct {
+	mu      sync.Mutex
+	zones   []Zone
+	current int // índice em zones (-1 = fora de todas)
+}
+
+// NewTracker valida as zonas (nomes únicos, formas válidas).
+func NewTracker(zones []Zone) (*Tracker, error) {
+	names := make(map[string]bool)
+	for _, z := range zones {
+		if err := z.validate(); err != nil {
+			return nil, err
+		}
+		if names[z.Name] {
+			return nil, fmt.Errorf("zona '%s' duplicada", z.Name)
+		}
+		names[z.Name] = true
+	}
+	return &Tracker{zones: append([]Zone(nil), zones...), current: -1}, nil
+}
+
+// Update recebe a posição do player. Retorna a zona atual e se o player
+// acabou de entrar nela (sair para fora de todas não conta como entrada).
+func (t *Tracker) Update(x, y float32) (Zone, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current >= 0 && t.zones[t.current].Contains(x, y) {
+		return t.zones[t.current], false
+	}
+	t.current = -1
+	for i, z := range t.zones {
+		if z.Contains(x, y) {
+			t.current = i
+			return z, true
+		}
+	}
+	return Zone{}, false
+}
+
+// Current retorna a zona atual (false = fora de todas).
+func (t *Tracker) Current() (Zone, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current < 0 {
+		return Zone{}, false
+	}
+	return t.zones[t.current], true
+}
+
+// ====================
+// Recorder
+// ====================
+
+// Recorder grava o contorno de uma zona andando pela borda no jogo: cada
+// posição a Spacing metros da anterior vira um vértice do polígono.
+type Recorder struct {
+	mu      sync.Mutex
+	name    string
+	spacing float32
+	points  []Point
+}
+
+func NewRecorder(name string, spacing float32) *Recorder {
+	if spacing <= 0 {
+		spacing = DefaultConfig().RecordSpacing
+	}
+	return &Recorder{name: name, spacing: spacing}
+}
+
+// Add registra a posição. Retorna true se virou um vértice novo.
+func (r *Recorder) Add(x, y float32) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := len(r.points); n > 0 {
+		last := r.points[n-1]
+		if dist(last, Point{x, y}) < r.spacing {
+			return false
+		}
+	}
+	r.points = append(r.points, Point{X: x, Y: y})
+	return true
+}
+
+// Points retorna quantos vértices já foram gravados.
+func (r *Recorder) Points() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.points)
+}
+
+// Finish fecha o polígono. O último ponto perto do primeiro (volta
+// completa) é descartado.
+func (r *Recorder) Finish() (Zone, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	points := append([]Point(nil), r.points...)
+	if n := len(points); n > 3 && dist(points[0], points[n-1]) < r.spacing {
+		points = points[:n-1]
+	}
+	z := Zone{Name: r.name, Polygon: points}
+	if err := z.validate(); err != nil {
+		return Zone{}, err
+	}
+	return z, nil
+}
+
+func dist(a, b Point) float32 {
+	dx, dy := float64(a.X-b.X), float64(a.Y-b.Y)
+	return float32(math.Sqrt(dx*dx + dy*dy))
+}
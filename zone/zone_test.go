@@ -0,0 +1,79 @@
+package zone_test
+
+import (
+	"archefriend/zone"
+	"testing"
+)
+
+func TestZones(t *testing.T) {
+	// Círculo sobreposto ao quadrado: o primeiro da lista vale, mas a zona
+	// atual só muda quando o player sai dela
+	tracker, err := zone.NewTracker([]zone.Zone{
+		{Name: "camp", Center: &zone.Point{X: 100, Y: 0}, Radius: 20, Preset: "preset2"},
+		{Name: "field", Polygon: []zone.Point{{X: 0, Y: -50}, {X: 100, Y: -50}, {X: 100, Y: 50}, {X: 0, Y: 50}}, Preset: "preset1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type step struct {
+		x, y    float32
+		zone    string
+		entered bool
+	}
+	for i, s := range []step{
+		{-10, 0, "", false},
+		{50, 0, "field", true},
+		{60, 0, "field", false},
+		{90, 0, "field", false}, // dentro do camp também: continua no field
+		{110, 0, "camp", true},  // saiu do field
+		{95, 0, "camp", false},  // voltou ao field, ainda dentro do camp
+		{50, 0, "field", true},
+		{50, 80, "", false},
+	} {
+		z, entered := tracker.Update(s.x, s.y)
+		if z.Name != s.zone || entered != s.entered {
+			t.Fatalf("step %d (%.0f, %.0f): zone %q entered %v, want %q %v", i, s.x, s.y, z.Name, entered, s.zone, s.entered)
+		}
+	}
+
+	// Polígono côncavo (L): o canto vazio fica fora
+	l := zone.Zone{Name: "L", Polygon: []zone.Point{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: 20}, {X: 0, Y: 20}}}
+	if !l.Contains(5, 15) || !l.Contains(15, 5) || l.Contains(15, 15) {
+		t.Fatalf("concave polygon containment wrong")
+	}
+
+	// Gravação: anda um quadrado 40x40 e volta ao início
+	rec := zone.NewRecorder("walked", 5)
+	var path []zone.Point
+	for i := 0; i <= 40; i++ {
+		path = append(path, zone.Point{X: float32(i), Y: 0})
+	}
+	for i := 0; i <= 40; i++ {
+		path = append(path, zone.Point{X: 40, Y: float32(i)})
+	}
+	for i := 40; i >= 0; i-- {
+		path = append(path, zone.Point{X: float32(i), Y: 40})
+	}
+	for i := 40; i >= 0; i-- {
+		path = append(path, zone.Point{X: 0, Y: float32(i)})
+	}
+	for _, p := range path {
+		rec.Add(p.X, p.Y)
+	}
+	z, err := rec.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(z.Polygon); n != 32 {
+		t.Fatalf("recorded %d points, want 32", n)
+	}
+	if !z.Contains(20, 20) || z.Contains(50, 20) {
+		t.Fatalf("recorded zone containment wrong")
+	}
+	if _, err := zone.NewRecorder("short", 5).Finish(); err == nil {
+		t.Fatalf("zone without points accepted")
+	}
+	if _, err := zone.NewTracker([]zone.Zone{z, z}); err == nil {
+		t.Fatalf("duplicate zone names accepted")
+	}
+}